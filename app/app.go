@@ -0,0 +1,307 @@
+// Package app merakit aplikasi Fiber lengkap (database, session, template,
+// middleware dan routes) agar bisa dipakai oleh main maupun pengujian.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/encryptcookie"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+
+	"ticketing-fiber/auth"
+	"ticketing-fiber/config"
+	"ticketing-fiber/handlers"
+	"ticketing-fiber/metrics"
+	"ticketing-fiber/middleware"
+	"ticketing-fiber/migrations"
+	"ticketing-fiber/models"
+	"ticketing-fiber/realtime"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/services"
+	"ticketing-fiber/tracing"
+	"ticketing-fiber/utils"
+	"ticketing-fiber/worker"
+)
+
+// App aplikasi Fiber beserta worker group job latar belakang dan hub event
+// realtime
+type App struct {
+	*fiber.App
+	Workers *worker.Group
+	Events  *realtime.Hub
+
+	watcher *services.TicketWatcher
+	digests *services.DigestScheduler
+}
+
+type options struct {
+	mailSender utils.MailSender
+}
+
+// Option mengubah dependency default NewApp
+type Option func(*options)
+
+// WithMailSender mengganti transport email, misalnya dengan
+// utils.MemoryMailSender agar tidak ada email yang benar-benar terkirim
+func WithMailSender(sender utils.MailSender) Option {
+	return func(o *options) {
+		o.mailSender = sender
+	}
+}
+
+// NewApp membuka database dari cfg, memastikan skema sesuai versi migrasi
+// (atau menjalankan migrasi jika DBAutoMigrate aktif), lalu membangun
+// aplikasi Fiber dengan semua routes
+func NewApp(cfg *config.Config, opts ...Option) (*App, error) {
+	o := &options{mailSender: utils.NewSMTPSender(cfg)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Initialize database
+	if err := config.InitDatabase(cfg); err != nil {
+		return nil, err
+	}
+	if err := tracing.RegisterGormCallbacks(config.DB); err != nil {
+		return nil, err
+	}
+
+	// Tolak start jika skema database tidak sesuai versi migrasi
+	if err := prepareSchema(cfg); err != nil {
+		return nil, err
+	}
+
+	// Initialize session store
+	if err := config.InitSessionStore(cfg); err != nil {
+		return nil, err
+	}
+
+	// Initialize Fiber app
+	app := fiber.New(fiber.Config{
+		Views:        newViewEngine(cfg),
+		ErrorHandler: errorHandler,
+		// Banner ASCII merusak output log JSON
+		DisableStartupMessage: cfg.LogFormat == "json",
+	})
+
+	// Middleware
+	app.Use(recover.New())
+	app.Use(middleware.RequestID)
+	app.Use(tracing.Middleware("/healthz", "/readyz", "/metrics"))
+	app.Use(metrics.Middleware())
+
+	// Health check & metrics, sebelum logger dan session agar probe tidak
+	// membanjiri log dan tidak menyentuh session
+	sqlDB, err := config.DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	healthHandler := handlers.NewHealthHandler(cfg, sqlDB, o.mailSender)
+	app.Get("/healthz", healthHandler.Healthz)
+	app.Get("/readyz", healthHandler.Readyz)
+	app.Get("/metrics", metrics.Handler())
+
+	app.Use(middleware.AccessLog)
+
+	// Static files
+	app.Static("/static", cfg.StaticDir)
+
+	// Encrypt cookie session dengan SessionSecret
+	app.Use(encryptcookie.New(encryptcookie.Config{
+		Key: config.SessionCookieKey(cfg),
+	}))
+
+	// Repositories & Services
+	userRepo := repository.NewGormUserRepository(config.DB)
+	ticketRepo := repository.NewGormTicketRepository(config.DB)
+	departmentRepo := repository.NewGormDepartmentRepository(config.DB)
+	sessionRepo := repository.NewGormSessionRepository(config.DB)
+	auditRepo := repository.NewGormAuditRepository(config.DB)
+	ticketWatchRepo := repository.NewGormTicketWatchRepository(config.DB)
+	authStores := auth.Stores{
+		Users:       userRepo,
+		Identities:  repository.NewGormIdentityRepository(config.DB),
+		Departments: departmentRepo,
+	}
+	events := realtime.NewHub()
+	ticketService := services.NewTicketService(ticketRepo, departmentRepo, events)
+	emailService := utils.NewEmailServiceWithSender(cfg, o.mailSender)
+	workers := worker.NewGroup()
+	notificationService := services.NewNotificationService(
+		repository.NewGormNotificationRepository(config.DB),
+		repository.NewGormNotificationPreferenceRepository(config.DB),
+		userRepo,
+		ticketRepo,
+		services.NotificationDelivery{
+			Email:           emailService,
+			Webhooks:        utils.NewWebhookSender(cfg),
+			Workers:         workers,
+			DefaultTimeZone: cfg.DefaultTimeZone,
+		},
+	)
+	if err := metrics.RegisterTicketCollector(openTicketCounter(ticketRepo)); err != nil {
+		return nil, err
+	}
+
+	// Set user locals
+	app.Use(middleware.SetUserLocals(userRepo, sessionRepo, ticketService, notificationService))
+	app.Use(middleware.Locale(cfg))
+
+	// Handlers
+	authenticator, err := auth.NewAuthenticator(cfg, utils.NewPasswordHasher(cfg), authStores)
+	if err != nil {
+		return nil, err
+	}
+	authHandler := handlers.NewAuthHandler(cfg, authenticator, auth.NewOIDCProvider(cfg, authStores), userRepo, sessionRepo, auditRepo)
+	dashboardHandler := handlers.NewDashboardHandler(cfg, ticketService)
+	ticketHandler := handlers.NewTicketHandler(cfg, emailService, ticketService, notificationService, auditRepo, workers)
+	settingsHandler := handlers.NewSettingsHandler(cfg, userRepo, sessionRepo, auditRepo, notificationService)
+	adminHandler := handlers.NewAdminHandler(cfg, auditRepo)
+	eventsHandler := handlers.NewEventsHandler(cfg, events, ticketService, notificationService)
+	notificationHandler := handlers.NewNotificationHandler(cfg, notificationService)
+
+	// Routes
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.Redirect("/login")
+	})
+
+	// Auth
+	app.Get("/login", middleware.GuestOnly, authHandler.ShowLogin)
+	app.Post("/login", authHandler.Login)
+	app.Get("/login/oidc", middleware.GuestOnly, authHandler.StartOIDCLogin)
+	app.Get("/login/oidc/callback", authHandler.OIDCCallback)
+	app.Get("/register", middleware.GuestOnly, authHandler.ShowRegister)
+	app.Post("/register", authHandler.Register)
+	app.Get("/logout", authHandler.Logout)
+	app.Post("/logout", authHandler.Logout)
+	// Protected Routes
+	protected := app.Group("/", middleware.AuthRequired, middleware.PortalUserRequired)
+	protected.Get("/dashboard", dashboardHandler.ShowDashboard)
+	protected.Get("/events", eventsHandler.Stream)
+	protected.Get("/tiket", ticketHandler.ShowMyTickets)
+	protected.Get("/tiket/:id", ticketHandler.ShowTicketDetail)
+	protected.Post("/tiket/:id", ticketHandler.AddReply)
+	protected.Get("/kirim-tiket", ticketHandler.ShowCreateTicket)
+	protected.Post("/kirim-tiket", ticketHandler.CreateTicket)
+	protected.Get("/tiket/sukses/:id", ticketHandler.ShowTicketSuccess)
+	protected.Get("/notifikasi", notificationHandler.ShowNotifications)
+	protected.Post("/notifikasi/read-all", notificationHandler.ReadAllNotifications)
+	protected.Post("/notifikasi/:id/read", notificationHandler.ReadNotification)
+	protected.Get("/settings", settingsHandler.ShowSettings)
+	protected.Post("/settings/profile", settingsHandler.UpdateProfile)
+	protected.Post("/settings/password", settingsHandler.ChangePassword)
+	protected.Post("/settings/notifications", settingsHandler.UpdateNotifications)
+	protected.Post("/settings/sessions/revoke-others", settingsHandler.RevokeOtherSessions)
+	protected.Post("/settings/sessions/:id/revoke", settingsHandler.RevokeSession)
+
+	// Admin (staff only)
+	admin := app.Group("/admin", middleware.AuthRequired, middleware.StaffRequired)
+	admin.Get("/audit", adminHandler.ShowAuditLog)
+	admin.Get("/audit/export.csv", adminHandler.ExportAuditLog)
+
+	// Seed Data
+	if err := SeedDefaultData(context.Background(), departmentRepo); err != nil {
+		return nil, err
+	}
+
+	return &App{
+		App:     app,
+		Workers: workers,
+		Events:  events,
+		watcher: services.NewTicketWatcher(ticketRepo, departmentRepo, ticketWatchRepo, auditRepo, notificationService, events, cfg.RealtimePollInterval),
+		digests: services.NewDigestScheduler(notificationService, cfg.DigestInterval),
+	}, nil
+}
+
+// Shutdown menghentikan server secara bertahap dalam batas timeout: menutup
+// stream realtime, berhenti menerima koneksi dan menunggu request berjalan,
+// menunggu job latar belakang (email) selesai, lalu menutup session storage
+// dan koneksi database
+func (a *App) Shutdown(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	// Stream /events tidak pernah selesai sendiri; tutup lebih dulu agar
+	// server tidak menunggunya sampai timeout
+	a.watcher.Close()
+	a.digests.Close()
+	a.Events.Close()
+
+	var errs []error
+	if err := a.App.ShutdownWithTimeout(timeout); err != nil {
+		errs = append(errs, fmt.Errorf("server shutdown: %w", err))
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	if err := a.Workers.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if config.Store != nil && config.Store.Storage != nil {
+		if err := config.Store.Storage.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("session storage close: %w", err))
+		}
+	}
+	if config.DB != nil {
+		if sqlDB, err := config.DB.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("database close: %w", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// openTicketCounter menghubungkan gauge open_tickets ke TicketRepository
+func openTicketCounter(tickets repository.TicketRepository) metrics.OpenTicketsFunc {
+	return func(ctx context.Context) ([]metrics.OpenTickets, error) {
+		counts, err := tickets.CountOpen(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]metrics.OpenTickets, 0, len(counts))
+		for _, count := range counts {
+			result = append(result, metrics.OpenTickets{
+				Status:     string(count.Status),
+				Department: count.Department,
+				Count:      count.Count,
+			})
+		}
+		return result, nil
+	}
+}
+
+func prepareSchema(cfg *config.Config) error {
+	migrator, err := migrations.New(config.DB)
+	if err != nil {
+		return err
+	}
+	if cfg.DBAutoMigrate {
+		return migrator.Up()
+	}
+	if err := migrator.Check(); err != nil {
+		return fmt.Errorf("%w\nRun `migrate status` to inspect and `migrate up` to upgrade the database", err)
+	}
+	return nil
+}
+
+// SeedDefaultData membuat grup Portal Users dan departemen default jika
+// belum ada; aman dijalankan berulang kali
+func SeedDefaultData(ctx context.Context, departmentRepo repository.DepartmentRepository) error {
+	var portalGroup models.Group
+	if err := config.DB.WithContext(ctx).FirstOrCreate(&portalGroup, models.Group{Name: "Portal Users"}).Error; err != nil {
+		return fmt.Errorf("failed to seed Portal Users group: %w", err)
+	}
+
+	departments := []string{"Technical Support", "Customer Service", "Billing", "General"}
+	for _, deptName := range departments {
+		if _, err := departmentRepo.FirstOrCreate(ctx, deptName); err != nil {
+			return fmt.Errorf("failed to seed department %s: %w", deptName, err)
+		}
+	}
+	return nil
+}
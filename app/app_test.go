@@ -0,0 +1,586 @@
+package app_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ticketing-fiber/app"
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+	"ticketing-fiber/utils"
+)
+
+const testPassword = "Correct-horse-42"
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// testServer aplikasi lengkap dengan database SQLite sementara dan email
+// yang hanya dicatat di memori
+type testServer struct {
+	t    *testing.T
+	app  *app.App
+	cfg  *config.Config
+	mail *utils.MemoryMailSender
+}
+
+func newTestServer(t *testing.T, configure ...func(*config.Config)) *testServer {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.DatabasePath = filepath.Join(t.TempDir(), "test.db")
+	cfg.DBAutoMigrate = true
+	cfg.TemplatesDir = "../templates"
+	cfg.StaticDir = "../static"
+	cfg.SessionStorage = config.SessionStorageMemory
+	cfg.RealtimePollInterval = 0
+	cfg.DigestInterval = 0
+	cfg.PasswordHashAlgorithm = utils.HashBcrypt
+	cfg.BcryptCost = 4
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	mail := &utils.MemoryMailSender{}
+	application, err := app.NewApp(cfg, app.WithMailSender(mail))
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := application.Shutdown(5 * time.Second); err != nil {
+			t.Errorf("Shutdown: %v", err)
+		}
+	})
+	return &testServer{t: t, app: application, cfg: cfg, mail: mail}
+}
+
+// waitForMail menunggu email dari job latar belakang sampai jumlahnya n
+func (s *testServer) waitForMail(n int) []utils.SentMail {
+	s.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sent := s.mail.Sent()
+		if len(sent) >= n || time.Now().After(deadline) {
+			if len(sent) < n {
+				s.t.Fatalf("got %d emails; want %d", len(sent), n)
+			}
+			return sent
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// client browser sederhana: menyimpan cookie antar request dan tidak
+// mengikuti redirect
+type client struct {
+	s       *testServer
+	cookies map[string]*http.Cookie
+}
+
+func (s *testServer) client() *client {
+	return &client{s: s, cookies: make(map[string]*http.Cookie)}
+}
+
+type response struct {
+	*http.Response
+	body string
+}
+
+func (c *client) do(req *http.Request) *response {
+	c.s.t.Helper()
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	resp, err := c.s.app.Test(req, -1)
+	if err != nil {
+		c.s.t.Fatalf("%s %s: %v", req.Method, req.URL, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.s.t.Fatal(err)
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.MaxAge < 0 || cookie.Value == "" {
+			delete(c.cookies, cookie.Name)
+		} else {
+			c.cookies[cookie.Name] = cookie
+		}
+	}
+	return &response{Response: resp, body: string(body)}
+}
+
+func (c *client) get(path string) *response {
+	return c.do(httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+func (c *client) post(path string, form url.Values) *response {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req)
+}
+
+func (c *client) postJSON(path string, form url.Values) *response {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	return c.do(req)
+}
+
+func (r *response) expectStatus(t *testing.T, status int) *response {
+	t.Helper()
+	if r.StatusCode != status {
+		t.Fatalf("%s %s: status %d; want %d\n%s", r.Request.Method, r.Request.URL, r.StatusCode, status, excerpt(r.body))
+	}
+	return r
+}
+
+func (r *response) expectRedirect(t *testing.T, location string) *response {
+	t.Helper()
+	r.expectStatus(t, http.StatusFound)
+	if got := r.Header.Get("Location"); got != location {
+		t.Fatalf("%s %s: redirect to %q; want %q", r.Request.Method, r.Request.URL, got, location)
+	}
+	return r
+}
+
+func (r *response) expectBody(t *testing.T, substrings ...string) *response {
+	t.Helper()
+	for _, s := range substrings {
+		if !strings.Contains(r.body, s) {
+			t.Fatalf("%s %s: body does not contain %q\n%s", r.Request.Method, r.Request.URL, s, excerpt(r.body))
+		}
+	}
+	return r
+}
+
+func excerpt(body string) string {
+	if len(body) > 2000 {
+		return body[:2000] + "..."
+	}
+	return body
+}
+
+// registerAndLogin mendaftarkan user baru lewat form lalu login
+func (s *testServer) registerAndLogin(username string) *client {
+	s.t.Helper()
+	c := s.client()
+	c.post("/register", url.Values{
+		"username":  {username},
+		"email":     {username + "@example.com"},
+		"password1": {testPassword},
+		"password2": {testPassword},
+	}).expectRedirect(s.t, "/login")
+	c.login(username, testPassword).expectRedirect(s.t, "/dashboard")
+	return c
+}
+
+func (c *client) login(username, password string) *response {
+	return c.post("/login", url.Values{"username": {username}, "password": {password}})
+}
+
+// createTicket membuat tiket lewat form JSON dan mengembalikan ID-nya
+func (c *client) createTicket(title string) uint {
+	c.s.t.Helper()
+	resp := c.postJSON("/kirim-tiket", url.Values{
+		"title":          {title},
+		"description":    {"Deskripsi " + title},
+		"reply_to_email": {"reply@example.com"},
+		"priority":       {"HIGH"},
+	}).expectStatus(c.s.t, http.StatusCreated)
+
+	var ticket models.Ticket
+	if err := json.Unmarshal([]byte(resp.body), &ticket); err != nil {
+		c.s.t.Fatalf("decode ticket: %v\n%s", err, resp.body)
+	}
+	if ticket.ID == 0 || ticket.Title != title {
+		c.s.t.Fatalf("created ticket = %+v", ticket)
+	}
+	return ticket.ID
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	s := newTestServer(t)
+	c := s.registerAndLogin("alice")
+
+	c.get("/dashboard").expectStatus(t, http.StatusOK).expectBody(t, "alice")
+
+	c.get("/logout").expectRedirect(t, "/login")
+	c.get("/dashboard").expectRedirect(t, "/login")
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	s := newTestServer(t)
+	s.registerAndLogin("alice")
+
+	c := s.client()
+	c.login("alice", "wrong-password").expectStatus(t, http.StatusOK)
+	c.get("/dashboard").expectStatus(t, http.StatusFound)
+}
+
+func TestRegisterValidation(t *testing.T) {
+	s := newTestServer(t)
+	s.registerAndLogin("alice")
+
+	c := s.client()
+	c.post("/register", url.Values{
+		"username":  {"alice"},
+		"email":     {"other@example.com"},
+		"password1": {testPassword},
+		"password2": {testPassword},
+	}).expectStatus(t, http.StatusBadRequest)
+	c.post("/register", url.Values{
+		"username":  {"bob"},
+		"email":     {"bob@example.com"},
+		"password1": {testPassword},
+		"password2": {testPassword + "x"},
+	}).expectStatus(t, http.StatusBadRequest)
+	// Test server memakai bcrypt: password di atas 72 byte ditolak form,
+	// bukan gagal saat di-hash
+	long := testPassword + strings.Repeat("x", 80)
+	c.post("/register", url.Values{
+		"username":  {"bob"},
+		"email":     {"bob@example.com"},
+		"password1": {long},
+		"password2": {long},
+	}).expectStatus(t, http.StatusBadRequest).expectBody(t, "72")
+	c.login("bob", testPassword).expectStatus(t, http.StatusOK)
+}
+
+func TestAccountFormsRespondWithJSON(t *testing.T) {
+	s := newTestServer(t)
+	c := s.client()
+
+	resp := c.postJSON("/register", url.Values{
+		"username":  {"alice"},
+		"email":     {"alice@example.com"},
+		"password1": {testPassword},
+		"password2": {testPassword},
+	}).expectStatus(t, http.StatusCreated)
+	var registered models.User
+	if err := json.Unmarshal([]byte(resp.body), &registered); err != nil {
+		t.Fatalf("decode: %v\n%s", err, resp.body)
+	}
+	if registered.ID == 0 || registered.Username != "alice" || strings.Contains(resp.body, "password") {
+		t.Errorf("registered user = %s", resp.body)
+	}
+
+	c.login("alice", testPassword).expectRedirect(t, "/dashboard")
+	resp = c.postJSON("/settings/profile", url.Values{
+		"username":  {"alice"},
+		"email":     {"alice@example.com"},
+		"last_name": {"Liddell"},
+		"language":  {"en"},
+	}).expectStatus(t, http.StatusOK)
+	var updated models.User
+	if err := json.Unmarshal([]byte(resp.body), &updated); err != nil {
+		t.Fatalf("decode: %v\n%s", err, resp.body)
+	}
+	if updated.ID != registered.ID || updated.LastName != "Liddell" || updated.Language != "en" {
+		t.Errorf("updated user = %s", resp.body)
+	}
+
+	// Validasi tetap berupa error JSON terstruktur
+	c.postJSON("/settings/profile", url.Values{
+		"username": {""},
+		"email":    {"alice@example.com"},
+	}).expectStatus(t, http.StatusBadRequest).expectBody(t, `"code":"validation"`, `"username"`)
+}
+
+func TestProtectedPagesRequireLogin(t *testing.T) {
+	s := newTestServer(t)
+	c := s.client()
+	for _, path := range []string{"/dashboard", "/tiket", "/tiket/1", "/kirim-tiket", "/settings"} {
+		if resp := c.get(path); resp.StatusCode != http.StatusFound || !strings.HasPrefix(resp.Header.Get("Location"), "/login") {
+			t.Errorf("GET %s = %d %q; want redirect to login", path, resp.StatusCode, resp.Header.Get("Location"))
+		}
+	}
+}
+
+func TestCreateTicketAndReply(t *testing.T) {
+	s := newTestServer(t)
+	c := s.registerAndLogin("alice")
+
+	// Form HTML: redirect ke halaman sukses
+	resp := c.post("/kirim-tiket", url.Values{
+		"title":          {"Printer rusak"},
+		"description":    {"Tidak bisa mencetak"},
+		"reply_to_email": {"alice@example.com"},
+		"priority":       {"MEDIUM"},
+	}).expectStatus(t, http.StatusFound)
+	successPath := resp.Header.Get("Location")
+	if !strings.HasPrefix(successPath, "/tiket/sukses/") {
+		t.Fatalf("redirect after create = %q", successPath)
+	}
+	c.get(successPath).expectStatus(t, http.StatusOK).expectBody(t, strings.TrimPrefix(successPath, "/tiket/sukses/"))
+
+	mails := s.waitForMail(1)
+	if mails[0].To[0] != "alice@example.com" || !strings.Contains(mails[0].Body, "Printer rusak") {
+		t.Errorf("confirmation email = %+v", mails[0])
+	}
+
+	id := c.createTicket("Email tidak masuk")
+	c.get("/tiket").expectStatus(t, http.StatusOK).expectBody(t, "Printer rusak", "Email tidak masuk")
+
+	path := fmt.Sprintf("/tiket/%d", id)
+	c.post(path, url.Values{"message": {"Sudah dicoba restart"}}).expectRedirect(t, path)
+	c.get(path).expectStatus(t, http.StatusOK).expectBody(t, "Email tidak masuk", "Sudah dicoba restart")
+
+	// Balasan kosong diabaikan
+	c.post(path, url.Values{"message": {"   "}}).expectRedirect(t, path)
+}
+
+func TestCreateTicketValidation(t *testing.T) {
+	s := newTestServer(t)
+	c := s.registerAndLogin("alice")
+
+	resp := c.postJSON("/kirim-tiket", url.Values{
+		"title":          {""},
+		"description":    {"x"},
+		"reply_to_email": {"not-an-email"},
+	}).expectStatus(t, http.StatusBadRequest)
+
+	var body struct {
+		Error struct {
+			Code   string            `json:"code"`
+			Fields map[string]string `json:"fields"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(resp.body), &body); err != nil {
+		t.Fatalf("decode: %v\n%s", err, resp.body)
+	}
+	if body.Error.Code != "validation" {
+		t.Errorf("error code = %q; want validation", body.Error.Code)
+	}
+	for _, field := range []string{"title", "reply_to_email"} {
+		if body.Error.Fields[field] == "" {
+			t.Errorf("missing error for field %q in %s", field, resp.body)
+		}
+	}
+}
+
+func TestTicketOwnershipIsolation(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.registerAndLogin("alice")
+	bob := s.registerAndLogin("bob")
+
+	id := alice.createTicket("Rahasia alice")
+	path := fmt.Sprintf("/tiket/%d", id)
+
+	bob.get(path).expectStatus(t, http.StatusNotFound)
+	bob.get(fmt.Sprintf("/tiket/sukses/%d", id)).expectStatus(t, http.StatusNotFound)
+	bob.post(path, url.Values{"message": {"balasan bob"}}).expectStatus(t, http.StatusNotFound)
+	if resp := bob.get("/tiket"); strings.Contains(resp.body, "Rahasia alice") {
+		t.Error("bob's ticket list shows alice's ticket")
+	}
+
+	alice.get(path).expectStatus(t, http.StatusOK).expectBody(t, "Rahasia alice")
+	if resp := alice.get(path); strings.Contains(resp.body, "balasan bob") {
+		t.Error("bob's reply was stored on alice's ticket")
+	}
+}
+
+func TestServiceErrorsFollowRequestLanguage(t *testing.T) {
+	s := newTestServer(t)
+	c := s.registerAndLogin("alice")
+
+	req := httptest.NewRequest(http.MethodGet, "/tiket/999", nil)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Language", "en")
+	resp := c.do(req).expectStatus(t, http.StatusNotFound)
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(resp.body), &body); err != nil {
+		t.Fatalf("decode: %v\n%s", err, resp.body)
+	}
+	if body.Error.Code != "not_found" || body.Error.Message != "Ticket not found." {
+		t.Errorf("error = %+v; want English not_found message", body.Error)
+	}
+
+	// Pesan field dari service memakai bahasa profil user
+	c.post("/settings/profile", url.Values{
+		"username": {"alice"},
+		"email":    {"alice@example.com"},
+		"language": {"en"},
+	}).expectRedirect(t, "/settings")
+	resp = c.post("/kirim-tiket", url.Values{
+		"title":          {"Printer rusak"},
+		"description":    {"Tidak bisa mencetak"},
+		"reply_to_email": {"alice@example.com"},
+		"department":     {"999"},
+	}).expectStatus(t, http.StatusBadRequest).expectBody(t, `lang="en"`, "Department not found")
+	if strings.Contains(resp.body, "Departemen tidak ditemukan") {
+		t.Error("English page shows the Indonesian field error")
+	}
+}
+
+func TestSettingsUpdates(t *testing.T) {
+	s := newTestServer(t)
+	c := s.registerAndLogin("alice")
+
+	c.post("/settings/profile", url.Values{
+		"username":   {"alice"},
+		"email":      {"alice@example.org"},
+		"first_name": {"Alice"},
+		"last_name":  {"Liddell"},
+		"language":   {"en"},
+		"time_zone":  {"Asia/Jakarta"},
+	}).expectRedirect(t, "/settings")
+	c.get("/settings").expectStatus(t, http.StatusOK).expectBody(t, "alice@example.org", "Liddell", "Asia/Jakarta")
+
+	// Bahasa profil dipakai untuk halaman berikutnya
+	c.get("/dashboard").expectStatus(t, http.StatusOK).expectBody(t, `lang="en"`)
+
+	// Email yang sudah dipakai user lain ditolak
+	s.registerAndLogin("bob")
+	c.post("/settings/profile", url.Values{
+		"username": {"alice"},
+		"email":    {"bob@example.com"},
+		"language": {"en"},
+	}).expectStatus(t, http.StatusBadRequest)
+
+	// Ganti password: session lain ikut logout, password lama tidak berlaku
+	other := s.client()
+	other.login("alice", testPassword).expectRedirect(t, "/dashboard")
+
+	newPassword := "Another-horse-77"
+	c.post("/settings/password", url.Values{
+		"old_password":  {testPassword},
+		"new_password1": {newPassword},
+		"new_password2": {newPassword},
+	}).expectRedirect(t, "/settings")
+	c.get("/settings").expectStatus(t, http.StatusOK)
+	other.get("/dashboard").expectStatus(t, http.StatusFound)
+
+	s.client().login("alice", testPassword).expectStatus(t, http.StatusOK)
+	s.client().login("alice", newPassword).expectRedirect(t, "/dashboard")
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	s := newTestServer(t)
+	c := s.registerAndLogin("alice")
+	other := s.client()
+	other.login("alice", testPassword).expectRedirect(t, "/dashboard")
+
+	c.post("/settings/sessions/revoke-others", nil).expectRedirect(t, "/settings")
+	other.get("/dashboard").expectStatus(t, http.StatusFound)
+	c.get("/dashboard").expectStatus(t, http.StatusOK)
+}
+
+func TestRevokeCurrentSessionIsAudited(t *testing.T) {
+	s := newTestServer(t)
+	c := s.registerAndLogin("alice")
+
+	var record models.UserSession
+	if err := config.DB.Order("id DESC").First(&record).Error; err != nil {
+		t.Fatal(err)
+	}
+	c.post(fmt.Sprintf("/settings/sessions/%d/revoke", record.ID), nil).expectRedirect(t, "/login")
+	c.get("/dashboard").expectStatus(t, http.StatusFound)
+
+	var events []models.AuditEvent
+	if err := config.DB.Where("action = ?", models.AuditSessionRevoke).Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].ActorUsername != "alice" || events[0].TargetID != fmt.Sprint(record.ID) {
+		t.Errorf("audit events = %+v; want the revoked session", events)
+	}
+}
+
+// openStream membuka /events di goroutine; channel ditutup saat server
+// mengakhiri stream
+func (c *client) openStream() <-chan struct{} {
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if resp, err := c.s.app.Test(req, -1); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	return done
+}
+
+func expectStreamOpen(t *testing.T, stream <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-stream:
+		t.Fatal("event stream ended while the session is still active")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func expectStreamClosed(t *testing.T, stream <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-stream:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event stream still open after the session ended")
+	}
+}
+
+func TestEventStreamEndsWithSession(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.RealtimeSessionCheck = 20 * time.Millisecond
+	})
+	c := s.registerAndLogin("alice")
+
+	// Session dicabut dari perangkat lain
+	other := s.client()
+	other.login("alice", testPassword).expectRedirect(t, "/dashboard")
+	stream := other.openStream()
+	expectStreamOpen(t, stream)
+	c.post("/settings/sessions/revoke-others", nil).expectRedirect(t, "/settings")
+	expectStreamClosed(t, stream)
+
+	// Logout di tab lain dengan session yang sama
+	stream = c.openStream()
+	expectStreamOpen(t, stream)
+	c.get("/logout").expectRedirect(t, "/login")
+	expectStreamClosed(t, stream)
+}
+
+func TestHealthAndMetrics(t *testing.T) {
+	s := newTestServer(t)
+	probe := s.client()
+	probe.get("/healthz").expectStatus(t, http.StatusOK).expectBody(t, `"status":"ok"`)
+	probe.get("/readyz").expectStatus(t, http.StatusOK).expectBody(t, `"database":"ok"`)
+
+	c := s.registerAndLogin("alice")
+	c.createTicket("Printer rusak")
+	probe.get("/metrics").expectStatus(t, http.StatusOK).expectBody(t,
+		`ticketing_http_request_duration_seconds_count{method="POST",route="/kirim-tiket",status="201"}`,
+		"ticketing_tickets_created_total",
+		`ticketing_open_tickets{department="none",status="WAITING"} 1`,
+	)
+
+	// Database mati: liveness tetap 200, readiness 503
+	db, err := config.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	probe.get("/healthz").expectStatus(t, http.StatusOK)
+	probe.get("/readyz").expectStatus(t, http.StatusServiceUnavailable).expectBody(t, `"status":"unavailable"`)
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"ticketing-fiber/apperrors"
+	"ticketing-fiber/i18n"
+)
+
+// errorResponse bentuk JSON error untuk client API
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// errorPages template halaman error per status; status lain memakai errors/error
+var errorPages = map[int]string{
+	fiber.StatusForbidden:           "errors/403",
+	fiber.StatusNotFound:            "errors/404",
+	fiber.StatusInternalServerError: "errors/500",
+}
+
+// errorHandler mengubah error dari handler menjadi halaman HTML atau JSON
+// sesuai header Accept. Detail error internal hanya ditulis ke log; user
+// hanya melihat pesan dari apperrors.Error atau pesan umum.
+func errorHandler(c *fiber.Ctx, err error) error {
+	lang := i18n.Lang(c.UserContext())
+	status, body := describeError(lang, err)
+	body.RequestID, _ = c.Locals("request_id").(string)
+
+	attrs := []any{"method", utils.CopyString(c.Method()), "path", utils.CopyString(c.Path()), "status", status, "error", err}
+	if status >= fiber.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), "request failed", attrs...)
+	} else {
+		slog.DebugContext(c.UserContext(), "request rejected", attrs...)
+	}
+
+	c.Status(status)
+	if c.Accepts(fiber.MIMETextHTML, fiber.MIMEApplicationJSON) == fiber.MIMEApplicationJSON {
+		return c.JSON(errorResponse{Error: body})
+	}
+
+	page, ok := errorPages[status]
+	if !ok {
+		page = "errors/error"
+	}
+	renderErr := c.Render(page, fiber.Map{
+		"title":      i18n.T(lang, "page.error.title", utils.StatusMessage(status)),
+		"lang":       lang,
+		"status":     status,
+		"message":    body.Message,
+		"fields":     body.Fields,
+		"request_id": body.RequestID,
+		"user":       c.Locals("user"),
+	})
+	if renderErr != nil {
+		slog.ErrorContext(c.UserContext(), "failed to render error page", "template", page, "error", renderErr)
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.SendString(body.Message)
+	}
+	return nil
+}
+
+// describeError menentukan status dan isi respons untuk err
+func describeError(lang string, err error) (int, errorBody) {
+	if appErr, ok := apperrors.As(err); ok {
+		appErr = appErr.Localize(lang)
+		return appErr.StatusCode(), errorBody{
+			Code:    string(appErr.Kind),
+			Message: appErr.Message,
+			Fields:  appErr.Fields,
+		}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		switch fiberErr.Code {
+		case fiber.StatusNotFound:
+			// Pesan bawaan Fiber ("Cannot GET /path") tidak ramah user
+			return fiberErr.Code, errorBody{Code: string(apperrors.KindNotFound), Message: i18n.T(lang, "error.page_not_found")}
+		case fiber.StatusForbidden:
+			return fiberErr.Code, errorBody{Code: string(apperrors.KindForbidden), Message: i18n.T(lang, "error.forbidden")}
+		}
+		if fiberErr.Code < fiber.StatusInternalServerError {
+			return fiberErr.Code, errorBody{Code: "http_error", Message: fiberErr.Message}
+		}
+	}
+
+	return fiber.StatusInternalServerError, errorBody{
+		Code:    string(apperrors.KindInternal),
+		Message: i18n.T(lang, "error.internal"),
+	}
+}
@@ -0,0 +1,119 @@
+package app_test
+
+import (
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"ticketing-fiber/auth/oidctest"
+	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
+)
+
+const oidcRedirectURL = "http://portal.test/login/oidc/callback"
+
+func newOIDCTestServer(t *testing.T) (*testServer, *oidctest.Issuer) {
+	t.Helper()
+	issuer := oidctest.NewIssuer(t)
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.OIDCIssuerURL = issuer.URL
+		cfg.OIDCClientID = issuer.ClientID
+		cfg.OIDCClientSecret = issuer.ClientSecret
+		cfg.OIDCRedirectURL = oidcRedirectURL
+	})
+	return s, issuer
+}
+
+// startOIDC memulai login SSO dan mengembalikan path callback dari issuer
+func (c *client) startOIDC(issuer *oidctest.Issuer, next string, claims map[string]interface{}) string {
+	c.s.t.Helper()
+	resp := c.get("/login/oidc?next="+url.QueryEscape(next)).expectStatus(c.s.t, http.StatusFound)
+	authURL := resp.Header.Get("Location")
+	if !strings.HasPrefix(authURL, issuer.URL+"/authorize?") {
+		c.s.t.Fatalf("redirect to %q; want issuer authorization endpoint", authURL)
+	}
+	callback, err := issuer.Authorize(authURL, claims)
+	if err != nil {
+		c.s.t.Fatal(err)
+	}
+	u, err := url.Parse(callback)
+	if err != nil {
+		c.s.t.Fatal(err)
+	}
+	if u.Scheme+"://"+u.Host+u.Path != oidcRedirectURL {
+		c.s.t.Fatalf("callback = %q; want %s", callback, oidcRedirectURL)
+	}
+	return u.RequestURI()
+}
+
+func ssoClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":                "carol-subject",
+		"email":              "carol@example.com",
+		"email_verified":     true,
+		"preferred_username": "carol",
+	}
+}
+
+func expectLoginError(t *testing.T, r *response, key string) {
+	t.Helper()
+	r.expectStatus(t, http.StatusOK).expectBody(t, html.EscapeString(i18n.T(i18n.Default, key)))
+}
+
+func TestOIDCLogin(t *testing.T) {
+	s, issuer := newOIDCTestServer(t)
+	c := s.client()
+
+	c.get("/login").expectStatus(t, http.StatusOK).expectBody(t, `href="/login/oidc`)
+
+	callback := c.startOIDC(issuer, "/tiket", ssoClaims())
+	c.get(callback).expectRedirect(t, "/tiket")
+	c.get("/dashboard").expectStatus(t, http.StatusOK).expectBody(t, "carol")
+
+	// Callback yang sama tidak bisa diputar ulang setelah login
+	other := s.client()
+	expectLoginError(t, other.get(callback), "login.sso_state_invalid")
+	if issuer.Exchanges() != 1 {
+		t.Errorf("issuer exchanges = %d; want 1", issuer.Exchanges())
+	}
+}
+
+func TestOIDCCallbackRejectsStateMismatch(t *testing.T) {
+	s, issuer := newOIDCTestServer(t)
+	c := s.client()
+
+	callback := c.startOIDC(issuer, "", ssoClaims())
+	u, _ := url.Parse(callback)
+	q := u.Query()
+	q.Set("state", "forged-state")
+	u.RawQuery = q.Encode()
+
+	expectLoginError(t, c.get(u.RequestURI()), "login.sso_state_invalid")
+	if issuer.Exchanges() != 0 {
+		t.Errorf("code was exchanged despite state mismatch")
+	}
+	c.get("/dashboard").expectRedirect(t, "/login")
+
+	// State dihapus dari session setelah callback pertama, jadi callback asli
+	// juga tidak bisa dipakai lagi
+	expectLoginError(t, c.get(callback), "login.sso_state_invalid")
+}
+
+func TestOIDCCallbackRefusesUnverifiedEmail(t *testing.T) {
+	s, issuer := newOIDCTestServer(t)
+	s.registerAndLogin("carol")
+
+	claims := ssoClaims()
+	claims["email_verified"] = false
+	c := s.client()
+	expectLoginError(t, c.get(c.startOIDC(issuer, "", claims)), "login.sso_email_unverified")
+	c.get("/dashboard").expectRedirect(t, "/login")
+}
+
+func TestOIDCCallbackIgnoresExternalNext(t *testing.T) {
+	s, issuer := newOIDCTestServer(t)
+	c := s.client()
+	c.get(c.startOIDC(issuer, "//evil.example.com", ssoClaims())).expectRedirect(t, "/dashboard")
+}
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/template/html/v2"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/models"
+)
+
+// newViewEngine membuat template engine beserta helper function template
+func newViewEngine(cfg *config.Config) *html.Engine {
+	// Initialize template engine
+	engine := html.New(cfg.TemplatesDir, ".html")
+	engine.Reload(cfg.Debug)
+	// engine.Debug(cfg.Debug)
+
+	// --- TEMPLATE FUNCTIONS (HELPER) ---
+
+	//  Slice string (untuk avatar)
+	engine.AddFunc("slice", func(s string, start, end int) string {
+		if start < 0 || end > len(s) || start > end {
+			return s
+		}
+		return s[start:end]
+	})
+
+	//  Uppercase
+	engine.AddFunc("upper", func(s string) string {
+		return strings.ToUpper(s)
+	})
+
+	//  Terjemahan dari katalog i18n: {{t $.lang "key" args...}}
+	engine.AddFunc("t", i18n.T)
+
+	//  Date formatting sesuai bahasa dan zona waktu viewer:
+	//  {{formatDate $.lang $.tz .CreatedAt}}
+	engine.AddFunc("formatDate", func(lang string, loc *time.Location, t interface{}) string {
+		if v, ok := timeValue(t); ok {
+			return i18n.FormatDate(lang, locationOrUTC(loc), v)
+		}
+		return ""
+	})
+
+	//  Date formatting pendek sesuai bahasa dan zona waktu viewer
+	engine.AddFunc("formatDateShort", func(lang string, loc *time.Location, t interface{}) string {
+		if v, ok := timeValue(t); ok {
+			return i18n.FormatDateShort(lang, locationOrUTC(loc), v)
+		}
+		return ""
+	})
+
+	// Time Since
+	engine.AddFunc("timeSince", func(lang string, t interface{}) string {
+		if v, ok := timeValue(t); ok {
+			return i18n.TimeSince(lang, v)
+		}
+		return ""
+	})
+
+	// Helper untuk CSS Class Status
+	engine.AddFunc("getStatusClass", func(status interface{}) string {
+		s := fmt.Sprintf("%v", status)
+		switch s {
+		case "WAITING", "OPEN":
+			return "open"
+		case "IN_PROGRESS":
+			return "in-progress"
+		case "CLOSED", "RESOLVED":
+			return "closed"
+		default:
+			return "closed"
+		}
+	})
+
+	//  Helper untuk CSS Class Priority (PERBAIKAN ERROR TIPE DATA)
+	engine.AddFunc("getPriorityClass", func(priority interface{}) string {
+		p := fmt.Sprintf("%v", priority) // Konversi nilai apa pun ke string
+		switch p {
+		case "HIGH":
+			return "high"
+		case "MEDIUM":
+			return "medium"
+		case "LOW":
+			return "low"
+		default:
+			return "low"
+		}
+	})
+
+	//  Equality check
+	engine.AddFunc("eq", func(a, b interface{}) bool {
+		return a == b
+	})
+
+	//  Map untuk argumen partial: {{template "x" (dict "lang" $.lang "item" .)}}
+	engine.AddFunc("dict", func(pairs ...interface{}) (map[string]interface{}, error) {
+		if len(pairs)%2 != 0 {
+			return nil, fmt.Errorf("dict: odd number of arguments")
+		}
+		m := make(map[string]interface{}, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			key, ok := pairs[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+			}
+			m[key] = pairs[i+1]
+		}
+		return m, nil
+	})
+
+	//  Length check
+	engine.AddFunc("len", func(arr interface{}) int {
+		if arr == nil {
+			return 0
+		}
+		switch v := arr.(type) {
+		case []interface{}:
+			return len(v)
+		case []models.Ticket:
+			return len(v)
+		case []models.TicketReply:
+			return len(v)
+		case []models.Department:
+			return len(v)
+		case string:
+			return len(v)
+		}
+		return 0
+	})
+
+	// Linebreaks
+	engine.AddFunc("linebreaks", func(val interface{}) string {
+		var s string
+		if val == nil {
+			return ""
+		}
+		s = fmt.Sprint(val)
+		return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "<br>"), "\n", "<br>")
+	})
+
+	//  Get Full Name
+	engine.AddFunc("getFullName", func(user interface{}) string {
+		if user == nil {
+			return "User"
+		}
+		// Handle pointer
+		if u, ok := user.(*models.User); ok {
+			if u.FirstName != "" || u.LastName != "" {
+				return strings.TrimSpace(u.FirstName + " " + u.LastName)
+			}
+			return u.Username
+		}
+		// Handle value
+		if u, ok := user.(models.User); ok {
+			if u.FirstName != "" || u.LastName != "" {
+				return strings.TrimSpace(u.FirstName + " " + u.LastName)
+			}
+			return u.Username
+		}
+		return "User"
+	})
+
+	return engine
+}
+
+// timeValue menerima time.Time atau *time.Time (misalnya LastLogin) dari template
+func timeValue(t interface{}) (time.Time, bool) {
+	switch v := t.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// locationOrUTC halaman yang tidak mengisi "tz" ditampilkan dalam UTC
+func locationOrUTC(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,146 @@
+// Package apperrors berisi error aplikasi bertipe. Handler cukup
+// mengembalikan error ini dan error handler Fiber yang memilih status HTTP,
+// halaman error atau JSON. Message aman ditampilkan ke user; Err adalah
+// penyebab internal yang hanya masuk log.
+//
+// Error yang dibuat di luar request (misalnya sentinel di services) tidak
+// tahu bahasa user, sehingga membawa key katalog i18n di Key dan FieldKeys.
+// Localize menerjemahkannya ke bahasa request sebelum ditampilkan.
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+
+	"ticketing-fiber/i18n"
+)
+
+// Kind jenis error aplikasi
+type Kind string
+
+const (
+	KindNotFound   Kind = "not_found"
+	KindForbidden  Kind = "forbidden"
+	KindValidation Kind = "validation"
+	KindConflict   Kind = "conflict"
+	KindInternal   Kind = "internal"
+)
+
+// Error error aplikasi bertipe
+type Error struct {
+	Kind Kind
+	// Message pesan untuk user
+	Message string
+	// Fields pesan per field untuk KindValidation
+	Fields map[string]string
+	// Key key katalog i18n untuk Message; kosong jika Message sudah diterjemahkan
+	Key string
+	// FieldKeys key katalog i18n per field untuk Fields
+	FieldKeys map[string]string
+	// Err penyebab internal, tidak pernah ditampilkan ke user
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode status HTTP untuk jenis error ini
+func (e *Error) StatusCode() int {
+	switch e.Kind {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+// Validation error input; fields boleh nil
+func Validation(message string, fields map[string]string) *Error {
+	return &Error{Kind: KindValidation, Message: message, Fields: fields}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+// Internal membungkus error tak terduga dengan pesan umum untuk user
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Message: i18n.T(i18n.Default, "error.internal"), Key: "error.internal", Err: err}
+}
+
+// NotFoundKey seperti NotFound dengan pesan dari katalog i18n
+func NotFoundKey(key string) *Error {
+	return &Error{Kind: KindNotFound, Message: i18n.T(i18n.Default, key), Key: key}
+}
+
+// ValidationKeys seperti Validation dengan pesan dan pesan per field dari
+// katalog i18n; fieldKeys boleh nil
+func ValidationKeys(key string, fieldKeys map[string]string) *Error {
+	e := &Error{Kind: KindValidation, Key: key, FieldKeys: fieldKeys}
+	return e.Localize(i18n.Default)
+}
+
+// Localize mengembalikan salinan e dengan Message dan Fields dalam bahasa
+// lang. Error tanpa key dikembalikan apa adanya.
+func (e *Error) Localize(lang string) *Error {
+	if e.Key == "" && e.FieldKeys == nil {
+		return e
+	}
+	localized := *e
+	if e.Key != "" {
+		localized.Message = i18n.T(lang, e.Key)
+	}
+	if e.FieldKeys != nil {
+		localized.Fields = make(map[string]string, len(e.FieldKeys))
+		for field, key := range e.FieldKeys {
+			localized.Fields[field] = i18n.T(lang, key)
+		}
+	}
+	return &localized
+}
+
+// As mengambil *Error dari rantai err
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// Localize menerjemahkan err jika berisi *Error dengan key katalog; error
+// lain dikembalikan apa adanya
+func Localize(err error, lang string) error {
+	if appErr, ok := As(err); ok {
+		return appErr.Localize(lang)
+	}
+	return err
+}
+
+// IsKind mengecek apakah err adalah error aplikasi dengan jenis kind
+func IsKind(err error, kind Kind) bool {
+	appErr, ok := As(err)
+	return ok && appErr.Kind == kind
+}
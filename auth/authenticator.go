@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+)
+
+// ErrInvalidCredentials dikembalikan jika username/password tidak cocok
+var ErrInvalidCredentials = errors.New("username atau password salah")
+
+// Authenticator memverifikasi username/password dan mengembalikan user lokal
+type Authenticator interface {
+	// Name nama backend untuk logging, misalnya "local" atau "ldap"
+	Name() string
+
+	// Authenticate mengembalikan ErrInvalidCredentials jika user tidak dikenal
+	// atau password salah, sehingga backend berikutnya bisa dicoba
+	Authenticate(ctx context.Context, username, password string) (*models.User, error)
+}
+
+// ChainAuthenticator mencoba beberapa backend secara berurutan
+type ChainAuthenticator []Authenticator
+
+func (c ChainAuthenticator) Name() string {
+	return "chain"
+}
+
+// Authenticate mengembalikan hasil backend pertama yang berhasil. Error selain
+// ErrInvalidCredentials (misalnya server LDAP tidak bisa dihubungi) dicatat dan
+// backend berikutnya tetap dicoba; ErrUserInactive dan ErrIdentityConflict
+// (password sudah terverifikasi) langsung dikembalikan.
+func (c ChainAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	for _, backend := range c {
+		user, err := backend.Authenticate(ctx, username, password)
+		switch {
+		case err == nil:
+			return user, nil
+		case errors.Is(err, ErrUserInactive), errors.Is(err, ErrIdentityConflict):
+			return nil, err
+		case !errors.Is(err, ErrInvalidCredentials):
+			slog.WarnContext(ctx, "auth backend error", "backend", backend.Name(), "error", err)
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// Stores repository yang dipakai authenticator untuk membaca dan
+// menyinkronkan user lokal
+type Stores struct {
+	Users       repository.UserRepository
+	Identities  repository.IdentityRepository
+	Departments repository.DepartmentRepository
+}
+
+// NewAuthenticator membangun authenticator dari cfg.AuthBackends
+func NewAuthenticator(cfg *config.Config, hasher *utils.PasswordHasher, stores Stores) (Authenticator, error) {
+	var chain ChainAuthenticator
+	for _, name := range cfg.AuthBackends {
+		switch name {
+		case "local":
+			chain = append(chain, NewLocalAuthenticator(stores.Users, hasher))
+		case "ldap":
+			if cfg.LDAPURL == "" {
+				return nil, errors.New("LDAP_URL wajib diisi untuk auth backend ldap")
+			}
+			chain = append(chain, NewLDAPAuthenticator(cfg, stores))
+		default:
+			return nil, fmt.Errorf("unknown auth backend %q", name)
+		}
+	}
+	if len(chain) == 0 {
+		chain = append(chain, NewLocalAuthenticator(stores.Users, hasher))
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return chain, nil
+}
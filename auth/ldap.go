@@ -0,0 +1,326 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPConn adalah bagian dari *ldap.Conn yang dipakai authenticator,
+// dipisah agar bisa diganti dengan stub in-process saat pengujian
+type LDAPConn interface {
+	Bind(username, password string) error
+	Search(req *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close() error
+}
+
+// LDAPDialer membuka koneksi baru ke server LDAP
+type LDAPDialer func(ctx context.Context) (LDAPConn, error)
+
+// LDAPAuthenticator memverifikasi password dengan bind ke direktori LDAP dan
+// menyinkronkan data user lokal dari atribut direktori
+type LDAPAuthenticator struct {
+	cfg         *config.Config
+	dial        LDAPDialer
+	users       repository.UserRepository
+	identities  repository.IdentityRepository
+	departments repository.DepartmentRepository
+}
+
+// ldapProvider nilai UserIdentity.Provider untuk akun LDAP; Subject berisi DN
+const ldapProvider = "ldap"
+
+func NewLDAPAuthenticator(cfg *config.Config, stores Stores) *LDAPAuthenticator {
+	return &LDAPAuthenticator{
+		cfg:         cfg,
+		dial:        defaultLDAPDialer(cfg),
+		users:       stores.Users,
+		identities:  stores.Identities,
+		departments: stores.Departments,
+	}
+}
+
+// WithDialer mengganti cara koneksi ke server (untuk stub/pengujian)
+func (a *LDAPAuthenticator) WithDialer(dial LDAPDialer) *LDAPAuthenticator {
+	a.dial = dial
+	return a
+}
+
+func (a *LDAPAuthenticator) Name() string {
+	return "ldap"
+}
+
+func defaultLDAPDialer(cfg *config.Config) LDAPDialer {
+	return func(ctx context.Context) (LDAPConn, error) {
+		tlsConfig := &tls.Config{InsecureSkipVerify: cfg.LDAPInsecureSkipVerify}
+		conn, err := ldap.DialURL(cfg.LDAPURL, ldap.DialWithTLSConfig(tlsConfig))
+		if err != nil {
+			return nil, err
+		}
+		conn.SetTimeout(10 * time.Second)
+
+		if cfg.LDAPStartTLS {
+			if err := conn.StartTLS(tlsConfig); err != nil {
+				conn.Close()
+				return nil, fmt.Errorf("ldap starttls failed: %w", err)
+			}
+		}
+		return conn, nil
+	}
+}
+
+// LDAPEntry atribut user yang sudah dipetakan dari direktori
+type LDAPEntry struct {
+	DN        string
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+	Groups    []string
+}
+
+// Authenticate mencari DN user dengan akun service, lalu bind sebagai user
+// tersebut untuk memverifikasi password
+func (a *LDAPAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	// Bind dengan password kosong adalah "unauthenticated bind" yang
+	// selalu berhasil di banyak server, jadi harus ditolak di sini
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := a.dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ldap connect failed: %w", err)
+	}
+	defer conn.Close()
+
+	entry, err := a.lookup(conn, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap bind failed: %w", err)
+	}
+
+	return a.syncUser(ctx, entry)
+}
+
+func (a *LDAPAuthenticator) lookup(conn LDAPConn, username string) (*LDAPEntry, error) {
+	if a.cfg.LDAPBindDN != "" {
+		if err := conn.Bind(a.cfg.LDAPBindDN, a.cfg.LDAPBindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind failed: %w", err)
+		}
+	}
+
+	attrs := []string{
+		a.cfg.LDAPAttrUsername,
+		a.cfg.LDAPAttrEmail,
+		a.cfg.LDAPAttrFirstName,
+		a.cfg.LDAPAttrLastName,
+		a.cfg.LDAPAttrGroups,
+	}
+	filter := strings.ReplaceAll(a.cfg.LDAPUserFilter, "%s", ldap.EscapeFilter(username))
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.cfg.LDAPBaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, 10, false,
+		filter, attrs, nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, fmt.Errorf("ldap filter %q matches more than one entry", filter)
+		}
+		return nil, fmt.Errorf("ldap search failed: %w", err)
+	}
+
+	switch len(result.Entries) {
+	case 0:
+		return nil, ErrInvalidCredentials
+	case 1:
+	default:
+		return nil, fmt.Errorf("ldap filter %q matches more than one entry", filter)
+	}
+
+	e := result.Entries[0]
+	entry := &LDAPEntry{
+		DN:        e.DN,
+		Username:  e.GetAttributeValue(a.cfg.LDAPAttrUsername),
+		Email:     e.GetAttributeValue(a.cfg.LDAPAttrEmail),
+		FirstName: e.GetAttributeValue(a.cfg.LDAPAttrFirstName),
+		LastName:  e.GetAttributeValue(a.cfg.LDAPAttrLastName),
+		Groups:    e.GetAttributeValues(a.cfg.LDAPAttrGroups),
+	}
+	if entry.Username == "" {
+		entry.Username = username
+	}
+	if entry.Email == "" {
+		return nil, fmt.Errorf("ldap entry %s has no %s attribute", entry.DN, a.cfg.LDAPAttrEmail)
+	}
+	return entry, nil
+}
+
+// ErrIdentityConflict dikembalikan jika akun LDAP tidak bisa dihubungkan ke
+// user lokal secara pasti, misalnya username dan email cocok dengan user yang
+// berbeda atau user tersebut sudah terhubung ke DN lain
+var ErrIdentityConflict = errors.New("akun direktori bentrok dengan user lokal lain")
+
+// syncUser membuat atau memperbarui user lokal dari entry LDAP
+func (a *LDAPAuthenticator) syncUser(ctx context.Context, entry *LDAPEntry) (*models.User, error) {
+	link, err := a.identities.Find(ctx, ldapProvider, entry.DN)
+	var user *models.User
+	switch {
+	case err == nil:
+		if user, err = a.users.FindByID(ctx, link.UserID); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, repository.ErrNotFound):
+		if user, err = a.matchUser(ctx, entry); err != nil {
+			return nil, err
+		}
+		link = &models.UserIdentity{
+			UserID:   user.ID,
+			Provider: ldapProvider,
+			Subject:  entry.DN,
+		}
+	default:
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	// Email baru dari direktori tidak boleh mengambil alamat milik user lain
+	if taken, err := a.users.EmailTaken(ctx, entry.Email, user.ID); err != nil {
+		return nil, err
+	} else if taken {
+		return nil, fmt.Errorf("%w: email %s belongs to another user", ErrIdentityConflict, entry.Email)
+	}
+
+	now := time.Now().UTC()
+	link.Email = entry.Email
+	link.LastLoginAt = &now
+	if err := a.identities.Save(ctx, link); err != nil {
+		return nil, err
+	}
+
+	user.Email = entry.Email
+	user.FirstName = entry.FirstName
+	user.LastName = entry.LastName
+	user.IsStaff = a.isStaff(entry.Groups)
+
+	departmentID, err := a.departmentFor(ctx, entry.Groups)
+	if err != nil {
+		return nil, err
+	}
+	user.DepartmentID = departmentID
+	user.Department = nil
+
+	if err := a.users.Save(ctx, user); err != nil {
+		return nil, err
+	}
+	return a.users.FindByID(ctx, user.ID)
+}
+
+// matchUser mencari user lokal untuk DN yang belum punya tautan. Hanya email
+// yang sama persis yang dipakai untuk menautkan (email di direktori dikelola
+// admin direktori, jadi dianggap terverifikasi); username yang sama saja
+// tidak cukup karena akan membuat akun direktori bisa mengambil alih akun
+// lokal dengan nama yang sama. Jika tidak ada yang cocok, user baru dibuat.
+func (a *LDAPAuthenticator) matchUser(ctx context.Context, entry *LDAPEntry) (*models.User, error) {
+	user, err := a.users.FindByEmail(ctx, entry.Email)
+	if errors.Is(err, repository.ErrNotFound) {
+		if taken, err := a.users.UsernameTaken(ctx, entry.Username, 0); err != nil {
+			return nil, err
+		} else if taken {
+			return nil, fmt.Errorf("%w: username %s is used by a user with another email", ErrIdentityConflict, entry.Username)
+		}
+		return a.provision(ctx, entry)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Username != entry.Username {
+		if taken, err := a.users.UsernameTaken(ctx, entry.Username, user.ID); err != nil {
+			return nil, err
+		} else if taken {
+			return nil, fmt.Errorf("%w: username %s and email %s belong to different users", ErrIdentityConflict, entry.Username, entry.Email)
+		}
+	}
+
+	existing, err := a.identities.FindForUser(ctx, user.ID, ldapProvider)
+	switch {
+	case err == nil:
+		return nil, fmt.Errorf("%w: user %s is already linked to %s", ErrIdentityConflict, user.Username, existing.Subject)
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+	return user, nil
+}
+
+func (a *LDAPAuthenticator) provision(ctx context.Context, entry *LDAPEntry) (*models.User, error) {
+	user := &models.User{
+		Username: entry.Username,
+		Email:    entry.Email,
+		Password: utils.UnusablePassword(),
+		IsActive: true,
+	}
+	if err := a.users.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to provision user: %w", err)
+	}
+	if err := a.users.AddToGroup(ctx, user, "Portal Users"); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (a *LDAPAuthenticator) isStaff(groups []string) bool {
+	for _, staffGroup := range a.cfg.LDAPStaffGroups {
+		if containsFold(groups, staffGroup) {
+			return true
+		}
+	}
+	return false
+}
+
+// departmentFor mengembalikan departemen dari grup LDAP pertama yang ada di mapping
+func (a *LDAPAuthenticator) departmentFor(ctx context.Context, groups []string) (*uint, error) {
+	for _, group := range groups {
+		for mappedGroup, departmentName := range a.cfg.LDAPDepartmentMapping {
+			if !strings.EqualFold(group, mappedGroup) {
+				continue
+			}
+			department, err := a.departments.FirstOrCreate(ctx, departmentName)
+			if err != nil {
+				return nil, err
+			}
+			return &department.ID, nil
+		}
+	}
+	return nil, nil
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
+	return false
+}
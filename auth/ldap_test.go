@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const testServiceDN = "cn=svc,dc=example,dc=com"
+
+// stubEntry akun di direktori stub
+type stubEntry struct {
+	password string
+	attrs    map[string][]string
+}
+
+// stubDirectory server LDAP in-process: memeriksa bind dan menjawab filter
+// (uid=...) dari entries yang dikunci dengan DN
+type stubDirectory struct {
+	entries map[string]stubEntry
+	dials   int
+}
+
+func (d *stubDirectory) dial(ctx context.Context) (LDAPConn, error) {
+	d.dials++
+	return &stubConn{dir: d}, nil
+}
+
+type stubConn struct {
+	dir   *stubDirectory
+	bound string
+}
+
+func (c *stubConn) Bind(dn, password string) error {
+	if dn == testServiceDN && password == "svc-secret" {
+		c.bound = dn
+		return nil
+	}
+	entry, ok := c.dir.entries[dn]
+	if !ok || password == "" || entry.password != password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	c.bound = dn
+	return nil
+}
+
+func (c *stubConn) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if c.bound != testServiceDN {
+		return nil, ldap.NewError(ldap.LDAPResultInsufficientAccessRights, errors.New("service bind required"))
+	}
+	result := &ldap.SearchResult{}
+	for dn, entry := range c.dir.entries {
+		for _, uid := range entry.attrs["uid"] {
+			if req.Filter == fmt.Sprintf("(uid=%s)", ldap.EscapeFilter(uid)) {
+				result.Entries = append(result.Entries, ldap.NewEntry(dn, entry.attrs))
+			}
+		}
+	}
+	return result, nil
+}
+
+func (c *stubConn) Close() error {
+	return nil
+}
+
+func testLDAPConfig() *config.Config {
+	cfg := config.Default()
+	cfg.LDAPURL = "ldap://directory.test"
+	cfg.LDAPBindDN = testServiceDN
+	cfg.LDAPBindPassword = "svc-secret"
+	cfg.LDAPBaseDN = "dc=example,dc=com"
+	cfg.LDAPStaffGroups = []string{"cn=helpdesk,ou=groups,dc=example,dc=com"}
+	cfg.LDAPDepartmentMapping = map[string]string{"cn=billing,ou=groups,dc=example,dc=com": "Billing"}
+	return cfg
+}
+
+type ldapFixture struct {
+	dir   *stubDirectory
+	mem   *repository.Memory
+	users repository.UserRepository
+	auth  *LDAPAuthenticator
+}
+
+func newLDAPFixture() *ldapFixture {
+	dir := &stubDirectory{entries: map[string]stubEntry{
+		"uid=alice,ou=people,dc=example,dc=com": {password: "alice-pass", attrs: map[string][]string{
+			"uid":       {"alice"},
+			"mail":      {"alice@example.com"},
+			"givenName": {"Alice"},
+			"sn":        {"Liddell"},
+			"memberOf":  {"CN=Helpdesk,ou=groups,dc=example,dc=com", "cn=billing,ou=groups,dc=example,dc=com"},
+		}},
+	}}
+	mem := repository.NewMemory()
+	stores := Stores{Users: mem.Users(), Identities: mem.Identities(), Departments: mem.Departments()}
+	return &ldapFixture{
+		dir:   dir,
+		mem:   mem,
+		users: mem.Users(),
+		auth:  NewLDAPAuthenticator(testLDAPConfig(), stores).WithDialer(dir.dial),
+	}
+}
+
+func (f *ldapFixture) addLocalUser(t *testing.T, username, email string) *models.User {
+	t.Helper()
+	user := &models.User{Username: username, Email: email, Password: "local-hash", IsActive: true}
+	if err := f.users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestLDAPProvisionsNewUser(t *testing.T) {
+	f := newLDAPFixture()
+	ctx := context.Background()
+
+	user, err := f.auth.Authenticate(ctx, "alice", "alice-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "alice" || user.Email != "alice@example.com" || user.FirstName != "Alice" || user.LastName != "Liddell" {
+		t.Errorf("provisioned user = %+v", user)
+	}
+	if !user.IsStaff {
+		t.Error("member of staff group (case-insensitive) should be staff")
+	}
+	if !user.HasPortalAccess() {
+		t.Error("provisioned user should be in Portal Users")
+	}
+	if user.DepartmentID == nil {
+		t.Fatal("department mapping was not applied")
+	}
+	if d, _ := f.mem.Departments().FindByID(ctx, *user.DepartmentID); d == nil || d.Name != "Billing" {
+		t.Errorf("department = %+v; want Billing", d)
+	}
+
+	link, err := f.mem.Identities().Find(ctx, "ldap", "uid=alice,ou=people,dc=example,dc=com")
+	if err != nil || link.UserID != user.ID || link.LastLoginAt == nil {
+		t.Errorf("identity link = %+v, %v", link, err)
+	}
+
+	// Login berikutnya memakai tautan DN, bukan membuat user baru
+	again, err := f.auth.Authenticate(ctx, "alice", "alice-pass")
+	if err != nil || again.ID != user.ID {
+		t.Errorf("second login = %v, %v; want same user", again, err)
+	}
+}
+
+func TestLDAPLinkFollowsDirectoryChanges(t *testing.T) {
+	f := newLDAPFixture()
+	ctx := context.Background()
+	user, err := f.auth.Authenticate(ctx, "alice", "alice-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := f.dir.entries["uid=alice,ou=people,dc=example,dc=com"]
+	entry.attrs["mail"] = []string{"alice.liddell@example.com"}
+	entry.attrs["memberOf"] = nil
+
+	updated, err := f.auth.Authenticate(ctx, "alice", "alice-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != user.ID || updated.Email != "alice.liddell@example.com" || updated.IsStaff || updated.DepartmentID != nil {
+		t.Errorf("user after directory change = %+v", updated)
+	}
+}
+
+func TestLDAPLinksExistingUserByExactEmail(t *testing.T) {
+	f := newLDAPFixture()
+	ctx := context.Background()
+	local := f.addLocalUser(t, "alice.local", "alice@example.com")
+
+	user, err := f.auth.Authenticate(ctx, "alice", "alice-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != local.ID {
+		t.Errorf("linked user = %d; want existing user %d", user.ID, local.ID)
+	}
+	if user.Username != "alice.local" {
+		t.Errorf("username changed to %q; local username must be kept", user.Username)
+	}
+}
+
+func TestLDAPRefusesAmbiguousLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, f *ldapFixture)
+	}{
+		{"username only", func(t *testing.T, f *ldapFixture) {
+			f.addLocalUser(t, "alice", "someone-else@example.com")
+		}},
+		{"username and email on different users", func(t *testing.T, f *ldapFixture) {
+			f.addLocalUser(t, "alice", "someone-else@example.com")
+			f.addLocalUser(t, "alice.local", "alice@example.com")
+		}},
+		{"email owner linked to another DN", func(t *testing.T, f *ldapFixture) {
+			user := f.addLocalUser(t, "alice", "alice@example.com")
+			err := f.mem.Identities().Save(context.Background(), &models.UserIdentity{
+				UserID: user.ID, Provider: "ldap", Subject: "uid=alice,ou=former,dc=example,dc=com",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"email case differs", func(t *testing.T, f *ldapFixture) {
+			f.addLocalUser(t, "alice", "Alice@Example.com")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newLDAPFixture()
+			ctx := context.Background()
+			tt.setup(t, f)
+
+			_, err := f.auth.Authenticate(ctx, "alice", "alice-pass")
+			if !errors.Is(err, ErrIdentityConflict) {
+				t.Fatalf("Authenticate error = %v; want ErrIdentityConflict", err)
+			}
+			if _, err := f.mem.Identities().Find(ctx, "ldap", "uid=alice,ou=people,dc=example,dc=com"); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("identity was linked despite conflict: %v", err)
+			}
+			if local, _ := f.users.FindByUsername(ctx, "alice"); local != nil && local.Password != "local-hash" {
+				t.Error("local user was modified")
+			}
+		})
+	}
+}
+
+func TestLDAPRefusesEmailOfAnotherUser(t *testing.T) {
+	f := newLDAPFixture()
+	ctx := context.Background()
+	if _, err := f.auth.Authenticate(ctx, "alice", "alice-pass"); err != nil {
+		t.Fatal(err)
+	}
+	f.addLocalUser(t, "mallory", "mallory@example.com")
+	f.dir.entries["uid=alice,ou=people,dc=example,dc=com"].attrs["mail"] = []string{"mallory@example.com"}
+
+	if _, err := f.auth.Authenticate(ctx, "alice", "alice-pass"); !errors.Is(err, ErrIdentityConflict) {
+		t.Errorf("Authenticate error = %v; want ErrIdentityConflict", err)
+	}
+}
+
+func TestLDAPCredentialChecks(t *testing.T) {
+	f := newLDAPFixture()
+	ctx := context.Background()
+
+	if _, err := f.auth.Authenticate(ctx, "alice", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("empty password error = %v; want ErrInvalidCredentials", err)
+	}
+	if f.dir.dials != 0 {
+		t.Error("empty password must be rejected before contacting the directory")
+	}
+	if _, err := f.auth.Authenticate(ctx, "alice", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("wrong password error = %v; want ErrInvalidCredentials", err)
+	}
+	if _, err := f.auth.Authenticate(ctx, "nobody", "x"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("unknown user error = %v; want ErrInvalidCredentials", err)
+	}
+	if _, err := f.auth.Authenticate(ctx, "*", "alice-pass"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("wildcard username error = %v; want ErrInvalidCredentials", err)
+	}
+	if users, _ := f.users.UsernameTaken(ctx, "alice", 0); users {
+		t.Error("failed logins must not provision users")
+	}
+}
+
+func TestLDAPInactiveUser(t *testing.T) {
+	f := newLDAPFixture()
+	ctx := context.Background()
+	user, err := f.auth.Authenticate(ctx, "alice", "alice-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.IsActive = false
+	if err := f.users.Save(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.auth.Authenticate(ctx, "alice", "alice-pass"); !errors.Is(err, ErrUserInactive) {
+		t.Errorf("Authenticate error = %v; want ErrUserInactive", err)
+	}
+}
+
+func TestChainStopsOnIdentityConflict(t *testing.T) {
+	f := newLDAPFixture()
+	ctx := context.Background()
+	f.addLocalUser(t, "alice", "someone-else@example.com")
+
+	chain := ChainAuthenticator{NewLocalAuthenticator(f.users, testHasher(utils.HashArgon2id)), f.auth}
+	if _, err := chain.Authenticate(ctx, "alice", "alice-pass"); !errors.Is(err, ErrIdentityConflict) {
+		t.Errorf("chain error = %v; want ErrIdentityConflict", err)
+	}
+}
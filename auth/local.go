@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+)
+
+// LocalAuthenticator memverifikasi password terhadap hash di tabel users
+type LocalAuthenticator struct {
+	users  repository.UserRepository
+	hasher *utils.PasswordHasher
+}
+
+func NewLocalAuthenticator(users repository.UserRepository, hasher *utils.PasswordHasher) *LocalAuthenticator {
+	return &LocalAuthenticator{users: users, hasher: hasher}
+}
+
+func (a *LocalAuthenticator) Name() string {
+	return "local"
+}
+
+// Authenticate mencari user berdasarkan username atau email, memverifikasi
+// password dan meng-upgrade hash lama ke parameter terbaru
+func (a *LocalAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	user, err := a.users.FindByLogin(ctx, username)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := a.hasher.Verify(password, user.Password)
+	if err != nil {
+		slog.ErrorContext(ctx, "password verification error", "username", user.Username, "error", err)
+	}
+	if !valid {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	// Upgrade hash password lama ke algoritma/parameter terbaru
+	if a.hasher.NeedsRehash(user.Password) {
+		newHash, err := a.hasher.Hash(password)
+		if err == nil {
+			err = a.users.UpdatePassword(ctx, user.ID, newHash)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to rehash password", "username", user.Username, "error", err)
+		} else {
+			user.Password = newHash
+			slog.InfoContext(ctx, "password hash upgraded", "username", user.Username)
+		}
+	}
+
+	return user, nil
+}
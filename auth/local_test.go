@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+)
+
+func testHasher(algorithm string) *utils.PasswordHasher {
+	h := *utils.DefaultPasswordHasher
+	h.Algorithm = algorithm
+	h.BcryptCost = 4
+	h.Argon2Memory = 1024
+	h.Argon2Time = 1
+	h.Argon2Threads = 1
+	return &h
+}
+
+func createLocalUser(t *testing.T, users repository.UserRepository, hasher *utils.PasswordHasher, user models.User, password string) *models.User {
+	t.Helper()
+	hash, err := hasher.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Password = hash
+	if err := users.Create(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+	return &user
+}
+
+func TestLocalAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewMemory().Users()
+	hasher := testHasher(utils.HashArgon2id)
+	alice := createLocalUser(t, users, hasher, models.User{Username: "alice", Email: "alice@example.com", IsActive: true}, "secret-1")
+	createLocalUser(t, users, hasher, models.User{Username: "bob", Email: "bob@example.com"}, "secret-2")
+
+	a := NewLocalAuthenticator(users, hasher)
+	for _, login := range []string{"alice", "alice@example.com"} {
+		user, err := a.Authenticate(ctx, login, "secret-1")
+		if err != nil || user.ID != alice.ID {
+			t.Errorf("Authenticate(%q) = %v, %v; want alice", login, user, err)
+		}
+	}
+
+	tests := []struct {
+		login, password string
+		want            error
+	}{
+		{"alice", "wrong", ErrInvalidCredentials},
+		{"carol", "secret-1", ErrInvalidCredentials},
+		{"bob", "secret-2", ErrUserInactive},
+		// Password salah untuk user nonaktif tidak boleh membocorkan status akun
+		{"bob", "wrong", ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		if _, err := a.Authenticate(ctx, tt.login, tt.password); !errors.Is(err, tt.want) {
+			t.Errorf("Authenticate(%q, %q) error = %v; want %v", tt.login, tt.password, err, tt.want)
+		}
+	}
+}
+
+func TestLocalAuthenticatorRehashesOldHash(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewMemory().Users()
+	user := createLocalUser(t, users, testHasher(utils.HashBcrypt), models.User{Username: "alice", Email: "alice@example.com", IsActive: true}, "secret-1")
+
+	hasher := testHasher(utils.HashArgon2id)
+	if _, err := NewLocalAuthenticator(users, hasher).Authenticate(ctx, "alice", "secret-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := users.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Password == user.Password || hasher.NeedsRehash(stored.Password) {
+		t.Errorf("password hash was not upgraded: %q", stored.Password)
+	}
+	if ok, _ := hasher.Verify("secret-1", stored.Password); !ok {
+		t.Error("upgraded hash does not verify")
+	}
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/repository"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrOIDCNotConfigured = errors.New("oidc is not configured")
+	ErrEmailNotVerified  = errors.New("email belum terverifikasi di identity provider")
+)
+
+// OIDCIdentity berisi klaim ID token yang dipakai untuk login
+type OIDCIdentity struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	GivenName         string
+	FamilyName        string
+	Groups            []string
+}
+
+// OIDCProvider adalah relying party OpenID Connect (authorization code + PKCE)
+type OIDCProvider struct {
+	cfg        *config.Config
+	users      repository.UserRepository
+	identities repository.IdentityRepository
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+	verifier *oidc.IDTokenVerifier
+	oauth2   oauth2.Config
+}
+
+// NewOIDCProvider membuat provider; discovery dilakukan saat pertama dipakai
+// sehingga aplikasi tetap bisa start walaupun identity provider sedang down
+func NewOIDCProvider(cfg *config.Config, stores Stores) *OIDCProvider {
+	return &OIDCProvider{cfg: cfg, users: stores.Users, identities: stores.Identities}
+}
+
+// Enabled mengecek apakah SSO dikonfigurasi
+func (p *OIDCProvider) Enabled() bool {
+	return p != nil && p.cfg.OIDCEnabled()
+}
+
+// DisplayName nama identity provider untuk tombol login
+func (p *OIDCProvider) DisplayName() string {
+	return p.cfg.OIDCDisplayName
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) error {
+	if !p.Enabled() {
+		return ErrOIDCNotConfigured
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider != nil {
+		return nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, p.cfg.OIDCIssuerURL)
+	if err != nil {
+		return fmt.Errorf("oidc discovery failed: %w", err)
+	}
+
+	p.provider = provider
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.cfg.OIDCClientID})
+	p.oauth2 = oauth2.Config{
+		ClientID:     p.cfg.OIDCClientID,
+		ClientSecret: p.cfg.OIDCClientSecret,
+		RedirectURL:  p.cfg.OIDCRedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.cfg.OIDCScopes,
+	}
+	return nil
+}
+
+// AuthRequest berisi nilai yang harus disimpan di session sampai callback
+type AuthRequest struct {
+	URL      string
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// NewAuthRequest membuat URL otorisasi dengan state, nonce dan PKCE challenge baru
+func (p *OIDCProvider) NewAuthRequest(ctx context.Context) (*AuthRequest, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	url := p.oauth2.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(verifier),
+	)
+
+	return &AuthRequest{
+		URL:      url,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+	}, nil
+}
+
+// Exchange menukar authorization code dengan token, memvalidasi ID token
+// (signature, issuer, audience, expiry, nonce) dan mengembalikan identitas user
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("token response does not contain id_token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+
+	identity := &OIDCIdentity{
+		Issuer:            idToken.Issuer,
+		Subject:           idToken.Subject,
+		Email:             stringClaim(claims, "email"),
+		EmailVerified:     boolClaim(claims, "email_verified"),
+		PreferredUsername: stringClaim(claims, "preferred_username"),
+		GivenName:         stringClaim(claims, "given_name"),
+		FamilyName:        stringClaim(claims, "family_name"),
+		Groups:            stringsClaim(claims, p.cfg.OIDCGroupsClaim),
+	}
+	return identity, nil
+}
+
+// MapGroups menerjemahkan grup dari identity provider ke nama models.Group lokal
+func (p *OIDCProvider) MapGroups(groups []string) []string {
+	var mapped []string
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		local, ok := p.cfg.OIDCGroupMapping[group]
+		if !ok || seen[local] {
+			continue
+		}
+		seen[local] = true
+		mapped = append(mapped, local)
+	}
+	return mapped
+}
+
+// ManagedGroups mengembalikan semua grup lokal yang dikelola oleh mapping,
+// keanggotaan grup di luar daftar ini tidak diubah saat sinkronisasi
+func (p *OIDCProvider) ManagedGroups() []string {
+	var managed []string
+	seen := make(map[string]bool)
+	for _, local := range p.cfg.OIDCGroupMapping {
+		if !seen[local] {
+			seen[local] = true
+			managed = append(managed, local)
+		}
+	}
+	return managed
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func stringClaim(claims map[string]interface{}, key string) string {
+	if v, ok := claims[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func boolClaim(claims map[string]interface{}, key string) bool {
+	switch v := claims[key].(type) {
+	case bool:
+		return v
+	case string:
+		// Beberapa provider mengirim "true" sebagai string
+		return v == "true"
+	}
+	return false
+}
+
+func stringsClaim(claims map[string]interface{}, key string) []string {
+	switch v := claims[key].(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	case string:
+		return []string{v}
+	}
+	return nil
+}
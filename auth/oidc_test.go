@@ -0,0 +1,395 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+
+	"ticketing-fiber/auth/oidctest"
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+)
+
+type oidcFixture struct {
+	issuer   *oidctest.Issuer
+	mem      *repository.Memory
+	users    repository.UserRepository
+	provider *OIDCProvider
+}
+
+func newOIDCFixture(t *testing.T, configure ...func(*config.Config)) *oidcFixture {
+	issuer := oidctest.NewIssuer(t)
+	cfg := config.Default()
+	cfg.OIDCIssuerURL = issuer.URL
+	cfg.OIDCClientID = issuer.ClientID
+	cfg.OIDCClientSecret = issuer.ClientSecret
+	cfg.OIDCRedirectURL = "http://portal.test/login/oidc/callback"
+	cfg.OIDCGroupMapping = map[string]string{"helpdesk": "Agents"}
+	cfg.OIDCStaffGroups = []string{"helpdesk"}
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	mem := repository.NewMemory()
+	stores := Stores{Users: mem.Users(), Identities: mem.Identities(), Departments: mem.Departments()}
+	return &oidcFixture{issuer: issuer, mem: mem, users: mem.Users(), provider: NewOIDCProvider(cfg, stores)}
+}
+
+// login menjalankan alur authorization code lengkap terhadap issuer palsu
+func (f *oidcFixture) login(t *testing.T, claims map[string]interface{}) (*OIDCIdentity, error) {
+	t.Helper()
+	ctx := context.Background()
+	req, err := f.provider.NewAuthRequest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, err := f.issuer.Authorize(req.URL, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(callback)
+	if u.Query().Get("state") != req.State {
+		t.Fatalf("callback state = %q; want %q", u.Query().Get("state"), req.State)
+	}
+	return f.provider.Exchange(ctx, u.Query().Get("code"), req.Verifier, req.Nonce)
+}
+
+func (f *oidcFixture) addLocalUser(t *testing.T, username, email string) *models.User {
+	t.Helper()
+	user := &models.User{Username: username, Email: email, Password: "local-hash", IsActive: true}
+	if err := f.users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func aliceClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":                "alice-subject",
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"preferred_username": "alice",
+		"given_name":         "Alice",
+		"family_name":        "Liddell",
+		"groups":             []string{"helpdesk", "everyone"},
+	}
+}
+
+func TestOIDCAuthRequestUsesPKCE(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	req, err := f.provider.NewAuthRequest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Scheme+"://"+u.Host != f.issuer.URL || u.Path != "/authorize" {
+		t.Errorf("authorization endpoint = %s; want discovered %s/authorize", u, f.issuer.URL)
+	}
+	sum := sha256.Sum256([]byte(req.Verifier))
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             f.issuer.ClientID,
+		"redirect_uri":          "http://portal.test/login/oidc/callback",
+		"scope":                 "openid email profile",
+		"state":                 req.State,
+		"nonce":                 req.Nonce,
+		"code_challenge_method": "S256",
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(sum[:]),
+	}
+	for key, value := range want {
+		if q.Get(key) != value {
+			t.Errorf("%s = %q; want %q", key, q.Get(key), value)
+		}
+	}
+	if q.Has("code_verifier") {
+		t.Error("verifier must not be sent in the authorization URL")
+	}
+
+	other, err := f.provider.NewAuthRequest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.State == req.State || other.Nonce == req.Nonce || other.Verifier == req.Verifier {
+		t.Error("state, nonce and verifier must be fresh for every request")
+	}
+}
+
+func TestOIDCExchangeReturnsClaims(t *testing.T) {
+	f := newOIDCFixture(t)
+	claims := aliceClaims()
+	claims["email_verified"] = "true"
+
+	identity, err := f.login(t, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Issuer != f.issuer.URL || identity.Subject != "alice-subject" {
+		t.Errorf("issuer/subject = %q/%q", identity.Issuer, identity.Subject)
+	}
+	if identity.Email != "alice@example.com" || !identity.EmailVerified || identity.PreferredUsername != "alice" ||
+		identity.GivenName != "Alice" || identity.FamilyName != "Liddell" {
+		t.Errorf("identity = %+v", identity)
+	}
+	if len(identity.Groups) != 2 || identity.Groups[0] != "helpdesk" {
+		t.Errorf("groups = %v", identity.Groups)
+	}
+}
+
+func TestOIDCExchangeRejectsWrongVerifier(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	req, err := f.provider.NewAuthRequest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, err := f.issuer.Authorize(req.URL, aliceClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(callback)
+	code := u.Query().Get("code")
+
+	other, err := f.provider.NewAuthRequest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.provider.Exchange(ctx, code, other.Verifier, req.Nonce); err == nil {
+		t.Error("Exchange accepted a code with the wrong PKCE verifier")
+	}
+	// Code yang sudah dicoba tidak bisa dipakai ulang
+	if _, err := f.provider.Exchange(ctx, code, req.Verifier, req.Nonce); err == nil {
+		t.Error("Exchange accepted a code twice")
+	}
+	if f.issuer.Exchanges() != 0 {
+		t.Errorf("issuer issued %d tokens; want 0", f.issuer.Exchanges())
+	}
+}
+
+func TestOIDCExchangeValidatesIDToken(t *testing.T) {
+	tests := map[string]func(claims map[string]interface{}){
+		"nonce mismatch": func(c map[string]interface{}) { c["nonce"] = "replayed-nonce" },
+		"missing nonce":  func(c map[string]interface{}) { c["nonce"] = "" },
+		"wrong audience": func(c map[string]interface{}) { c["aud"] = "other-client" },
+		"wrong issuer":   func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" },
+		"expired":        func(c map[string]interface{}) { c["exp"] = 1 },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := newOIDCFixture(t)
+			claims := aliceClaims()
+			mutate(claims)
+			if identity, err := f.login(t, claims); err == nil {
+				t.Errorf("Exchange accepted invalid id_token: %+v", identity)
+			}
+		})
+	}
+}
+
+func TestOIDCLinksExistingUserByVerifiedEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+	local := f.addLocalUser(t, "alice.local", "alice@example.com")
+
+	identity, err := f.login(t, aliceClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := f.provider.ResolveUser(ctx, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != local.ID || user.Username != "alice.local" || user.Password != "local-hash" {
+		t.Errorf("resolved user = %+v; want existing local user", user)
+	}
+	link, err := f.mem.Identities().Find(ctx, f.issuer.URL, "alice-subject")
+	if err != nil || link.UserID != local.ID || link.LastLoginAt == nil {
+		t.Fatalf("identity link = %+v, %v", link, err)
+	}
+
+	// Setelah tertaut, login memakai issuer+subject walaupun email di IdP berubah
+	claims := aliceClaims()
+	claims["email"] = "alice.liddell@example.com"
+	claims["email_verified"] = false
+	identity, err = f.login(t, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, err := f.provider.ResolveUser(ctx, identity); err != nil || user.ID != local.ID {
+		t.Errorf("linked login = %v, %v; want user %d", user, err, local.ID)
+	}
+}
+
+func TestOIDCRefusesUnverifiedEmail(t *testing.T) {
+	for name, mutate := range map[string]func(map[string]interface{}){
+		"unverified": func(c map[string]interface{}) { c["email_verified"] = false },
+		"no claim":   func(c map[string]interface{}) { delete(c, "email_verified") },
+		"no email":   func(c map[string]interface{}) { delete(c, "email") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			f := newOIDCFixture(t)
+			ctx := context.Background()
+			local := f.addLocalUser(t, "alice", "alice@example.com")
+			claims := aliceClaims()
+			mutate(claims)
+
+			identity, err := f.login(t, claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.provider.ResolveUser(ctx, identity); !errors.Is(err, ErrEmailNotVerified) {
+				t.Fatalf("ResolveUser error = %v; want ErrEmailNotVerified", err)
+			}
+			if _, err := f.mem.Identities().FindForUser(ctx, local.ID, f.issuer.URL); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("identity was linked for unverified email: %v", err)
+			}
+			if taken, _ := f.users.UsernameTaken(ctx, "alice2", 0); taken {
+				t.Error("user was provisioned for unverified email")
+			}
+		})
+	}
+}
+
+func TestOIDCAutoProvisioning(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+	// Username sudah dipakai user lain dengan email berbeda
+	f.addLocalUser(t, "alice", "someone-else@example.com")
+
+	identity, err := f.login(t, aliceClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := f.provider.ResolveUser(ctx, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "alice2" || user.Email != "alice@example.com" || user.FirstName != "Alice" || user.LastName != "Liddell" {
+		t.Errorf("provisioned user = %+v", user)
+	}
+	if !user.HasPortalAccess() {
+		t.Error("provisioned user should be in Portal Users")
+	}
+	if ok, _ := testHasher(utils.HashArgon2id).Verify("", user.Password); ok {
+		t.Error("provisioned user must not have a usable password")
+	}
+}
+
+func TestOIDCAutoProvisioningDisabled(t *testing.T) {
+	f := newOIDCFixture(t, func(cfg *config.Config) { cfg.OIDCAutoProvision = false })
+	ctx := context.Background()
+
+	identity, err := f.login(t, aliceClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.provider.ResolveUser(ctx, identity); !errors.Is(err, ErrUserNotProvisioned) {
+		t.Fatalf("ResolveUser error = %v; want ErrUserNotProvisioned", err)
+	}
+	if taken, _ := f.users.UsernameTaken(ctx, "alice", 0); taken {
+		t.Error("user was provisioned although auto-provisioning is disabled")
+	}
+}
+
+func TestOIDCGroupAndStaffMapping(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	identity, err := f.login(t, aliceClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := f.provider.ResolveUser(ctx, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsStaff {
+		t.Error("member of OIDC staff group should be staff")
+	}
+	if names := oidcGroupNames(user); !names["Agents"] || !names["Portal Users"] {
+		t.Errorf("groups = %v; want Agents and Portal Users", names)
+	}
+	// Grup lokal di luar mapping tidak disentuh sinkronisasi
+	if err := f.users.AddToGroup(ctx, user, "Reviewers"); err != nil {
+		t.Fatal(err)
+	}
+
+	claims := aliceClaims()
+	claims["groups"] = []string{"everyone"}
+	identity, err = f.login(t, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err = f.provider.ResolveUser(ctx, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.IsStaff {
+		t.Error("user removed from OIDC staff group should lose staff status")
+	}
+	names := oidcGroupNames(user)
+	if names["Agents"] || !names["Reviewers"] || !names["Portal Users"] {
+		t.Errorf("groups after sync = %v; want Reviewers and Portal Users without Agents", names)
+	}
+}
+
+func TestOIDCStaffUnchangedWithoutStaffGroups(t *testing.T) {
+	f := newOIDCFixture(t, func(cfg *config.Config) { cfg.OIDCStaffGroups = nil })
+	ctx := context.Background()
+	local := f.addLocalUser(t, "alice", "alice@example.com")
+	local.IsStaff = true
+	if err := f.users.Save(ctx, local); err != nil {
+		t.Fatal(err)
+	}
+
+	claims := aliceClaims()
+	claims["groups"] = nil
+	identity, err := f.login(t, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := f.provider.ResolveUser(ctx, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsStaff {
+		t.Error("staff flag changed although OIDC_STAFF_GROUPS is empty")
+	}
+}
+
+func TestOIDCInactiveUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+	local := f.addLocalUser(t, "alice", "alice@example.com")
+	local.IsActive = false
+	if err := f.users.Save(ctx, local); err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := f.login(t, aliceClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.provider.ResolveUser(ctx, identity); !errors.Is(err, ErrUserInactive) {
+		t.Errorf("ResolveUser error = %v; want ErrUserInactive", err)
+	}
+}
+
+func oidcGroupNames(user *models.User) map[string]bool {
+	names := make(map[string]bool)
+	for _, g := range user.Groups {
+		names[g.Name] = true
+	}
+	return names
+}
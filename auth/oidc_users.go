@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+)
+
+var (
+	ErrUserNotProvisioned = errors.New("akun belum terdaftar di portal")
+	ErrUserInactive       = errors.New("akun tidak aktif")
+)
+
+var usernameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// ResolveUser mencari user lokal untuk identitas OIDC: berdasarkan tautan
+// issuer+subject yang sudah ada, lalu berdasarkan email yang terverifikasi,
+// dan membuat user baru jika auto-provisioning aktif. Keanggotaan grup
+// disinkronkan dari klaim grup sesuai OIDC_GROUP_MAPPING, dan status staff
+// dari OIDC_STAFF_GROUPS jika dikonfigurasi.
+func (p *OIDCProvider) ResolveUser(ctx context.Context, identity *OIDCIdentity) (*models.User, error) {
+	link, err := p.identities.Find(ctx, identity.Issuer, identity.Subject)
+	var user *models.User
+	switch {
+	case err == nil:
+		if user, err = p.users.FindByID(ctx, link.UserID); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, repository.ErrNotFound):
+		if identity.Email == "" || !identity.EmailVerified {
+			return nil, ErrEmailNotVerified
+		}
+
+		user, err = p.users.FindByEmail(ctx, identity.Email)
+		if errors.Is(err, repository.ErrNotFound) {
+			if !p.cfg.OIDCAutoProvision {
+				return nil, ErrUserNotProvisioned
+			}
+			user, err = p.provisionUser(ctx, identity)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		link = &models.UserIdentity{
+			UserID:   user.ID,
+			Provider: identity.Issuer,
+			Subject:  identity.Subject,
+		}
+	default:
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	now := time.Now().UTC()
+	link.Email = identity.Email
+	link.LastLoginAt = &now
+	if err := p.identities.Save(ctx, link); err != nil {
+		return nil, err
+	}
+
+	if len(p.cfg.OIDCStaffGroups) > 0 {
+		if staff := p.isStaff(identity.Groups); staff != user.IsStaff {
+			user.IsStaff = staff
+			if err := p.users.Save(ctx, user); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := p.syncGroups(ctx, user, identity.Groups); err != nil {
+		return nil, err
+	}
+	return p.users.FindByID(ctx, user.ID)
+}
+
+func (p *OIDCProvider) provisionUser(ctx context.Context, identity *OIDCIdentity) (*models.User, error) {
+	username, err := p.uniqueUsername(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username:  username,
+		Email:     identity.Email,
+		Password:  utils.UnusablePassword(),
+		FirstName: identity.GivenName,
+		LastName:  identity.FamilyName,
+		IsActive:  true,
+	}
+	if err := p.users.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to provision user: %w", err)
+	}
+	if err := p.users.AddToGroup(ctx, user, "Portal Users"); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// uniqueUsername membuat username dari preferred_username atau email,
+// menambahkan angka jika sudah dipakai
+func (p *OIDCProvider) uniqueUsername(ctx context.Context, identity *OIDCIdentity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = usernameSanitizer.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 2; i < 1000; i++ {
+		taken, err := p.users.UsernameTaken(ctx, candidate, 0)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	return "", fmt.Errorf("could not find free username for %q", base)
+}
+
+// syncGroups menyamakan keanggotaan grup yang dikelola mapping dengan klaim
+// grup dari identity provider; grup lain milik user tidak disentuh
+func (p *OIDCProvider) syncGroups(ctx context.Context, user *models.User, claimGroups []string) error {
+	wanted := make(map[string]bool)
+	for _, name := range p.MapGroups(claimGroups) {
+		wanted[name] = true
+	}
+
+	for _, name := range p.ManagedGroups() {
+		var err error
+		if wanted[name] {
+			err = p.users.AddToGroup(ctx, user, name)
+		} else {
+			err = p.users.RemoveFromGroup(ctx, user, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isStaff mengecek apakah salah satu klaim grup termasuk OIDC_STAFF_GROUPS
+func (p *OIDCProvider) isStaff(groups []string) bool {
+	for _, staffGroup := range p.cfg.OIDCStaffGroups {
+		if containsFold(groups, staffGroup) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"ticketing-fiber/app"
+	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/migrations"
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+
+	"golang.org/x/term"
+)
+
+const usage = `usage: ticketing-fiber [command]
+
+Tanpa command, server HTTP dijalankan.
+
+commands:
+  config <check|show>                    validasi / tampilkan konfigurasi
+  migrate <status|up|down|to|baseline>   kelola skema database
+  createsuperuser [-username u] [-email e] [-password p]
+                                         buat akun staff baru
+  user set-staff <username> [-off]       jadikan (atau cabut) status staff
+  user deactivate <username>             nonaktifkan akun dan logout semua sesinya
+  user activate <username>               aktifkan kembali akun
+  user set-password <username> [-password p]
+                                         reset password user
+  department add <name>                  tambah departemen
+  department list                        tampilkan semua departemen
+  seed                                   buat grup dan departemen default
+  send-test-email <to>                   kirim email uji coba via SMTP`
+
+// runCommand menjalankan subcommand CLI
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:])
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return nil
+	}
+
+	// Command selain migrate butuh skema terbaru
+	if err := checkSchema(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "createsuperuser":
+		return createSuperuser(ctx, cfg, args[1:])
+	case "user":
+		return runUserCommand(ctx, cfg, args[1:])
+	case "department":
+		return runDepartmentCommand(ctx, args[1:])
+	case "seed":
+		if err := app.SeedDefaultData(ctx, repository.NewGormDepartmentRepository(config.DB)); err != nil {
+			return err
+		}
+		fmt.Println("Default groups and departments are in place")
+		return nil
+	case "send-test-email":
+		return sendTestEmail(cfg, args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func checkSchema() error {
+	migrator, err := migrations.New(config.DB)
+	if err != nil {
+		return err
+	}
+	if err := migrator.Check(); err != nil {
+		return fmt.Errorf("%w\nRun `migrate up` first", err)
+	}
+	return nil
+}
+
+func createSuperuser(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("createsuperuser", flag.ContinueOnError)
+	username := fs.String("username", "", "username")
+	email := fs.String("email", "", "email")
+	password := fs.String("password", "", "password (ditanyakan jika kosong)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if *username == "" {
+		if *username, err = prompt("Username: "); err != nil {
+			return err
+		}
+	}
+	if *email == "" {
+		if *email, err = prompt("Email: "); err != nil {
+			return err
+		}
+	}
+	if *username == "" || *email == "" {
+		return errors.New("username and email are required")
+	}
+
+	users := repository.NewGormUserRepository(config.DB)
+	if taken, err := users.UsernameTaken(ctx, *username, 0); err != nil || taken {
+		return firstErr(err, fmt.Errorf("username %q is already taken", *username))
+	}
+	if taken, err := users.EmailTaken(ctx, *email, 0); err != nil || taken {
+		return firstErr(err, fmt.Errorf("email %q is already registered", *email))
+	}
+
+	hashed, err := newPasswordHash(cfg, *password, utils.PasswordUserInfo{Username: *username, Email: *email})
+	if err != nil {
+		return err
+	}
+
+	user := models.User{
+		Username: *username,
+		Email:    *email,
+		Password: hashed,
+		IsStaff:  true,
+		IsActive: true,
+	}
+	if err := users.Create(ctx, &user); err != nil {
+		return err
+	}
+	if err := users.AddToGroup(ctx, &user, "Portal Users"); err != nil {
+		return err
+	}
+
+	fmt.Printf("Staff user %s created (id %d)\n", user.Username, user.ID)
+	return nil
+}
+
+func runUserCommand(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		return errors.New("usage: user <set-staff|deactivate|activate|set-password> <username>")
+	}
+
+	action, username := args[0], args[1]
+	users := repository.NewGormUserRepository(config.DB)
+	user, err := users.FindByUsername(ctx, username)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("user %q not found", username)
+	} else if err != nil {
+		return err
+	}
+
+	switch action {
+	case "set-staff":
+		fs := flag.NewFlagSet("user set-staff", flag.ContinueOnError)
+		off := fs.Bool("off", false, "cabut status staff")
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+		user.IsStaff = !*off
+		if err := users.Save(ctx, user); err != nil {
+			return err
+		}
+		fmt.Printf("User %s staff: %t\n", user.Username, user.IsStaff)
+
+	case "deactivate":
+		user.IsActive = false
+		if err := users.Save(ctx, user); err != nil {
+			return err
+		}
+		revoked, err := revokeAllSessions(cfg, user.ID)
+		if err != nil {
+			return fmt.Errorf("user deactivated but failed to revoke sessions: %w", err)
+		}
+		fmt.Printf("User %s deactivated, %d session(s) revoked\n", user.Username, revoked)
+
+	case "activate":
+		user.IsActive = true
+		if err := users.Save(ctx, user); err != nil {
+			return err
+		}
+		fmt.Printf("User %s activated\n", user.Username)
+
+	case "set-password":
+		fs := flag.NewFlagSet("user set-password", flag.ContinueOnError)
+		password := fs.String("password", "", "password baru (ditanyakan jika kosong)")
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+		hashed, err := newPasswordHash(cfg, *password, utils.PasswordUserInfo{
+			Username:  user.Username,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+		})
+		if err != nil {
+			return err
+		}
+		user.Password = hashed
+		if err := users.Save(ctx, user); err != nil {
+			return err
+		}
+		revoked, err := revokeAllSessions(cfg, user.ID)
+		if err != nil {
+			return fmt.Errorf("password changed but failed to revoke sessions: %w", err)
+		}
+		fmt.Printf("Password for %s changed, %d session(s) revoked\n", user.Username, revoked)
+
+	default:
+		return fmt.Errorf("unknown user command %q", action)
+	}
+	return nil
+}
+
+func runDepartmentCommand(ctx context.Context, args []string) error {
+	departments := repository.NewGormDepartmentRepository(config.DB)
+
+	if len(args) == 0 {
+		return errors.New("usage: department <add|list>")
+	}
+	switch args[0] {
+	case "add":
+		name := strings.TrimSpace(strings.Join(args[1:], " "))
+		if name == "" {
+			return errors.New("usage: department add <name>")
+		}
+		department, err := departments.FirstOrCreate(ctx, name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Department %q (id %d)\n", department.Name, department.ID)
+	case "list":
+		list, err := departments.List(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME")
+		for _, d := range list {
+			fmt.Fprintf(w, "%d\t%s\n", d.ID, d.Name)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown department command %q", args[0])
+	}
+	return nil
+}
+
+func sendTestEmail(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: send-test-email <to>")
+	}
+	body := fmt.Sprintf("Email ini dikirim dari %s untuk menguji konfigurasi SMTP (%s:%d).",
+		cfg.AppName, cfg.EmailHost, cfg.EmailPort)
+	if err := utils.NewEmailService(cfg).SendMail(context.Background(), args, "Test email "+cfg.AppName, body); err != nil {
+		return err
+	}
+	fmt.Printf("Test email sent to %s\n", strings.Join(args, ", "))
+	return nil
+}
+
+// newPasswordHash memvalidasi password terhadap kebijakan lalu meng-hash-nya;
+// password kosong ditanyakan dua kali tanpa echo
+func newPasswordHash(cfg *config.Config, password string, info utils.PasswordUserInfo) (string, error) {
+	if password == "" {
+		first, err := promptPassword("Password: ")
+		if err != nil {
+			return "", err
+		}
+		second, err := promptPassword("Password (again): ")
+		if err != nil {
+			return "", err
+		}
+		if first != second {
+			return "", errors.New("passwords do not match")
+		}
+		password = first
+	}
+
+	hasher := utils.NewPasswordHasher(cfg)
+	if err := hasher.Policy(utils.DefaultPasswordPolicy).Validate(i18n.English, password, info); err != nil {
+		return "", err
+	}
+	return hasher.Hash(password)
+}
+
+// revokeAllSessions menghapus semua session login user dari storage
+func revokeAllSessions(cfg *config.Config, userID uint) (int, error) {
+	if err := config.InitSessionStore(cfg); err != nil {
+		return 0, err
+	}
+	if cfg.SessionStorage == config.SessionStorageMemory {
+		fmt.Fprintln(os.Stderr, "warning: memory session storage is per-process; restart the server to log the user out")
+	}
+
+	ctx := context.Background()
+	sessions := repository.NewGormSessionRepository(config.DB)
+	records, err := sessions.ListForUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for i, record := range records {
+		if err := config.Store.Delete(record.SessionID); err != nil {
+			return i, err
+		}
+		if err := sessions.Delete(ctx, record.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(records), nil
+}
+
+var stdin = bufio.NewReader(os.Stdin)
+
+func prompt(label string) (string, error) {
+	fmt.Print(label)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func promptPassword(label string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return prompt(label)
+	}
+	fmt.Print(label)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	return string(password), err
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,52 +1,480 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSessionSecret placeholder secret bawaan, ditolak di production
+const DefaultSessionSecret = "your-secret-key-change-in-production"
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
 )
 
+// Config konfigurasi aplikasi. Nilai diambil dari Default, lalu file YAML
+// (key = tag yaml), lalu environment variable (tag env). Tag sep dan kvsep
+// menentukan pemisah saat env var di-parse ke slice atau map.
 type Config struct {
+	// Environment "development" atau "production"; di production pengaturan
+	// yang tidak aman membuat aplikasi menolak start
+	Environment string `yaml:"app_env" env:"APP_ENV"`
+
 	// Server
-	Port string
+	Port string `yaml:"port" env:"PORT"`
+	// ShutdownTimeout batas waktu menunggu request dan job latar belakang
+	// selesai saat menerima SIGINT/SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// ReadyzCheckMail ikut mengecek koneksi ke server SMTP di /readyz
+	ReadyzCheckMail bool `yaml:"readyz_check_mail" env:"READYZ_CHECK_MAIL"`
+	// RealtimePollInterval jeda pengecekan database untuk balasan dan
+	// perubahan tiket yang ditulis aplikasi lain (misalnya panel staff),
+	// agar dikirim ke stream /events dan dicatat sebagai notifikasi;
+	// 0 mematikan pengecekan
+	RealtimePollInterval time.Duration `yaml:"realtime_poll_interval" env:"REALTIME_POLL_INTERVAL"`
+	// RealtimeSessionCheck jeda pengecekan ulang session milik stream
+	// /events, agar stream perangkat yang logout atau dicabut ikut ditutup
+	RealtimeSessionCheck time.Duration `yaml:"realtime_session_check" env:"REALTIME_SESSION_CHECK"`
 
-	// Database
-	DatabasePath string
+	// Database; DatabaseURL kosong berarti SQLite di DatabasePath
+	DatabaseDriver    string        `yaml:"db_driver" env:"DB_DRIVER"`
+	DatabaseURL       string        `yaml:"database_url" env:"DATABASE_URL"`
+	DatabasePath      string        `yaml:"db_path" env:"DB_PATH"`
+	DBMaxOpenConns    int           `yaml:"db_max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns    int           `yaml:"db_max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime time.Duration `yaml:"db_conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime time.Duration `yaml:"db_conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	// DBAutoMigrate menjalankan `migrate up` saat start, bukan menolak start
+	DBAutoMigrate bool `yaml:"db_auto_migrate" env:"DB_AUTO_MIGRATE"`
 
 	// Email
-	EmailHost     string
-	EmailPort     int
-	EmailUsername string
-	EmailPassword string
-	EmailFrom     string
+	EmailHost     string `yaml:"email_host" env:"EMAIL_HOST"`
+	EmailPort     int    `yaml:"email_port" env:"EMAIL_PORT"`
+	EmailUsername string `yaml:"email_user" env:"EMAIL_USER"`
+	EmailPassword string `yaml:"email_password" env:"EMAIL_PASSWORD"`
+	EmailFrom     string `yaml:"email_from" env:"EMAIL_FROM"`
+
+	// DigestInterval jarak antar email ringkasan untuk user yang
+	// memilih ringkasan harian; 0 mematikan pengiriman ringkasan
+	DigestInterval time.Duration `yaml:"notification_digest_interval" env:"NOTIFICATION_DIGEST_INTERVAL"`
+	// WebhookAllowPrivate mengizinkan webhook notifikasi ke loopback dan
+	// jaringan privat; hanya untuk pengembangan
+	WebhookAllowPrivate bool `yaml:"webhook_allow_private" env:"WEBHOOK_ALLOW_PRIVATE"`
 
 	// Session
-	SessionSecret string
-	SessionExpiry time.Duration
+	SessionSecret     string        `yaml:"session_secret" env:"SESSION_SECRET"`
+	SessionExpiry     time.Duration `yaml:"session_expiry" env:"SESSION_EXPIRY"`
+	SessionStorage    string        `yaml:"session_storage" env:"SESSION_STORAGE"`
+	SessionGCInterval time.Duration `yaml:"session_gc_interval" env:"SESSION_GC_INTERVAL"`
+	RedisURL          string        `yaml:"redis_url" env:"REDIS_URL"`
+	CookieSecure      bool          `yaml:"cookie_secure" env:"COOKIE_SECURE"`
+
+	// Password hashing
+	PasswordHashAlgorithm string `yaml:"password_hash_algorithm" env:"PASSWORD_HASH_ALGORITHM"`
+	BcryptCost            int    `yaml:"bcrypt_cost" env:"BCRYPT_COST"`
+	Argon2Memory          uint32 `yaml:"argon2_memory_kb" env:"ARGON2_MEMORY_KB"`
+	Argon2Time            uint32 `yaml:"argon2_time" env:"ARGON2_TIME"`
+	Argon2Threads         uint8  `yaml:"argon2_threads" env:"ARGON2_THREADS"`
+
+	// Authentication backends dicoba berurutan, misalnya "ldap,local"
+	AuthBackends []string `yaml:"auth_backends" env:"AUTH_BACKENDS"`
+
+	// LDAP directory
+	LDAPURL                string            `yaml:"ldap_url" env:"LDAP_URL"`
+	LDAPStartTLS           bool              `yaml:"ldap_starttls" env:"LDAP_STARTTLS"`
+	LDAPInsecureSkipVerify bool              `yaml:"ldap_insecure_skip_verify" env:"LDAP_INSECURE_SKIP_VERIFY"`
+	LDAPBindDN             string            `yaml:"ldap_bind_dn" env:"LDAP_BIND_DN"`
+	LDAPBindPassword       string            `yaml:"ldap_bind_password" env:"LDAP_BIND_PASSWORD"`
+	LDAPBaseDN             string            `yaml:"ldap_base_dn" env:"LDAP_BASE_DN"`
+	LDAPUserFilter         string            `yaml:"ldap_user_filter" env:"LDAP_USER_FILTER"`
+	LDAPAttrUsername       string            `yaml:"ldap_attr_username" env:"LDAP_ATTR_USERNAME"`
+	LDAPAttrEmail          string            `yaml:"ldap_attr_email" env:"LDAP_ATTR_EMAIL"`
+	LDAPAttrFirstName      string            `yaml:"ldap_attr_first_name" env:"LDAP_ATTR_FIRST_NAME"`
+	LDAPAttrLastName       string            `yaml:"ldap_attr_last_name" env:"LDAP_ATTR_LAST_NAME"`
+	LDAPAttrGroups         string            `yaml:"ldap_attr_groups" env:"LDAP_ATTR_GROUPS"`
+	LDAPStaffGroups        []string          `yaml:"ldap_staff_groups" env:"LDAP_STAFF_GROUPS" sep:";"`
+	LDAPDepartmentMapping  map[string]string `yaml:"ldap_department_mapping" env:"LDAP_DEPARTMENT_MAPPING" sep:";" kvsep:":"`
+
+	// OpenID Connect SSO
+	OIDCIssuerURL     string            `yaml:"oidc_issuer_url" env:"OIDC_ISSUER_URL"`
+	OIDCClientID      string            `yaml:"oidc_client_id" env:"OIDC_CLIENT_ID"`
+	OIDCClientSecret  string            `yaml:"oidc_client_secret" env:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL   string            `yaml:"oidc_redirect_url" env:"OIDC_REDIRECT_URL"`
+	OIDCScopes        []string          `yaml:"oidc_scopes" env:"OIDC_SCOPES" sep:" "`
+	OIDCGroupsClaim   string            `yaml:"oidc_groups_claim" env:"OIDC_GROUPS_CLAIM"`
+	OIDCGroupMapping  map[string]string `yaml:"oidc_group_mapping" env:"OIDC_GROUP_MAPPING"`
+	OIDCStaffGroups   []string          `yaml:"oidc_staff_groups" env:"OIDC_STAFF_GROUPS" sep:";"`
+	OIDCAutoProvision bool              `yaml:"oidc_auto_provision" env:"OIDC_AUTO_PROVISION"`
+	OIDCDisplayName   string            `yaml:"oidc_display_name" env:"OIDC_DISPLAY_NAME"`
+
+	// Logging
+	LogFormat string `yaml:"log_format" env:"LOG_FORMAT"`
+	LogLevel  string `yaml:"log_level" env:"LOG_LEVEL"`
+
+	// OpenTelemetry tracing; exporter "none", "stdout" atau "otlp". Endpoint
+	// OTLP kosong berarti memakai env standar OTEL_EXPORTER_OTLP_*
+	TracingExporter     string  `yaml:"tracing_exporter" env:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint string  `yaml:"tracing_otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	TracingServiceName  string  `yaml:"tracing_service_name" env:"OTEL_SERVICE_NAME"`
+	TracingSampleRatio  float64 `yaml:"tracing_sample_ratio" env:"TRACING_SAMPLE_RATIO"`
 
 	// App
-	AppName string
-	Debug   bool
+	AppName      string `yaml:"app_name" env:"APP_NAME"`
+	Debug        bool   `yaml:"debug" env:"DEBUG"`
+	TemplatesDir string `yaml:"templates_dir" env:"TEMPLATES_DIR"`
+	StaticDir    string `yaml:"static_dir" env:"STATIC_DIR"`
+
+	// DefaultTimeZone zona waktu tampilan (nama IANA) untuk user yang belum
+	// memilih zona waktu sendiri
+	DefaultTimeZone string `yaml:"default_time_zone" env:"DEFAULT_TIME_ZONE"`
+
+	// Source path file konfigurasi yang dibaca, kosong jika hanya env
+	Source string `yaml:"-"`
 }
 
-func LoadConfig() *Config {
+// Default konfigurasi bawaan untuk development lokal
+func Default() *Config {
 	return &Config{
-		Port:          getEnv("PORT", "3000"),
-		DatabasePath:  getEnv("DB_PATH", "./ticketing.db"),
-		EmailHost:     getEnv("EMAIL_HOST", "mail.cloudtech.id"),
-		EmailPort:     587,
-		EmailUsername: getEnv("EMAIL_USER", "daffa@cloudtech.id"),
-		EmailPassword: getEnv("EMAIL_PASSWORD", ""),
-		EmailFrom:     getEnv("EMAIL_FROM", "daffa@cloudtech.id"),
-		SessionSecret: getEnv("SESSION_SECRET", "your-secret-key-change-in-production"),
-		SessionExpiry: 24 * time.Hour,
-		AppName:       "Ticketing System",
-		Debug:         getEnv("DEBUG", "true") == "true",
-	}
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+		Environment:           EnvDevelopment,
+		Port:                  "3000",
+		ShutdownTimeout:       30 * time.Second,
+		RealtimePollInterval:  5 * time.Second,
+		RealtimeSessionCheck:  30 * time.Second,
+		DigestInterval:        24 * time.Hour,
+		DatabasePath:          "./ticketing.db",
+		DBMaxOpenConns:        25,
+		DBMaxIdleConns:        5,
+		DBConnMaxLifetime:     30 * time.Minute,
+		DBConnMaxIdleTime:     5 * time.Minute,
+		EmailPort:             587,
+		SessionSecret:         DefaultSessionSecret,
+		SessionExpiry:         24 * time.Hour,
+		SessionStorage:        SessionStorageDatabase,
+		SessionGCInterval:     10 * time.Minute,
+		PasswordHashAlgorithm: "argon2id",
+		BcryptCost:            12,
+		Argon2Memory:          64 * 1024,
+		Argon2Time:            3,
+		Argon2Threads:         2,
+		AuthBackends:          []string{"local"},
+		LDAPUserFilter:        "(uid=%s)",
+		LDAPAttrUsername:      "uid",
+		LDAPAttrEmail:         "mail",
+		LDAPAttrFirstName:     "givenName",
+		LDAPAttrLastName:      "sn",
+		LDAPAttrGroups:        "memberOf",
+		OIDCScopes:            []string{"openid", "email", "profile"},
+		OIDCGroupsClaim:       "groups",
+		OIDCAutoProvision:     true,
+		OIDCDisplayName:       "SSO",
+		LogFormat:             "text",
+		LogLevel:              "info",
+		TracingExporter:       "none",
+		TracingServiceName:    "ticketing-fiber",
+		TracingSampleRatio:    1,
+		AppName:               "Ticketing System",
+		DefaultTimeZone:       "Asia/Jakarta",
+		Debug:                 true,
+		TemplatesDir:          "./templates",
+		StaticDir:             "./static",
+	}
+}
+
+// ConfigFilePath path file konfigurasi dari CONFIG_FILE, atau ./config.yaml
+// jika file tersebut ada
+func ConfigFilePath() string {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return path
+	}
+	if _, err := os.Stat("config.yaml"); err == nil {
+		return "config.yaml"
+	}
+	return ""
+}
+
+// LoadConfig membaca konfigurasi dari ConfigFilePath dan environment
+func LoadConfig() (*Config, error) {
+	return Load(ConfigFilePath())
+}
+
+// Load menggabungkan Default, file YAML di path (boleh kosong) dan env
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		cfg.Source = path
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, fmt.Errorf("invalid environment variable: %w", err)
+	}
+
+	if cfg.OIDCRedirectURL == "" {
+		cfg.OIDCRedirectURL = "http://localhost:" + cfg.Port + "/login/oidc/callback"
+	}
+	return cfg, nil
+}
+
+// applyEnv menimpa field yang env var-nya diisi. Nilai yang tidak bisa
+// di-parse dikumpulkan sebagai error, bukan diam-diam diabaikan.
+func applyEnv(cfg *Config) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		if err := setField(v.Field(i), field.Tag, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setField(f reflect.Value, tag reflect.StructTag, value string) error {
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Uint8, reflect.Uint32:
+		n, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Slice:
+		if sep := tagOr(tag, "sep", ","); sep == " " {
+			f.Set(reflect.ValueOf(strings.Fields(value)))
+		} else {
+			f.Set(reflect.ValueOf(splitList(value, sep)))
+		}
+	case reflect.Map:
+		f.Set(reflect.ValueOf(parseMappingSep(value, tagOr(tag, "sep", ","), tagOr(tag, "kvsep", "="))))
+	default:
+		return fmt.Errorf("unsupported config type %s", f.Type())
+	}
+	return nil
+}
+
+func tagOr(tag reflect.StructTag, key, fallback string) string {
+	if value := tag.Get(key); value != "" {
 		return value
 	}
-	return defaultValue
+	return fallback
+}
+
+// Validate memeriksa konfigurasi. Error berarti aplikasi tidak boleh start.
+// Pengaturan yang tidak aman menjadi error di production dan hanya warning
+// di development.
+func (c *Config) Validate() (warnings []string, err error) {
+	var problems []string
+	insecure := func(msg string) {
+		if c.IsProduction() {
+			problems = append(problems, msg)
+		} else {
+			warnings = append(warnings, msg)
+		}
+	}
+
+	switch c.Environment {
+	case EnvDevelopment, EnvProduction:
+	default:
+		problems = append(problems, fmt.Sprintf("app_env must be %q or %q, got %q", EnvDevelopment, EnvProduction, c.Environment))
+	}
+
+	if n, err := strconv.Atoi(c.Port); err != nil || n < 1 || n > 65535 {
+		problems = append(problems, fmt.Sprintf("port %q is not a valid TCP port", c.Port))
+	}
+	if c.ShutdownTimeout <= 0 {
+		problems = append(problems, "shutdown_timeout must be positive")
+	}
+	if c.RealtimePollInterval < 0 {
+		problems = append(problems, "realtime_poll_interval must not be negative")
+	}
+	if c.RealtimeSessionCheck <= 0 {
+		problems = append(problems, "realtime_session_check must be positive")
+	}
+	if c.DigestInterval < 0 {
+		problems = append(problems, "notification_digest_interval must not be negative")
+	}
+	if c.EmailPort < 1 || c.EmailPort > 65535 {
+		problems = append(problems, fmt.Sprintf("email_port %d is not a valid TCP port", c.EmailPort))
+	}
+
+	if driver, _ := resolveDatabase(c); driver != DriverSQLite && driver != DriverPostgres && driver != DriverMySQL {
+		problems = append(problems, fmt.Sprintf("cannot determine database driver (db_driver %q, database_url scheme unknown)", c.DatabaseDriver))
+	}
+
+	switch c.SessionStorage {
+	case SessionStorageDatabase, SessionStorageMemory:
+	case SessionStorageRedis:
+		if c.RedisURL == "" {
+			problems = append(problems, "redis_url is required when session_storage is redis")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("unknown session_storage %q", c.SessionStorage))
+	}
+	if c.SessionExpiry <= 0 {
+		problems = append(problems, "session_expiry must be positive")
+	}
+
+	switch c.LogFormat {
+	case "text", "json":
+	default:
+		problems = append(problems, fmt.Sprintf("log_format must be text or json, got %q", c.LogFormat))
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("log_level must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+
+	switch c.TracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		problems = append(problems, fmt.Sprintf("tracing_exporter must be none, stdout or otlp, got %q", c.TracingExporter))
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		problems = append(problems, fmt.Sprintf("tracing_sample_ratio must be between 0 and 1, got %v", c.TracingSampleRatio))
+	}
+
+	if _, err := time.LoadLocation(c.DefaultTimeZone); c.DefaultTimeZone == "" || err != nil {
+		problems = append(problems, fmt.Sprintf("default_time_zone %q is not a valid IANA time zone", c.DefaultTimeZone))
+	}
+
+	switch c.PasswordHashAlgorithm {
+	case "argon2id", "bcrypt":
+	default:
+		problems = append(problems, fmt.Sprintf("unknown password_hash_algorithm %q", c.PasswordHashAlgorithm))
+	}
+
+	for _, backend := range c.AuthBackends {
+		switch backend {
+		case "local":
+		case "ldap":
+			if c.LDAPURL == "" || c.LDAPBaseDN == "" {
+				problems = append(problems, "ldap_url and ldap_base_dn are required when auth_backends contains ldap")
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("unknown auth backend %q", backend))
+		}
+	}
+
+	if c.EmailHost == "" || c.EmailFrom == "" {
+		warnings = append(warnings, "email_host or email_from is empty, outgoing email will fail")
+	}
+
+	if c.SessionSecret == "" || c.SessionSecret == DefaultSessionSecret {
+		insecure("session_secret is empty or the built-in default")
+	} else if len(c.SessionSecret) < 32 {
+		insecure("session_secret should be at least 32 characters")
+	}
+	if !c.CookieSecure {
+		insecure("cookie_secure is false, session cookies are sent over plain HTTP")
+	}
+	if c.Debug {
+		insecure("debug is enabled")
+	}
+	if c.LDAPInsecureSkipVerify {
+		insecure("ldap_insecure_skip_verify disables LDAP TLS certificate checks")
+	}
+
+	if len(problems) > 0 {
+		return warnings, fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return warnings, nil
+}
+
+// IsProduction mengecek apakah aplikasi berjalan di production
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvProduction
+}
+
+// OIDCEnabled mengecek apakah login SSO OpenID Connect dikonfigurasi
+func (c *Config) OIDCEnabled() bool {
+	return c.OIDCIssuerURL != "" && c.OIDCClientID != ""
+}
+
+// parseMappingSep mengubah "a=b,c=d" menjadi map{a: b, c: d} dengan pemisah
+// sendiri; key dan value dipisah pada kemunculan kvSep terakhir sehingga key
+// boleh berisi kvSep (misalnya DN LDAP)
+func parseMappingSep(value, entrySep, kvSep string) map[string]string {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(value, entrySep) {
+		i := strings.LastIndex(pair, kvSep)
+		if i < 0 {
+			continue
+		}
+		key, val := strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+len(kvSep):])
+		if key != "" && val != "" {
+			mapping[key] = val
+		}
+	}
+	return mapping
+}
+
+// splitList memecah value dengan sep dan membuang item kosong
+func splitList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		env   string
+		value string
+		got   func(c *Config) interface{}
+		want  interface{}
+	}{
+		{"PORT", "8080", func(c *Config) interface{} { return c.Port }, "8080"},
+		{"EMAIL_PORT", "2525", func(c *Config) interface{} { return c.EmailPort }, 2525},
+		{"BCRYPT_COST", "12", func(c *Config) interface{} { return c.BcryptCost }, 12},
+		{"ARGON2_MEMORY_KB", "131072", func(c *Config) interface{} { return c.Argon2Memory }, uint32(131072)},
+		{"ARGON2_THREADS", "4", func(c *Config) interface{} { return c.Argon2Threads }, uint8(4)},
+		{"TRACING_SAMPLE_RATIO", "0.25", func(c *Config) interface{} { return c.TracingSampleRatio }, 0.25},
+		{"COOKIE_SECURE", "true", func(c *Config) interface{} { return c.CookieSecure }, true},
+		{"DB_AUTO_MIGRATE", "0", func(c *Config) interface{} { return c.DBAutoMigrate }, false},
+		{"SESSION_EXPIRY", "90m", func(c *Config) interface{} { return c.SessionExpiry }, 90 * time.Minute},
+		{"REALTIME_POLL_INTERVAL", "0s", func(c *Config) interface{} { return c.RealtimePollInterval }, time.Duration(0)},
+		{"AUTH_BACKENDS", "ldap, local,", func(c *Config) interface{} { return c.AuthBackends }, []string{"ldap", "local"}},
+		{"LDAP_STAFF_GROUPS", "cn=it,dc=corp; cn=ops,dc=corp", func(c *Config) interface{} { return c.LDAPStaffGroups }, []string{"cn=it,dc=corp", "cn=ops,dc=corp"}},
+		{"OIDC_SCOPES", "openid  email profile", func(c *Config) interface{} { return c.OIDCScopes }, []string{"openid", "email", "profile"}},
+		{"OIDC_GROUP_MAPPING", "it=IT,ops=Operations", func(c *Config) interface{} { return c.OIDCGroupMapping }, map[string]string{"it": "IT", "ops": "Operations"}},
+		{"LDAP_DEPARTMENT_MAPPING", "cn=it,dc=corp:IT;cn=ops,dc=corp:Operations", func(c *Config) interface{} { return c.LDAPDepartmentMapping }, map[string]string{"cn=it,dc=corp": "IT", "cn=ops,dc=corp": "Operations"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			cfg := Default()
+			if err := applyEnv(cfg); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.got(cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s=%q gives %#v; want %#v", tt.env, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnvCollectsErrors(t *testing.T) {
+	t.Setenv("EMAIL_PORT", "smtp")
+	t.Setenv("COOKIE_SECURE", "yes please")
+	t.Setenv("SESSION_EXPIRY", "24")
+	t.Setenv("ARGON2_THREADS", "300")
+	t.Setenv("PORT", "9000")
+
+	cfg := Default()
+	err := applyEnv(cfg)
+	if err == nil {
+		t.Fatal("applyEnv accepted invalid values")
+	}
+	for _, key := range []string{"EMAIL_PORT", "COOKIE_SECURE", "SESSION_EXPIRY", "ARGON2_THREADS"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error does not mention %s: %v", key, err)
+		}
+	}
+	// Nilai yang valid tetap diterapkan
+	if cfg.Port != "9000" {
+		t.Errorf("Port = %q; want 9000", cfg.Port)
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "port: \"4000\"\nsession_expiry: 2h\nauth_backends: [ldap, local]\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PORT", "5000")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "5000" || cfg.SessionExpiry != 2*time.Hour || !reflect.DeepEqual(cfg.AuthBackends, []string{"ldap", "local"}) {
+		t.Errorf("Load = port %q, session_expiry %v, auth_backends %v", cfg.Port, cfg.SessionExpiry, cfg.AuthBackends)
+	}
+	if cfg.OIDCRedirectURL != "http://localhost:5000/login/oidc/callback" {
+		t.Errorf("OIDCRedirectURL = %q", cfg.OIDCRedirectURL)
+	}
+
+	if err := os.WriteFile(path, []byte("prot: \"4000\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Errorf("Load with unknown key = %v; want error naming it", err)
+	}
+}
+
+// secureConfig konfigurasi production yang lolos Validate
+func secureConfig() *Config {
+	cfg := Default()
+	cfg.Environment = EnvProduction
+	cfg.SessionSecret = strings.Repeat("s", 32)
+	cfg.CookieSecure = true
+	cfg.Debug = false
+	cfg.EmailHost = "smtp.example.com"
+	cfg.EmailFrom = "helpdesk@example.com"
+	return cfg
+}
+
+func TestValidateInsecureSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		problem string
+	}{
+		{"default session secret", func(c *Config) { c.SessionSecret = DefaultSessionSecret }, "session_secret is empty"},
+		{"empty session secret", func(c *Config) { c.SessionSecret = "" }, "session_secret is empty"},
+		{"short session secret", func(c *Config) { c.SessionSecret = "short" }, "at least 32 characters"},
+		{"plain HTTP cookies", func(c *Config) { c.CookieSecure = false }, "cookie_secure is false"},
+		{"debug", func(c *Config) { c.Debug = true }, "debug is enabled"},
+		{"LDAP without TLS checks", func(c *Config) { c.LDAPInsecureSkipVerify = true }, "ldap_insecure_skip_verify"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Production: pengaturan tidak aman menolak start
+			cfg := secureConfig()
+			tt.modify(cfg)
+			warnings, err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("production Validate error = %v; want %q", err, tt.problem)
+			}
+			if len(warnings) != 0 {
+				t.Errorf("production warnings = %v; want them as errors", warnings)
+			}
+
+			// Development: hanya warning
+			cfg.Environment = EnvDevelopment
+			warnings, err = cfg.Validate()
+			if err != nil {
+				t.Errorf("development Validate error = %v", err)
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.problem) {
+				t.Errorf("development warnings = %v; want %q", warnings, tt.problem)
+			}
+		})
+	}
+
+	if warnings, err := secureConfig().Validate(); err != nil || len(warnings) != 0 {
+		t.Errorf("secure production config: warnings %v, error %v", warnings, err)
+	}
+}
+
+func TestValidateRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		problem string
+	}{
+		{"environment", func(c *Config) { c.Environment = "staging" }, "app_env"},
+		{"port", func(c *Config) { c.Port = "70000" }, "not a valid TCP port"},
+		{"negative poll interval", func(c *Config) { c.RealtimePollInterval = -time.Second }, "realtime_poll_interval"},
+		{"redis without url", func(c *Config) { c.SessionStorage = SessionStorageRedis }, "redis_url is required"},
+		{"time zone", func(c *Config) { c.DefaultTimeZone = "Mars/Olympus" }, "default_time_zone"},
+		{"hash algorithm", func(c *Config) { c.PasswordHashAlgorithm = "md5" }, "password_hash_algorithm"},
+		{"ldap backend without url", func(c *Config) { c.AuthBackends = []string{"ldap", "local"} }, "ldap_url and ldap_base_dn"},
+		{"sample ratio", func(c *Config) { c.TracingSampleRatio = 2 }, "tracing_sample_ratio"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Nilai tidak valid menjadi error di semua environment
+			for _, env := range []string{EnvDevelopment, EnvProduction} {
+				cfg := secureConfig()
+				cfg.Environment = env
+				tt.modify(cfg)
+				if _, err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.problem) {
+					t.Errorf("%s Validate error = %v; want %q", env, err, tt.problem)
+				}
+			}
+		})
+	}
+}
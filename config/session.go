@@ -0,0 +1,196 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"ticketing-fiber/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	redisstorage "github.com/gofiber/storage/redis/v3"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SessionStorageDatabase = "database"
+	SessionStorageRedis    = "redis"
+	SessionStorageMemory   = "memory"
+)
+
+// InitSessionStore membuat session store sesuai cfg.SessionStorage
+func InitSessionStore(cfg *Config) error {
+	storage, err := newSessionStorage(cfg)
+	if err != nil {
+		return err
+	}
+
+	Store = session.New(session.Config{
+		Expiration:     cfg.SessionExpiry,
+		Storage:        storage,
+		CookieHTTPOnly: true,
+		CookieSecure:   cfg.CookieSecure,
+		CookieSameSite: "Lax",
+	})
+
+	slog.Info("session storage ready", "storage", cfg.SessionStorage)
+	return nil
+}
+
+func newSessionStorage(cfg *Config) (fiber.Storage, error) {
+	switch cfg.SessionStorage {
+	case SessionStorageDatabase, "":
+		if DB == nil {
+			return nil, errors.New("session storage database membutuhkan koneksi database")
+		}
+		return NewGormStorage(DB, cfg.SessionGCInterval), nil
+	case SessionStorageRedis:
+		return newRedisStorage(cfg.RedisURL)
+	case SessionStorageMemory:
+		// nil berarti Fiber memakai memory storage bawaan
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown session storage %q", cfg.SessionStorage)
+	}
+}
+
+func newRedisStorage(url string) (fiber.Storage, error) {
+	if url == "" {
+		return nil, errors.New("REDIS_URL wajib diisi untuk session storage redis")
+	}
+
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect redis: %w", err)
+	}
+
+	return redisstorage.NewFromConnection(client), nil
+}
+
+// SessionCookieKey menurunkan kunci AES-256 (base64) dari SessionSecret
+// untuk middleware encryptcookie
+func SessionCookieKey(cfg *Config) string {
+	sum := sha256.Sum256([]byte(cfg.SessionSecret))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// GormStorage adalah implementasi fiber.Storage yang menyimpan session
+// di tabel sessions pada database aplikasi
+type GormStorage struct {
+	db        *gorm.DB
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewGormStorage membuat storage baru dan menjalankan garbage collector
+// session kedaluwarsa setiap gcInterval (0 = tanpa GC otomatis)
+func NewGormStorage(db *gorm.DB, gcInterval time.Duration) *GormStorage {
+	s := &GormStorage{
+		db:   db,
+		done: make(chan struct{}),
+	}
+	if gcInterval > 0 {
+		go s.gcLoop(gcInterval)
+	}
+	return s
+}
+
+// Get mengembalikan data session, atau nil jika tidak ada / sudah kedaluwarsa
+func (s *GormStorage) Get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	var record models.Session
+	err := s.db.Where("id = ?", key).Limit(1).Find(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	if record.ID == "" || record.IsExpired(time.Now()) {
+		return nil, nil
+	}
+	return record.Data, nil
+}
+
+// Set menyimpan data session; exp 0 berarti tidak pernah kedaluwarsa
+func (s *GormStorage) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+
+	record := models.Session{
+		ID:   key,
+		Data: val,
+	}
+	if exp > 0 {
+		expiresAt := time.Now().UTC().Add(exp)
+		record.ExpiresAt = &expiresAt
+	}
+
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "expires_at", "updated_at"}),
+	}).Create(&record).Error
+}
+
+// Delete menghapus session berdasarkan key
+func (s *GormStorage) Delete(key string) error {
+	if key == "" {
+		return nil
+	}
+	return s.db.Where("id = ?", key).Delete(&models.Session{}).Error
+}
+
+// Reset menghapus semua session
+func (s *GormStorage) Reset() error {
+	return s.db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Session{}).Error
+}
+
+// Close menghentikan garbage collector
+func (s *GormStorage) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	return nil
+}
+
+// DeleteExpired menghapus semua session yang sudah kedaluwarsa
+func (s *GormStorage) DeleteExpired() (int64, error) {
+	result := s.db.Where("expires_at IS NOT NULL AND expires_at <= ?", time.Now().UTC()).
+		Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
+
+func (s *GormStorage) gcLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if n, err := s.DeleteExpired(); err != nil {
+				slog.Error("session gc failed", "error", err)
+			} else if n > 0 {
+				slog.Info("session gc removed expired sessions", "count", n)
+			}
+		}
+	}
+}
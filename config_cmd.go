@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"ticketing-fiber/config"
+)
+
+const configUsage = `usage: ticketing-fiber config <command>
+
+commands:
+  check             validasi konfigurasi (file + env) tanpa menjalankan server
+  show              tampilkan konfigurasi efektif, nilai rahasia disamarkan`
+
+// secretKeys key konfigurasi yang nilainya tidak boleh dicetak
+var secretKeys = map[string]bool{
+	"session_secret":     true,
+	"email_password":     true,
+	"ldap_bind_password": true,
+	"oidc_client_secret": true,
+	"database_url":       true,
+	"redis_url":          true,
+}
+
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return errors.New("missing config command")
+	}
+
+	switch args[0] {
+	case "check":
+		return checkConfig(cfg)
+	case "show":
+		printConfig(cfg)
+		return nil
+	default:
+		fmt.Fprintln(os.Stderr, configUsage)
+		return fmt.Errorf("unknown config command %q", args[0])
+	}
+}
+
+func checkConfig(cfg *config.Config) error {
+	source := cfg.Source
+	if source == "" {
+		source = "(environment only)"
+	}
+	fmt.Printf("Config file: %s\n", source)
+	fmt.Printf("Environment: %s\n", cfg.Environment)
+
+	warnings, err := cfg.Validate()
+	for _, w := range warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("Configuration OK")
+	return nil
+}
+
+func printConfig(cfg *config.Config) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("yaml")
+		if key == "" || key == "-" {
+			continue
+		}
+		value := fmt.Sprint(v.Field(i).Interface())
+		if secretKeys[key] && value != "" {
+			value = "********"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key, t.Field(i).Tag.Get("env"), strings.TrimSpace(value))
+	}
+}
@@ -0,0 +1,182 @@
+// Package forms mengikat body request (form HTML atau JSON) ke struct lalu
+// memvalidasinya berdasarkan tag `validate`. Kegagalan validasi dikembalikan
+// sebagai apperrors.Error berjenis validation dengan pesan per field, sehingga
+// handler HTML bisa merender ulang form dan client JSON menerima error yang
+// sama dari error handler.
+//
+// Aturan tag dipisah koma dan dijalankan berurutan:
+//
+//	trim       hapus spasi di awal/akhir (mengubah nilai field)
+//	upper      ubah ke huruf besar (mengubah nilai field)
+//	required   wajib diisi
+//	email      alamat email tunggal yang valid
+//	min=N      minimal N karakter
+//	max=N      maksimal N karakter
+//	oneof=A B  salah satu dari nilai yang dipisah spasi
+//	eqfield=F  sama dengan field F pada struct yang sama
+//
+// Field yang kosong dan tidak required melewati aturan lainnya. Nama field
+// pada pesan error diambil dari tag `form`. Tag `label` berisi key katalog
+// i18n untuk nama field pada pesan; pesan memakai bahasa request.
+package forms
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+
+	"ticketing-fiber/apperrors"
+	"ticketing-fiber/i18n"
+)
+
+// FormField key pada map error untuk kesalahan yang tidak terkait satu field
+const FormField = "__all__"
+
+// Bind membaca body request ke dst (pointer ke struct) lalu memvalidasinya.
+// Bahasa pesan diambil dari context request (lihat i18n.WithLang).
+func Bind(c *fiber.Ctx, dst interface{}) error {
+	lang := i18n.Lang(c.UserContext())
+	if err := c.BodyParser(dst); err != nil {
+		message := i18n.T(lang, "form.malformed")
+		return apperrors.Validation(message, map[string]string{FormField: message})
+	}
+	return Validate(lang, dst)
+}
+
+// Validate menjalankan aturan tag validate pada dst (pointer ke struct)
+func Validate(lang string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("forms: Validate requires a pointer to struct, got %T", dst))
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	fields := make(map[string]string)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || !sf.IsExported() {
+			continue
+		}
+		name := fieldName(sf)
+		if msg := validateField(lang, v, v.Field(i), sf, tag); msg != "" {
+			fields[name] = msg
+		}
+	}
+	return Invalid(lang, fields)
+}
+
+// Invalid membuat error validasi dari pesan per field; nil jika fields kosong.
+// Dipakai handler untuk menggabungkan hasil Bind dengan pengecekan lain
+// (misalnya username sudah dipakai).
+func Invalid(lang string, fields map[string]string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return apperrors.Validation(i18n.T(lang, "form.invalid"), fields)
+}
+
+// FieldErrors pesan per field dari error validasi; nil untuk error lain
+func FieldErrors(err error) map[string]string {
+	appErr, ok := apperrors.As(err)
+	if !ok || appErr.Kind != apperrors.KindValidation {
+		return nil
+	}
+	if appErr.Fields == nil {
+		return map[string]string{FormField: appErr.Message}
+	}
+	return appErr.Fields
+}
+
+func validateField(lang string, parent, field reflect.Value, sf reflect.StructField, tag string) string {
+	label := fieldName(sf)
+	if key := sf.Tag.Get("label"); key != "" {
+		label = i18n.T(lang, key)
+	}
+
+	rules := strings.Split(tag, ",")
+	// Modifier dijalankan dulu agar aturan lain melihat nilai akhir
+	for _, rule := range rules {
+		switch rule {
+		case "trim":
+			field.SetString(strings.TrimSpace(field.String()))
+		case "upper":
+			field.SetString(strings.ToUpper(field.String()))
+		}
+	}
+
+	if field.IsZero() {
+		for _, rule := range rules {
+			if rule == "required" {
+				return i18n.T(lang, "form.required", label)
+			}
+		}
+		return ""
+	}
+
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "trim", "upper", "required":
+		case "email":
+			addr, err := mail.ParseAddress(field.String())
+			if err != nil || addr.Address != field.String() {
+				return i18n.T(lang, "form.email", label)
+			}
+		case "min":
+			if utf8.RuneCountInString(field.String()) < ruleInt(sf, rule, arg) {
+				return i18n.T(lang, "form.min", label, arg)
+			}
+		case "max":
+			if utf8.RuneCountInString(field.String()) > ruleInt(sf, rule, arg) {
+				return i18n.T(lang, "form.max", label, arg)
+			}
+		case "oneof":
+			found := false
+			for _, allowed := range strings.Fields(arg) {
+				if field.String() == allowed {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return i18n.T(lang, "form.oneof", label)
+			}
+		case "eqfield":
+			other := parent.FieldByName(arg)
+			if !other.IsValid() {
+				panic(fmt.Sprintf("forms: field %s: eqfield refers to unknown field %q", sf.Name, arg))
+			}
+			if !reflect.DeepEqual(field.Interface(), other.Interface()) {
+				return i18n.T(lang, "form.eqfield", label)
+			}
+		default:
+			panic(fmt.Sprintf("forms: field %s: unknown rule %q", sf.Name, rule))
+		}
+	}
+	return ""
+}
+
+func ruleInt(sf reflect.StructField, rule, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		panic(fmt.Sprintf("forms: field %s: invalid rule %q", sf.Name, rule))
+	}
+	return n
+}
+
+// fieldName nama field pada map error: tag form, lalu tag json, lalu nama Go
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{"form", "json"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
@@ -0,0 +1,201 @@
+package forms
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"ticketing-fiber/apperrors"
+	"ticketing-fiber/i18n"
+)
+
+type signupForm struct {
+	Username  string `form:"username" json:"username" validate:"trim,required,min=3,max=8" label:"field.username"`
+	Email     string `form:"email" json:"email" validate:"trim,required,email"`
+	Priority  string `form:"priority" json:"priority" validate:"trim,upper,oneof=LOW HIGH"`
+	Password1 string `form:"password1" json:"password1" validate:"required"`
+	Password2 string `form:"password2" json:"password2" validate:"required,eqfield=Password1"`
+	Note      string `json:"note" validate:"max=5"`
+	Ignored   string `form:"ignored"`
+}
+
+func validForm() signupForm {
+	return signupForm{Username: "alice", Email: "alice@example.com", Password1: "secret", Password2: "secret"}
+}
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *signupForm)
+		want   map[string]string
+	}{
+		{"valid", nil, nil},
+		{"required", func(f *signupForm) { f.Username = "" }, map[string]string{"username": "Username is required"}},
+		{"trim before required", func(f *signupForm) { f.Email = "   " }, map[string]string{"email": "email is required"}},
+		{"min", func(f *signupForm) { f.Username = "al" }, map[string]string{"username": "Username must be at least 3 characters"}},
+		{"min counts runes", func(f *signupForm) { f.Username = "élè" }, nil},
+		{"max", func(f *signupForm) { f.Username = "alice-wonder" }, map[string]string{"username": "Username must be at most 8 characters"}},
+		{"trim before max", func(f *signupForm) { f.Username = "  alice   " }, nil},
+		{"email", func(f *signupForm) { f.Email = "not-an-email" }, map[string]string{"email": "email must be a valid email address"}},
+		{"email with display name", func(f *signupForm) { f.Email = "Alice <alice@example.com>" }, map[string]string{"email": "email must be a valid email address"}},
+		{"oneof after upper", func(f *signupForm) { f.Priority = "high" }, nil},
+		{"oneof", func(f *signupForm) { f.Priority = "urgent" }, map[string]string{"priority": "priority is not valid"}},
+		{"optional empty skips rules", func(f *signupForm) { f.Priority = "" }, nil},
+		{"eqfield", func(f *signupForm) { f.Password2 = "other" }, map[string]string{"password2": "password2 does not match"}},
+		{"json name without form tag", func(f *signupForm) { f.Note = "too long" }, map[string]string{"note": "note must be at most 5 characters"}},
+		{"field without validate tag", func(f *signupForm) { f.Ignored = strings.Repeat("x", 100) }, nil},
+		{"several fields", func(f *signupForm) {
+			f.Username = ""
+			f.Password2 = ""
+		}, map[string]string{"username": "Username is required", "password2": "password2 is required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := validForm()
+			if tt.modify != nil {
+				tt.modify(&f)
+			}
+			err := Validate(i18n.English, &f)
+			if got := FieldErrors(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FieldErrors = %v; want %v", got, tt.want)
+			}
+			if tt.want != nil {
+				appErr, ok := apperrors.As(err)
+				if !ok || appErr.Kind != apperrors.KindValidation || appErr.StatusCode() != http.StatusBadRequest {
+					t.Errorf("error = %#v; want validation error", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateModifiesValues(t *testing.T) {
+	f := validForm()
+	f.Username = "  alice "
+	f.Priority = " low "
+	if err := Validate(i18n.English, &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Username != "alice" || f.Priority != "LOW" {
+		t.Errorf("after Validate username=%q priority=%q; want trimmed and upper-cased", f.Username, f.Priority)
+	}
+}
+
+func TestValidateMessagesFollowLanguage(t *testing.T) {
+	f := validForm()
+	f.Username = ""
+	err := Validate(i18n.Indonesian, &f)
+	if got := FieldErrors(err)["username"]; got != i18n.T(i18n.Indonesian, "form.required", i18n.T(i18n.Indonesian, "field.username")) {
+		t.Errorf("Indonesian message = %q", got)
+	}
+	if err.Error() != i18n.T(i18n.Indonesian, "form.invalid") {
+		t.Errorf("error message = %q", err.Error())
+	}
+}
+
+func TestValidateRequiresPointerToStruct(t *testing.T) {
+	for _, dst := range []interface{}{validForm(), new(string), nil} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Validate(%T) did not panic", dst)
+				}
+			}()
+			Validate(i18n.English, dst)
+		}()
+	}
+}
+
+func TestValidatePanicsOnUnknownRule(t *testing.T) {
+	var f struct {
+		Name string `validate:"required,shiny"`
+	}
+	f.Name = "x"
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "shiny") {
+			t.Errorf("recover() = %v; want panic naming the rule", r)
+		}
+	}()
+	Validate(i18n.English, &f)
+}
+
+// bindApp endpoint yang mengikat body ke signupForm lalu mengembalikan
+// hasilnya sebagai JSON
+func bindApp() *fiber.App {
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		c.SetUserContext(i18n.WithLang(c.UserContext(), i18n.English))
+		var f signupForm
+		if err := Bind(c, &f); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(FieldErrors(err))
+		}
+		return c.JSON(f)
+	})
+	return app
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"form", fiber.MIMEApplicationForm, "username=+alice+&email=alice%40example.com&priority=low&password1=s&password2=s", http.StatusOK, `"username":"alice","email":"alice@example.com","priority":"LOW"`},
+		{"json", fiber.MIMEApplicationJSON, `{"username":"alice","email":"alice@example.com","password1":"s","password2":"s"}`, http.StatusOK, `"email":"alice@example.com"`},
+		{"invalid form", fiber.MIMEApplicationForm, "username=al&email=x", http.StatusBadRequest, `"username":"Username must be at least 3 characters"`},
+		{"malformed json", fiber.MIMEApplicationJSON, `{"username":`, http.StatusBadRequest, `"__all__":"The submitted data is malformed."`},
+		{"unsupported content type", fiber.MIMETextPlain, "username=alice", http.StatusBadRequest, `"__all__"`},
+	}
+	app := bindApp()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, tt.contentType)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			buf := new(strings.Builder)
+			if _, err := io.Copy(buf, resp.Body); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus || !strings.Contains(buf.String(), tt.wantBody) {
+				t.Errorf("status %d body %s; want %d containing %s", resp.StatusCode, buf, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestInvalidAndFieldErrors(t *testing.T) {
+	if err := Invalid(i18n.English, nil); err != nil {
+		t.Errorf("Invalid(nil) = %v; want nil", err)
+	}
+	if err := Invalid(i18n.English, map[string]string{}); err != nil {
+		t.Errorf("Invalid(empty) = %v; want nil", err)
+	}
+
+	fields := map[string]string{"username": "taken"}
+	err := Invalid(i18n.English, fields)
+	if got := FieldErrors(err); !reflect.DeepEqual(got, fields) {
+		t.Errorf("FieldErrors(Invalid) = %v", got)
+	}
+
+	// Error validasi tanpa field menjadi pesan form
+	if got := FieldErrors(apperrors.Validation("broken", nil)); !reflect.DeepEqual(got, map[string]string{FormField: "broken"}) {
+		t.Errorf("FieldErrors without fields = %v", got)
+	}
+	for _, other := range []error{nil, errors.New("boom"), apperrors.NotFound("missing")} {
+		if got := FieldErrors(other); got != nil {
+			t.Errorf("FieldErrors(%v) = %v; want nil", other, got)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const auditPageSize = 50
+
+type AdminHandler struct {
+	cfg   *config.Config
+	audit repository.AuditRepository
+}
+
+func NewAdminHandler(cfg *config.Config, audit repository.AuditRepository) *AdminHandler {
+	return &AdminHandler{cfg: cfg, audit: audit}
+}
+
+// auditFilter filter halaman audit log dari query string
+type auditFilter struct {
+	Action     string
+	Actor      string
+	TargetType string
+	TargetID   string
+	From       string
+	To         string
+
+	// location zona waktu viewer untuk menafsirkan tanggal From/To
+	location *time.Location
+}
+
+func parseAuditFilter(c *fiber.Ctx) auditFilter {
+	return auditFilter{
+		Action:     strings.TrimSpace(c.Query("action")),
+		Actor:      strings.TrimSpace(c.Query("actor")),
+		TargetType: strings.TrimSpace(c.Query("target_type")),
+		TargetID:   strings.TrimSpace(c.Query("target_id")),
+		From:       strings.TrimSpace(c.Query("from")),
+		To:         strings.TrimSpace(c.Query("to")),
+		location:   i18n.Location(c.UserContext()),
+	}
+}
+
+// query filter untuk repository; tanggal From/To ditafsirkan di zona waktu
+// viewer dan To mencakup seluruh hari tersebut
+func (f auditFilter) query() repository.AuditFilter {
+	query := repository.AuditFilter{
+		Action:     f.Action,
+		Actor:      f.Actor,
+		TargetType: f.TargetType,
+		TargetID:   f.TargetID,
+	}
+	if from, err := time.ParseInLocation("2006-01-02", f.From, f.location); err == nil {
+		query.From = from
+	}
+	if to, err := time.ParseInLocation("2006-01-02", f.To, f.location); err == nil {
+		query.To = to.AddDate(0, 0, 1)
+	}
+	return query
+}
+
+// queryString filter dalam bentuk query string untuk link export dan paginasi
+func (f auditFilter) queryString() string {
+	values := url.Values{}
+	add := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	add("action", f.Action)
+	add("actor", f.Actor)
+	add("target_type", f.TargetType)
+	add("target_id", f.TargetID)
+	add("from", f.From)
+	add("to", f.To)
+	return values.Encode()
+}
+
+// ShowAuditLog menampilkan audit log dengan filter dan paginasi
+func (h *AdminHandler) ShowAuditLog(c *fiber.Ctx) error {
+	filter := parseAuditFilter(c)
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+
+	events, total, err := h.audit.List(c.UserContext(), filter.query(), (page-1)*auditPageSize, auditPageSize)
+	if err != nil {
+		return err
+	}
+
+	totalPages := int((total + auditPageSize - 1) / auditPageSize)
+
+	return c.Render("admin/audit", addBaseData(c, fiber.Map{
+		"title":         tr(c, "page.audit.title"),
+		"page_title":    tr(c, "page.audit.heading"),
+		"page_subtitle": tr(c, "page.audit.subtitle"),
+		"nav_active":    "audit",
+		"template_name": "admin/audit",
+		"events":        events,
+		"total":         total,
+		"filter":        filter,
+		"filter_query":  filter.queryString(),
+		"actions":       models.AuditActions(),
+		"page":          page,
+		"total_pages":   totalPages,
+		"prev_page":     page - 1,
+		"next_page":     nextPage(page, totalPages),
+	}))
+}
+
+// ExportAuditLog mengunduh audit log (sesuai filter) dalam format CSV
+func (h *AdminHandler) ExportAuditLog(c *fiber.Ctx) error {
+	filter := parseAuditFilter(c)
+
+	filename := fmt.Sprintf("audit-log-%s.csv", time.Now().Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	w := csv.NewWriter(c)
+	w.Write([]string{"id", "created_at", "action", "actor_id", "actor_username", "ip_address", "user_agent", "target_type", "target_id", "changes"})
+
+	err := h.audit.Each(c.UserContext(), filter.query(), func(e *models.AuditEvent) error {
+		actorID := ""
+		if e.ActorID != nil {
+			actorID = strconv.FormatUint(uint64(*e.ActorID), 10)
+		}
+		return w.Write([]string{
+			strconv.FormatUint(uint64(e.ID), 10),
+			e.CreatedAt.UTC().Format(time.RFC3339),
+			e.Action,
+			actorID,
+			csvSafe(e.ActorUsername),
+			e.IPAddress,
+			csvSafe(e.UserAgent),
+			e.TargetType,
+			e.TargetID,
+			csvSafe(e.Changes),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// csvSafe mencegah formula injection saat CSV dibuka di spreadsheet
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func nextPage(page, totalPages int) int {
+	if page >= totalPages {
+		return 0
+	}
+	return page + 1
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// auditEvent data yang dicatat oleh recordAudit
+type auditEvent struct {
+	Action string
+	Actor  *models.User
+	// ActorName dipakai jika Actor nil, misalnya username pada login gagal
+	ActorName  string
+	TargetType string
+	TargetID   interface{}
+	Before     map[string]interface{}
+	After      map[string]interface{}
+}
+
+type auditChange struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// recordAudit menulis AuditEvent ke database; kegagalan hanya dicatat di log
+// agar tidak membatalkan aksi user
+func recordAudit(c *fiber.Ctx, audit repository.AuditRepository, e auditEvent) {
+	event := models.AuditEvent{
+		Action:        e.Action,
+		ActorUsername: e.ActorName,
+		IPAddress:     c.IP(),
+		UserAgent:     truncate(c.Get(fiber.HeaderUserAgent), 512),
+		TargetType:    e.TargetType,
+	}
+	if e.Actor != nil {
+		event.ActorID = &e.Actor.ID
+		event.ActorUsername = e.Actor.Username
+	}
+	if e.TargetID != nil {
+		event.TargetID = fmt.Sprint(e.TargetID)
+	}
+
+	if changes := diffFields(e.Before, e.After); len(changes) > 0 {
+		if data, err := json.Marshal(changes); err == nil {
+			event.Changes = string(data)
+		}
+	}
+
+	if err := audit.Create(c.UserContext(), &event); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to write audit event", "action", e.Action, "error", err)
+	}
+}
+
+// diffFields mengembalikan field yang nilainya berbeda antara before dan after
+func diffFields(before, after map[string]interface{}) map[string]auditChange {
+	changes := make(map[string]auditChange)
+	for key, newValue := range after {
+		oldValue, existed := before[key]
+		if existed && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes[key] = auditChange{Before: oldValue, After: newValue}
+	}
+	for key, oldValue := range before {
+		if _, ok := after[key]; !ok {
+			changes[key] = auditChange{Before: oldValue}
+		}
+	}
+	return changes
+}
+
+// userAuditFields field profil user yang dibandingkan di audit log
+func userAuditFields(u *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"username":   u.Username,
+		"email":      u.Email,
+		"first_name": u.FirstName,
+		"last_name":  u.LastName,
+	}
+}
+
+// notificationAuditFields pilihan notifikasi yang dicatat di audit log;
+// enabled berisi pasangan <jenis>_<saluran> yang dicentang
+func notificationAuditFields(p *models.NotificationPreference) map[string]interface{} {
+	enabled := []string{}
+	for _, kind := range models.NotificationKinds {
+		for _, channel := range models.NotificationChannels {
+			if p.Subscribed(kind, channel) {
+				enabled = append(enabled, preferenceField(kind, channel))
+			}
+		}
+	}
+	return map[string]interface{}{
+		"enabled":      enabled,
+		"webhook_url":  p.WebhookURL,
+		"daily_digest": p.DailyDigest,
+	}
+}
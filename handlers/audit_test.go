@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRecordAuditStoresChangedFields(t *testing.T) {
+	audit := repository.NewMemory().Audit()
+	actor := &models.User{ID: 7, Username: "alice"}
+
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		recordAudit(c, audit, auditEvent{
+			Action:     models.AuditProfileUpdate,
+			Actor:      actor,
+			TargetType: "user",
+			TargetID:   actor.ID,
+			Before:     map[string]interface{}{"email": "old@example.com", "first_name": "Alice"},
+			After:      map[string]interface{}{"email": "new@example.com", "first_name": "Alice"},
+		})
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	req := httptest.NewRequest(fiber.MethodPost, "/", nil)
+	req.Header.Set(fiber.HeaderUserAgent, "test-agent")
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	events, total, err := audit.List(context.Background(), repository.AuditFilter{}, 0, 10)
+	if err != nil || total != 1 {
+		t.Fatalf("List = %d events, %v; want 1", total, err)
+	}
+	event := events[0]
+	if event.ActorID == nil || *event.ActorID != 7 || event.ActorUsername != "alice" || event.TargetID != "7" || event.UserAgent != "test-agent" {
+		t.Errorf("event = %+v", event)
+	}
+
+	var changes map[string]auditChange
+	if err := json.Unmarshal([]byte(event.Changes), &changes); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes["email"].Before != "old@example.com" || changes["email"].After != "new@example.com" {
+		t.Errorf("changes = %+v; want only email", changes)
+	}
+}
+
+func TestExportAuditLogFiltersAndEscapes(t *testing.T) {
+	audit := repository.NewMemory().Audit()
+	ctx := context.Background()
+	day := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	for _, e := range []models.AuditEvent{
+		{Action: models.AuditLogin, ActorUsername: "alice", CreatedAt: day.Add(-24 * time.Hour)},
+		{Action: models.AuditLoginFailed, ActorUsername: "=HYPERLINK(\"x\")", CreatedAt: day},
+		{Action: models.AuditLogin, ActorUsername: "bob", CreatedAt: day.Add(time.Hour)},
+	} {
+		if err := audit.Create(ctx, &e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := fiber.New()
+	app.Get("/export", NewAdminHandler(&config.Config{}, audit).ExportAuditLog)
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/export?from=2026-03-10&to=2026-03-10", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Header + dua event pada 10 Maret, terbaru lebih dulu
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows: %v", len(rows), rows)
+	}
+	if rows[1][4] != "bob" || rows[2][4] != "'=HYPERLINK(\"x\")" {
+		t.Errorf("actor columns = %q, %q", rows[1][4], rows[2][4])
+	}
+}
@@ -2,38 +2,66 @@
 package handlers
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 
+	"ticketing-fiber/auth"
 	"ticketing-fiber/config"
+	"ticketing-fiber/forms"
 	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
 	"ticketing-fiber/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type AuthHandler struct {
-	cfg *config.Config
+	cfg            *config.Config
+	passwordPolicy utils.PasswordPolicy
+	hasher         *utils.PasswordHasher
+	authenticator  auth.Authenticator
+	oidc           *auth.OIDCProvider
+	users          repository.UserRepository
+	sessions       repository.SessionRepository
+	audit          repository.AuditRepository
 }
 
-func NewAuthHandler(cfg *config.Config) *AuthHandler {
-	return &AuthHandler{cfg: cfg}
+func NewAuthHandler(cfg *config.Config, authenticator auth.Authenticator, oidc *auth.OIDCProvider, users repository.UserRepository, sessions repository.SessionRepository, audit repository.AuditRepository) *AuthHandler {
+	hasher := utils.NewPasswordHasher(cfg)
+	return &AuthHandler{
+		cfg:            cfg,
+		users:          users,
+		sessions:       sessions,
+		audit:          audit,
+		passwordPolicy: hasher.Policy(utils.DefaultPasswordPolicy),
+		hasher:         hasher,
+		authenticator:  authenticator,
+		oidc:           oidc,
+	}
 }
 
 // ShowLogin menampilkan halaman login
 func (h *AuthHandler) ShowLogin(c *fiber.Ctx) error {
-	data := fiber.Map{
-		"title": "Login - Portal Ticketing",
-	}
+	data := fiber.Map{}
 
 	if next := c.Query("next"); next != "" {
 		data["query_next"] = next
 	}
 
-	if c.Query("registered") == "true" {
-		data["success"] = "Akun berhasil dibuat. Silakan login untuk melanjutkan."
-	}
+	return h.renderLogin(c, data)
+}
 
+func (h *AuthHandler) renderLogin(c *fiber.Ctx, data fiber.Map) error {
+	data["lang"] = langOf(c)
+	if h.oidc.Enabled() {
+		data["sso_enabled"] = true
+		data["sso_name"] = h.oidc.DisplayName()
+	}
+	if data["messages"] == nil {
+		data["messages"] = popFlashes(c)
+	}
 	return c.Render("tickets/login", data)
 }
 
@@ -44,28 +72,27 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	rememberMe := c.FormValue("remember_me")
 	nextParam := c.FormValue("next")
 
-	log.Printf("Login attempt for user: %s", username)
-
-	// Cari user berdasarkan username atau email
-	var user models.User
-	if err := config.DB.Preload("Groups").
-		Where("username = ? OR email = ?", username, username).
-		First(&user).Error; err != nil {
-		log.Printf("User not found: %s", username)
-		return c.Render("tickets/login", fiber.Map{
-			"error":            "Username atau password salah. Silakan coba lagi.",
-			"title":            "Login - Portal Ticketing",
-			"query_next":       nextParam,
-			"entered_username": username,
-		})
-	}
+	slog.InfoContext(c.UserContext(), "login attempt", "username", username)
 
-	// Cek password
-	if !utils.CheckPasswordHash(password, user.Password) {
-		log.Printf("Invalid password for user: %s", username)
-		return c.Render("tickets/login", fiber.Map{
-			"error":            "Username atau password salah. Silakan coba lagi.",
-			"title":            "Login - Portal Ticketing",
+	// Verifikasi kredensial lewat backend autentikasi (local/LDAP)
+	user, err := h.authenticator.Authenticate(c.UserContext(), username, password)
+	if err != nil {
+		slog.WarnContext(c.UserContext(), "login failed", "username", username, "error", err)
+		recordAudit(c, h.audit, auditEvent{
+			Action:     models.AuditLoginFailed,
+			ActorName:  username,
+			TargetType: "user",
+			After:      map[string]interface{}{"reason": err.Error()},
+		})
+		message := tr(c, "login.invalid_credentials")
+		switch {
+		case errors.Is(err, auth.ErrUserInactive):
+			message = tr(c, "login.inactive")
+		case errors.Is(err, auth.ErrIdentityConflict):
+			message = tr(c, "login.account_conflict")
+		}
+		return h.renderLogin(c, fiber.Map{
+			"error":            message,
 			"query_next":       nextParam,
 			"entered_username": username,
 		})
@@ -73,134 +100,165 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 
 	// Cek akses portal
 	if !user.HasPortalAccess() {
-		log.Printf("User %s doesn't have portal access", username)
-		return c.Render("tickets/login", fiber.Map{
-			"error":            "Akun ini tidak memiliki akses ke dashboard pengguna.",
-			"title":            "Login - Portal Ticketing",
+		slog.WarnContext(c.UserContext(), "user has no portal access", "username", username)
+		return h.renderLogin(c, fiber.Map{
+			"error":            tr(c, "login.no_portal_access"),
 			"query_next":       nextParam,
 			"entered_username": username,
 		})
 	}
 
+	if err := h.startUserSession(c, user, rememberMe != ""); err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+
+	slog.InfoContext(c.UserContext(), "login successful", "username", username)
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditLogin,
+		Actor:      user,
+		TargetType: "user",
+		TargetID:   user.ID,
+	})
+
+	// Check for next parameter
+	next := nextParam
+	if next == "" {
+		next = c.Query("next")
+	}
+
+	if next != "" {
+		return c.Redirect(next)
+	}
+
+	return c.Redirect("/dashboard")
+}
+
+// startUserSession memperbarui last login dan membuat session login baru untuk user
+func (h *AuthHandler) startUserSession(c *fiber.Ctx, user *models.User, rememberMe bool) error {
 	// Update last login
-	now := time.Now()
+	now := time.Now().UTC()
 	user.LastLogin = &now
-	config.DB.Save(&user)
+	if err := h.users.Save(c.UserContext(), user); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to update last login", "username", user.Username, "error", err)
+	}
 
 	// Create session
 	sess, err := config.Store.Get(c)
 	if err != nil {
-		log.Printf("Session error: %v", err)
-		return c.Status(fiber.StatusInternalServerError).SendString("Session error")
+		return err
+	}
+
+	// Ganti session ID setelah login untuk mencegah session fixation
+	if err := sess.Regenerate(); err != nil {
+		return err
 	}
 
 	sess.Set("user_id", user.ID)
 	sess.Set("username", user.Username)
+	sessionID := sess.ID()
 
 	// Set session expiry
-	if rememberMe == "" {
+	if !rememberMe {
 		sess.SetExpiry(0) // Session expires when browser closes
 	} else {
 		sess.SetExpiry(14 * 24 * time.Hour) // 2 weeks
 	}
 
 	if err := sess.Save(); err != nil {
-		log.Printf("Failed to save session: %v", err)
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to save session")
+		return err
 	}
 
-	log.Printf("Login successful for user: %s", username)
-
-	// Check for next parameter
-	next := nextParam
-	if next == "" {
-		next = c.Query("next")
-	}
-
-	if next != "" {
-		return c.Redirect(next)
-	}
-
-	return c.Redirect("/dashboard")
+	recordUserSession(c, h.sessions, user.ID, sessionID)
+	return nil
 }
 
 // ShowRegister menampilkan halaman registrasi
 func (h *AuthHandler) ShowRegister(c *fiber.Ctx) error {
 	return c.Render("tickets/register", fiber.Map{
-		"title": "Registrasi - Portal Ticketing",
+		"lang":                  langOf(c),
+		"password_requirements": h.passwordPolicy.Requirements(langOf(c)),
 	})
 }
 
 // Register proses registrasi user baru
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
-	username := c.FormValue("username")
-	email := c.FormValue("email")
-	password1 := c.FormValue("password1")
-	password2 := c.FormValue("password2")
-
-	// Validasi
-	errors := make(map[string]string)
-
-	if username == "" {
-		errors["username"] = "Username wajib diisi"
-	}
-	if email == "" {
-		errors["email"] = "Email wajib diisi"
-	}
-	if password1 == "" {
-		errors["password1"] = "Password wajib diisi"
+	var req RegisterRequest
+	fields := forms.FieldErrors(forms.Bind(c, &req))
+	if fields == nil {
+		fields = make(map[string]string)
 	}
-	if password1 != password2 {
-		errors["password2"] = "Password tidak cocok"
+
+	if _, ok := fields["password1"]; !ok {
+		if err := h.passwordPolicy.Validate(langOf(c), req.Password1, utils.PasswordUserInfo{
+			Username: req.Username,
+			Email:    req.Email,
+		}); err != nil {
+			fields["password1"] = err.Error()
+		}
 	}
 
 	// Cek username exists
-	var existingUser models.User
-	if err := config.DB.Where("username = ?", username).First(&existingUser).Error; err == nil {
-		errors["username"] = "Username sudah digunakan"
+	if _, ok := fields["username"]; !ok {
+		if taken, _ := h.users.UsernameTaken(c.UserContext(), req.Username, 0); taken {
+			fields["username"] = tr(c, "register.username_taken")
+		}
 	}
 
 	// Cek email exists
-	if err := config.DB.Where("email = ?", email).First(&existingUser).Error; err == nil {
-		errors["email"] = "Email sudah terdaftar"
+	if _, ok := fields["email"]; !ok {
+		if taken, _ := h.users.EmailTaken(c.UserContext(), req.Email, 0); taken {
+			fields["email"] = tr(c, "register.email_taken")
+		}
 	}
 
-	if len(errors) > 0 {
-		return c.Render("tickets/register", fiber.Map{
-			"errors":   errors,
-			"username": username,
-			"email":    email,
-			"title":    "Registrasi - Portal Ticketing",
+	if err := forms.Invalid(langOf(c), fields); err != nil {
+		return formError(c, err, func(fields map[string]string) error {
+			return c.Render("tickets/register", fiber.Map{
+				"errors":                fields,
+				"form":                  &req,
+				"lang":                  langOf(c),
+				"password_requirements": h.passwordPolicy.Requirements(langOf(c)),
+			})
 		})
 	}
 
 	// Hash password
-	hashedPassword, err := utils.HashPassword(password1)
+	hashedPassword, err := h.hasher.Hash(req.Password1)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to hash password")
+		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	// Create user
 	user := models.User{
-		Username: username,
-		Email:    email,
+		Username: req.Username,
+		Email:    req.Email,
 		Password: hashedPassword,
 		IsActive: true,
 	}
 
-	if err := config.DB.Create(&user).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to create user")
+	if err := h.users.Create(c.UserContext(), &user); err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
 	}
 
 	// Add to Portal Users group
-	var portalGroup models.Group
-	config.DB.FirstOrCreate(&portalGroup, models.Group{Name: "Portal Users"})
-	config.DB.Model(&user).Association("Groups").Append(&portalGroup)
+	if err := h.users.AddToGroup(c.UserContext(), &user, "Portal Users"); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to add user to Portal Users", "username", req.Username, "error", err)
+	}
 
-	log.Printf("New user registered: %s", username)
+	slog.InfoContext(c.UserContext(), "user registered", "username", req.Username)
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditRegister,
+		Actor:      &user,
+		TargetType: "user",
+		TargetID:   user.ID,
+		After:      userAuditFields(&user),
+	})
 
-	// Flash message (simplified, Anda bisa gunakan session untuk flash messages)
-	return c.Redirect("/login?registered=true")
+	if wantsJSON(c) {
+		return c.Status(fiber.StatusCreated).JSON(user)
+	}
+	setFlash(c, FlashSuccess, tr(c, "register.success"))
+	return c.Redirect("/login")
 }
 
 // Logout proses logout user
@@ -210,9 +268,22 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 		return c.Redirect("/login")
 	}
 
+	if err := h.sessions.DeleteBySessionID(c.UserContext(), sess.ID()); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to delete user session", "error", err)
+	}
+
+	if user, ok := c.Locals("user").(*models.User); ok {
+		recordAudit(c, h.audit, auditEvent{
+			Action:     models.AuditLogout,
+			Actor:      user,
+			TargetType: "user",
+			TargetID:   user.ID,
+		})
+	}
+
 	// Destroy session
 	if err := sess.Destroy(); err != nil {
-		log.Printf("Failed to destroy session: %v", err)
+		slog.ErrorContext(c.UserContext(), "failed to destroy session", "error", err)
 	}
 
 	return c.Redirect("/login")
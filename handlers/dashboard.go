@@ -4,64 +4,50 @@ package handlers
 import (
 	"ticketing-fiber/config"
 	"ticketing-fiber/models"
+	"ticketing-fiber/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type DashboardHandler struct {
-	cfg *config.Config
+	cfg     *config.Config
+	tickets *services.TicketService
 }
 
-func NewDashboardHandler(cfg *config.Config) *DashboardHandler {
-	return &DashboardHandler{cfg: cfg}
+func NewDashboardHandler(cfg *config.Config, tickets *services.TicketService) *DashboardHandler {
+	return &DashboardHandler{
+		cfg:     cfg,
+		tickets: tickets,
+	}
 }
 
 func (h *DashboardHandler) ShowDashboard(c *fiber.Ctx) error {
 	user := c.Locals("user").(*models.User)
-	activeTicketsCount := c.Locals("active_tickets_count")
 
-	var waitingCount, inProgressCount, closedCount, totalCount int64
+	stats, err := h.tickets.Stats(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
 
-	config.DB.Model(&models.Ticket{}).
-		Where("created_by_id = ? AND status = ?", user.ID, models.StatusWaiting).
-		Count(&waitingCount)
-
-	config.DB.Model(&models.Ticket{}).
-		Where("created_by_id = ? AND status = ?", user.ID, models.StatusInProgress).
-		Count(&inProgressCount)
-
-	config.DB.Model(&models.Ticket{}).
-		Where("created_by_id = ? AND status = ?", user.ID, models.StatusClosed).
-		Count(&closedCount)
-
-	config.DB.Model(&models.Ticket{}).
-		Where("created_by_id = ?", user.ID).
-		Count(&totalCount)
-
-	// PERBAIKAN: Gunakan slice of pointers
-	var recentTickets []*models.Ticket
-	config.DB.Preload("Department").
-		Preload("Replies").
-		Where("created_by_id = ?", user.ID).
-		Order("created_at DESC").
-		Limit(5).
-		Find(&recentTickets)
+	recentTickets, err := h.tickets.Recent(c.UserContext(), user.ID, 5)
+	if err != nil {
+		return err
+	}
 
 	return c.Render("tickets/dashboard", addBaseData(c, fiber.Map{
-		"title":                "Dashboard - Portal Ticketing",
-		"page_title":           "Dashboard",
-		"page_subtitle":        "Selamat datang kembali, " + user.GetFullName() + "!",
+		"title":                tr(c, "page.dashboard.title"),
+		"page_title":           tr(c, "page.dashboard.heading"),
+		"page_subtitle":        tr(c, "page.dashboard.subtitle", user.GetFullName()),
 		"nav_active":           "dashboard",
 		"template_name":        "tickets/dashboard",
 		"user":                 user,
-		"active_tickets_count": activeTicketsCount,
-		"waiting_tickets":      waitingCount,
-		"in_progress_tickets":  inProgressCount,
-		"closed_tickets":       closedCount,
-		"total_tickets":        totalCount,
+		"active_tickets_count": stats.Active(),
+		"waiting_tickets":      stats.Waiting,
+		"in_progress_tickets":  stats.InProgress,
+		"closed_tickets":       stats.Closed,
+		"total_tickets":        stats.Total,
 		"recent_tickets":       recentTickets,
 		"announcements":        []interface{}{},
 		"popular_articles":     []interface{}{},
-		"unread_count":         0,
 	}))
 }
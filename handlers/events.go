@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/models"
+	"ticketing-fiber/realtime"
+	"ticketing-fiber/services"
+)
+
+// keepaliveInterval jeda komentar SSE agar proxy tidak menutup stream yang
+// sedang sepi
+const keepaliveInterval = 20 * time.Second
+
+type EventsHandler struct {
+	cfg           *config.Config
+	events        *realtime.Hub
+	tickets       *services.TicketService
+	notifications *services.NotificationService
+}
+
+func NewEventsHandler(cfg *config.Config, events *realtime.Hub, tickets *services.TicketService, notifications *services.NotificationService) *EventsHandler {
+	return &EventsHandler{
+		cfg:           cfg,
+		events:        events,
+		tickets:       tickets,
+		notifications: notifications,
+	}
+}
+
+// replyPayload data event "reply": HTML balasan siap disisipkan ke halaman
+// detail tiket
+type replyPayload struct {
+	TicketID    uint   `json:"ticket_id"`
+	ReplyID     uint   `json:"reply_id"`
+	Own         bool   `json:"own"`
+	HTML        string `json:"html"`
+	UnreadCount int64  `json:"unread_count"`
+}
+
+// statusPayload data event "status"
+type statusPayload struct {
+	TicketID    uint   `json:"ticket_id"`
+	Status      string `json:"status"`
+	Label       string `json:"label"`
+	Class       string `json:"class"`
+	ActiveCount int64  `json:"active_count"`
+	UnreadCount int64  `json:"unread_count"`
+}
+
+// assignmentPayload data event "assignment"
+type assignmentPayload struct {
+	TicketID    uint   `json:"ticket_id"`
+	Department  string `json:"department"`
+	UnreadCount int64  `json:"unread_count"`
+}
+
+// Stream mengirim event tiket milik user sebagai Server-Sent Events beserta
+// jumlah notifikasi belum dibaca. Dengan ?ticket=<id> stream juga membawa
+// balasan tiket tersebut; aksesnya dicek sama seperti ShowTicketDetail
+// (hanya pemilik tiket). Stream ditutup setelah session-nya logout,
+// dicabut dari perangkat lain atau kedaluwarsa.
+func (h *EventsHandler) Stream(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	topics := []string{realtime.UserTopic(user.ID)}
+	if raw := c.Query("ticket"); raw != "" {
+		ticketID, err := strconv.Atoi(raw)
+		if err != nil {
+			return services.ErrTicketNotFound
+		}
+		ticket, err := h.tickets.GetForUser(c.UserContext(), uint(ticketID), user.ID)
+		if err != nil {
+			return err
+		}
+		topics = append(topics, realtime.TicketTopic(ticket.ID))
+	}
+
+	sess, err := config.Store.Get(c)
+	if err != nil {
+		return fmt.Errorf("get session: %w", err)
+	}
+	sessionID := sess.ID()
+
+	sub, err := h.events.Subscribe(topics...)
+	if err != nil {
+		return fmt.Errorf("subscribe realtime events: %w", err)
+	}
+
+	// fiber.Ctx tidak boleh dipakai lagi setelah handler selesai, jadi semua
+	// yang dibutuhkan stream diambil sekarang
+	ctx := context.WithoutCancel(c.UserContext())
+	lang, loc := langOf(c), i18n.Location(c.UserContext())
+	views := c.App().Config().Views
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// Nginx tidak boleh menahan event di buffer
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		keepalive := time.NewTicker(keepaliveInterval)
+		defer keepalive.Stop()
+		sessionCheck := time.NewTicker(h.cfg.RealtimeSessionCheck)
+		defer sessionCheck.Stop()
+
+		fmt.Fprint(w, "retry: 5000\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+		for {
+			select {
+			case event, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+				payload, ok := h.payload(ctx, event, user.ID, lang, loc, views)
+				if !ok {
+					continue
+				}
+				if err := writeEvent(w, string(event.Type), payload); err != nil {
+					return
+				}
+			case <-keepalive.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+				if err := w.Flush(); err != nil {
+					return
+				}
+			case <-sessionCheck.C:
+				if !sessionStored(ctx, sessionID) {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// payload data event untuk user userID dalam bahasa dan zona waktunya;
+// false jika event tidak perlu dikirim
+func (h *EventsHandler) payload(ctx context.Context, event realtime.Event, userID uint, lang string, loc *time.Location, views fiber.Views) (interface{}, bool) {
+	// Notifikasi event ini sudah dicatat sebelum dipublikasikan
+	unread, err := h.notifications.UnreadCount(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to count unread notifications", "error", err)
+	}
+
+	switch event.Type {
+	case realtime.EventReply:
+		var html bytes.Buffer
+		err := views.Render(&html, "partials/ticket_reply", fiber.Map{
+			"lang":  lang,
+			"tz":    loc,
+			"reply": event.Reply,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to render realtime reply", "reply_id", event.Reply.ID, "error", err)
+			return nil, false
+		}
+		return replyPayload{
+			TicketID:    event.TicketID,
+			ReplyID:     event.Reply.ID,
+			Own:         event.Reply.UserID == userID,
+			HTML:        html.String(),
+			UnreadCount: unread,
+		}, true
+
+	case realtime.EventStatus:
+		ticket := models.Ticket{Status: event.Status}
+		var active int64
+		if stats, err := h.tickets.Stats(ctx, userID); err == nil {
+			active = stats.Active()
+		}
+		return statusPayload{
+			TicketID:    event.TicketID,
+			Status:      string(event.Status),
+			Label:       ticket.GetStatusDisplay(lang),
+			Class:       ticket.GetStatusClass(),
+			ActiveCount: active,
+			UnreadCount: unread,
+		}, true
+
+	case realtime.EventAssignment:
+		department := event.Department
+		if department == "" {
+			department = i18n.T(lang, "ticket.department_none")
+		}
+		return assignmentPayload{
+			TicketID:    event.TicketID,
+			Department:  department,
+			UnreadCount: unread,
+		}, true
+	}
+	return nil, false
+}
+
+// writeEvent menulis satu event SSE dengan data JSON lalu flush; error
+// berarti client sudah memutus koneksi
+func writeEvent(w *bufio.Writer, name string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+	return w.Flush()
+}
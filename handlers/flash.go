@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+
+	"ticketing-fiber/config"
+)
+
+// FlashLevel tingkat pesan flash, dipakai sebagai class CSS alert-<level>
+type FlashLevel string
+
+const (
+	FlashSuccess FlashLevel = "success"
+	FlashInfo    FlashLevel = "info"
+	FlashWarning FlashLevel = "warning"
+	FlashError   FlashLevel = "error"
+)
+
+// FlashMessage pesan sekali tampil yang disimpan di session sampai halaman
+// berikutnya dirender
+type FlashMessage struct {
+	Level   FlashLevel `json:"level"`
+	Message string     `json:"message"`
+}
+
+func (m FlashMessage) String() string {
+	return m.Message
+}
+
+// flashSessionKey key session untuk antrean pesan flash. Disimpan sebagai
+// JSON agar tidak perlu mendaftarkan tipe ke encoder gob storage session.
+const flashSessionKey = "_flash"
+
+// setFlash menambahkan pesan flash ke session. Panggil setelah handler
+// selesai menyimpan session-nya sendiri, karena Store.Get membaca ulang
+// session dari storage dan Save terakhir yang menang.
+func setFlash(c *fiber.Ctx, level FlashLevel, message string) {
+	sess, err := config.Store.Get(c)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to load session for flash message", "error", err)
+		return
+	}
+
+	messages := decodeFlashes(sess.Get(flashSessionKey))
+	messages = append(messages, FlashMessage{Level: level, Message: message})
+	encoded, err := json.Marshal(messages)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to encode flash messages", "error", err)
+		return
+	}
+
+	sess.Set(flashSessionKey, string(encoded))
+	if err := sess.Save(); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to save flash message", "error", err)
+	}
+}
+
+// popFlashes mengambil lalu menghapus semua pesan flash dari session
+func popFlashes(c *fiber.Ctx) []FlashMessage {
+	sess, err := config.Store.Get(c)
+	if err != nil || sess.Fresh() {
+		return nil
+	}
+
+	messages := decodeFlashes(sess.Get(flashSessionKey))
+	if len(messages) == 0 {
+		return nil
+	}
+	sess.Delete(flashSessionKey)
+	if err := sess.Save(); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to clear flash messages", "error", err)
+	}
+	return messages
+}
+
+func decodeFlashes(value interface{}) []FlashMessage {
+	raw, ok := value.(string)
+	if !ok || raw == "" {
+		return nil
+	}
+	var messages []FlashMessage
+	if err := json.Unmarshal([]byte(raw), &messages); err != nil {
+		return nil
+	}
+	return messages
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"ticketing-fiber/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// flashApp endpoint untuk menulis flash dan membaca antreannya sebagai JSON
+func flashApp(messages ...FlashMessage) *fiber.App {
+	app := fiber.New()
+	app.Post("/set", func(c *fiber.Ctx) error {
+		for _, m := range messages {
+			setFlash(c, m.Level, m.Message)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Post("/corrupt", func(c *fiber.Ctx) error {
+		sess, err := config.Store.Get(c)
+		if err != nil {
+			return err
+		}
+		sess.Set(flashSessionKey, "{not json")
+		return sess.Save()
+	})
+	app.Get("/pop", func(c *fiber.Ctx) error {
+		return c.JSON(popFlashes(c))
+	})
+	return app
+}
+
+// flashRequest mengirim request dengan cookie session lalu menyimpan cookie
+// balasan
+func flashRequest(t *testing.T, app *fiber.App, cookies map[string]*http.Cookie, method, path string) []FlashMessage {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	for _, cookie := range resp.Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	if method != fiber.MethodGet {
+		return nil
+	}
+	var messages []FlashMessage
+	if err := json.NewDecoder(resp.Body).Decode(&messages); err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
+
+func TestFlashRoundTrip(t *testing.T) {
+	useMemorySessionStore(t)
+	want := []FlashMessage{
+		{Level: FlashSuccess, Message: "Profil berhasil diperbarui"},
+		{Level: FlashError, Message: `<script>alert("x")</script> é`},
+	}
+	app := flashApp(want...)
+	cookies := map[string]*http.Cookie{}
+
+	flashRequest(t, app, cookies, fiber.MethodPost, "/set")
+	if got := flashRequest(t, app, cookies, fiber.MethodGet, "/pop"); !reflect.DeepEqual(got, want) {
+		t.Errorf("first pop = %+v; want %+v", got, want)
+	}
+	// Pesan hanya tampil sekali
+	if got := flashRequest(t, app, cookies, fiber.MethodGet, "/pop"); len(got) != 0 {
+		t.Errorf("second pop = %+v; want none", got)
+	}
+}
+
+func TestFlashIgnoresMissingOrCorruptSession(t *testing.T) {
+	useMemorySessionStore(t)
+	app := flashApp()
+
+	// Tanpa session tidak ada pesan dan tidak ada session baru
+	cookies := map[string]*http.Cookie{}
+	if got := flashRequest(t, app, cookies, fiber.MethodGet, "/pop"); len(got) != 0 {
+		t.Errorf("pop without session = %+v", got)
+	}
+	if len(cookies) != 0 {
+		t.Errorf("pop without session set cookies %v", cookies)
+	}
+
+	flashRequest(t, app, cookies, fiber.MethodPost, "/corrupt")
+	if got := flashRequest(t, app, cookies, fiber.MethodGet, "/pop"); len(got) != 0 {
+		t.Errorf("pop with corrupt value = %+v", got)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// mailPinger transport email yang bisa dicek koneksinya (utils.SMTPSender)
+type mailPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// dbPinger koneksi database yang bisa dicek (*sql.DB)
+type dbPinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	cfg        *config.Config
+	db         dbPinger
+	mailSender utils.MailSender
+}
+
+func NewHealthHandler(cfg *config.Config, db dbPinger, mailSender utils.MailSender) *HealthHandler {
+	return &HealthHandler{cfg: cfg, db: db, mailSender: mailSender}
+}
+
+// Healthz liveness probe: selalu 200 selama proses bisa melayani request
+func (h *HealthHandler) Healthz(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// Readyz readiness probe: 503 jika database tidak bisa di-ping, atau server
+// SMTP tidak bisa dihubungi saat ReadyzCheckMail aktif
+func (h *HealthHandler) Readyz(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+	defer cancel()
+
+	checks := fiber.Map{}
+	ready := true
+
+	if err := h.db.PingContext(ctx); err != nil {
+		slog.WarnContext(ctx, "readiness: database check failed", "error", err)
+		checks["database"] = err.Error()
+		ready = false
+	} else {
+		checks["database"] = "ok"
+	}
+
+	if h.cfg.ReadyzCheckMail {
+		if pinger, ok := h.mailSender.(mailPinger); ok {
+			if err := pinger.Ping(ctx); err != nil {
+				slog.WarnContext(ctx, "readiness: mail check failed", "error", err)
+				checks["mail"] = err.Error()
+				ready = false
+			} else {
+				checks["mail"] = "ok"
+			}
+		}
+	}
+
+	if !ready {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "unavailable", "checks": checks})
+	}
+	return c.JSON(fiber.Map{"status": "ready", "checks": checks})
+}
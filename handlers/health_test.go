@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type pingFunc func(ctx context.Context) error
+
+func (f pingFunc) PingContext(ctx context.Context) error { return f(ctx) }
+
+// pingMailSender MailSender yang koneksinya bisa dicek
+type pingMailSender struct {
+	utils.MemoryMailSender
+	err error
+}
+
+func (s *pingMailSender) Ping(ctx context.Context) error { return s.err }
+
+func TestReadyzChecks(t *testing.T) {
+	down := errors.New("connection refused")
+	ok := pingFunc(func(context.Context) error { return nil })
+	tests := []struct {
+		name       string
+		db         dbPinger
+		mail       error
+		checkMail  bool
+		wantStatus int
+		wantBody   string
+	}{
+		{"ready", ok, nil, false, fiber.StatusOK, `"checks":{"database":"ok"}`},
+		{"database down", pingFunc(func(context.Context) error { return down }), nil, false, fiber.StatusServiceUnavailable, `"database":"connection refused"`},
+		{"mail not checked", ok, down, false, fiber.StatusOK, `"status":"ready"`},
+		{"mail down", ok, down, true, fiber.StatusServiceUnavailable, `"mail":"connection refused"`},
+		{"mail up", ok, nil, true, fiber.StatusOK, `"mail":"ok"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.ReadyzCheckMail = tt.checkMail
+			h := NewHealthHandler(cfg, tt.db, &pingMailSender{err: tt.mail})
+			app := fiber.New()
+			app.Get("/readyz", h.Readyz)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/readyz", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus || !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("status %d body %s; want %d containing %s", resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
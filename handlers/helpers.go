@@ -2,15 +2,31 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
+
+	"ticketing-fiber/i18n"
 )
 
+// langOf bahasa request yang ditentukan middleware.Locale
+func langOf(c *fiber.Ctx) string {
+	return i18n.Lang(c.UserContext())
+}
+
+// tr menerjemahkan key ke bahasa request
+func tr(c *fiber.Ctx, key string, args ...interface{}) string {
+	return i18n.T(langOf(c), key, args...)
+}
+
 func addBaseData(c *fiber.Ctx, data fiber.Map) fiber.Map {
 	if data == nil {
 		data = fiber.Map{}
 	}
 
+	lang := langOf(c)
+	data["lang"] = lang
+	data["tz"] = i18n.Location(c.UserContext())
+
 	if data["title"] == nil {
-		data["title"] = "Portal Ticketing"
+		data["title"] = i18n.T(lang, "app.name")
 	}
 
 	if user := c.Locals("user"); user != nil {
@@ -23,5 +39,17 @@ func addBaseData(c *fiber.Ctx, data fiber.Map) fiber.Map {
 		data["active_tickets_count"] = 0
 	}
 
+	if count := c.Locals("unread_count"); count != nil {
+		data["unread_count"] = count
+	} else if _, ok := data["unread_count"]; !ok {
+		data["unread_count"] = 0
+	}
+
+	if data["messages"] == nil {
+		if messages := popFlashes(c); len(messages) > 0 {
+			data["messages"] = messages
+		}
+	}
+
 	return data
 }
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/models"
+	"ticketing-fiber/services"
+)
+
+// notificationListLimit jumlah notifikasi maksimal di halaman notifikasi
+const notificationListLimit = 50
+
+type NotificationHandler struct {
+	cfg           *config.Config
+	notifications *services.NotificationService
+}
+
+func NewNotificationHandler(cfg *config.Config, notifications *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		cfg:           cfg,
+		notifications: notifications,
+	}
+}
+
+// notificationView notifikasi dalam respons JSON (dropdown di header)
+type notificationView struct {
+	ID        uint      `json:"id"`
+	TicketID  uint      `json:"ticket_id"`
+	Message   string    `json:"message"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+	TimeAgo   string    `json:"time_ago"`
+	URL       string    `json:"url"`
+}
+
+// ShowNotifications menampilkan notifikasi user; client JSON (dropdown)
+// boleh membatasi jumlahnya dengan ?limit=
+func (h *NotificationHandler) ShowNotifications(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	limit := notificationListLimit
+	if n := c.QueryInt("limit"); n > 0 && n < limit {
+		limit = n
+	}
+	notifications, err := h.notifications.List(c.UserContext(), user.ID, limit)
+	if err != nil {
+		return err
+	}
+
+	if wantsJSON(c) {
+		unread, err := h.notifications.UnreadCount(c.UserContext(), user.ID)
+		if err != nil {
+			return err
+		}
+		lang := langOf(c)
+		views := make([]notificationView, 0, len(notifications))
+		for _, n := range notifications {
+			views = append(views, notificationView{
+				ID:        n.ID,
+				TicketID:  n.TicketID,
+				Message:   n.Message(lang),
+				Read:      n.IsRead(),
+				CreatedAt: n.CreatedAt,
+				TimeAgo:   i18n.TimeSince(lang, n.CreatedAt),
+				URL:       fmt.Sprintf("/tiket/%d", n.TicketID),
+			})
+		}
+		return c.JSON(fiber.Map{"notifications": views, "unread_count": unread})
+	}
+
+	return c.Render("tickets/notifications", addBaseData(c, fiber.Map{
+		"title":         tr(c, "page.notifications.title"),
+		"page_title":    tr(c, "page.notifications.heading"),
+		"page_subtitle": tr(c, "page.notifications.subtitle"),
+		"template_name": "tickets/notifications",
+		"notifications": notifications,
+	}))
+}
+
+// ReadNotification menandai satu notifikasi sudah dibaca lalu membuka
+// tiketnya, atau kembali ke daftar notifikasi jika form mengirim stay=1
+func (h *NotificationHandler) ReadNotification(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return services.ErrNotificationNotFound
+	}
+	notification, err := h.notifications.MarkRead(c.UserContext(), user.ID, uint(id))
+	if err != nil {
+		return err
+	}
+
+	if wantsJSON(c) {
+		unread, err := h.notifications.UnreadCount(c.UserContext(), user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"unread_count": unread})
+	}
+	if c.FormValue("stay") == "1" {
+		return c.Redirect("/notifikasi")
+	}
+	return c.Redirect(fmt.Sprintf("/tiket/%d", notification.TicketID))
+}
+
+// ReadAllNotifications menandai semua notifikasi user sudah dibaca
+func (h *NotificationHandler) ReadAllNotifications(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	count, err := h.notifications.MarkAllRead(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(c.UserContext(), "notifications marked read", "count", count, "username", user.Username)
+
+	if wantsJSON(c) {
+		return c.JSON(fiber.Map{"unread_count": 0})
+	}
+	setFlash(c, FlashSuccess, tr(c, "notifications.all_marked_read"))
+	return c.Redirect("/notifikasi")
+}
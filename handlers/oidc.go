@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"ticketing-fiber/auth"
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	oidcStateKey    = "oidc_state"
+	oidcNonceKey    = "oidc_nonce"
+	oidcVerifierKey = "oidc_verifier"
+	oidcNextKey     = "oidc_next"
+)
+
+// StartOIDCLogin mengarahkan user ke identity provider untuk login SSO
+func (h *AuthHandler) StartOIDCLogin(c *fiber.Ctx) error {
+	if !h.oidc.Enabled() {
+		return c.Redirect("/login")
+	}
+
+	req, err := h.oidc.NewAuthRequest(c.UserContext())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "oidc login error", "error", err)
+		return h.renderLogin(c, fiber.Map{
+			"error": tr(c, "login.sso_unavailable"),
+		})
+	}
+
+	sess, err := config.Store.Get(c)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+
+	sess.Set(oidcStateKey, req.State)
+	sess.Set(oidcNonceKey, req.Nonce)
+	sess.Set(oidcVerifierKey, req.Verifier)
+	sess.Set(oidcNextKey, c.Query("next"))
+	if err := sess.Save(); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return c.Redirect(req.URL)
+}
+
+// OIDCCallback menerima authorization code dari identity provider,
+// memvalidasi ID token lalu membuat session login
+func (h *AuthHandler) OIDCCallback(c *fiber.Ctx) error {
+	if !h.oidc.Enabled() {
+		return c.Redirect("/login")
+	}
+
+	sess, err := config.Store.Get(c)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+
+	state, _ := sess.Get(oidcStateKey).(string)
+	nonce, _ := sess.Get(oidcNonceKey).(string)
+	verifier, _ := sess.Get(oidcVerifierKey).(string)
+	next, _ := sess.Get(oidcNextKey).(string)
+	for _, key := range []string{oidcStateKey, oidcNonceKey, oidcVerifierKey, oidcNextKey} {
+		sess.Delete(key)
+	}
+	sess.Save()
+
+	if errParam := c.Query("error"); errParam != "" {
+		slog.WarnContext(c.UserContext(), "oidc provider returned error", "error", errParam, "description", c.Query("error_description"))
+		return h.renderLogin(c, fiber.Map{
+			"error": tr(c, "login.sso_denied"),
+		})
+	}
+
+	if state == "" || c.Query("state") != state {
+		slog.WarnContext(c.UserContext(), "oidc state mismatch")
+		return h.renderLogin(c, fiber.Map{
+			"error": tr(c, "login.sso_state_invalid"),
+		})
+	}
+
+	identity, err := h.oidc.Exchange(c.UserContext(), c.Query("code"), verifier, nonce)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "oidc exchange error", "error", err)
+		return h.renderLogin(c, fiber.Map{
+			"error": tr(c, "login.sso_failed"),
+		})
+	}
+
+	user, err := h.oidc.ResolveUser(c.UserContext(), identity)
+	if err != nil {
+		slog.WarnContext(c.UserContext(), "oidc user resolution failed", "email", identity.Email, "error", err)
+		message := tr(c, "login.sso_failed_contact_admin")
+		switch {
+		case errors.Is(err, auth.ErrEmailNotVerified):
+			message = tr(c, "login.sso_email_unverified")
+		case errors.Is(err, auth.ErrUserNotProvisioned):
+			message = tr(c, "login.sso_not_provisioned")
+		case errors.Is(err, auth.ErrUserInactive):
+			message = tr(c, "login.sso_inactive")
+		}
+		return h.renderLogin(c, fiber.Map{"error": message})
+	}
+
+	if !user.HasPortalAccess() {
+		slog.WarnContext(c.UserContext(), "user has no portal access", "username", user.Username)
+		return h.renderLogin(c, fiber.Map{
+			"error": tr(c, "login.no_portal_access"),
+		})
+	}
+
+	if err := h.startUserSession(c, user, false); err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+
+	slog.InfoContext(c.UserContext(), "sso login successful", "username", user.Username)
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditLogin,
+		Actor:      user,
+		TargetType: "user",
+		TargetID:   user.ID,
+		After:      map[string]interface{}{"method": "oidc", "issuer": identity.Issuer},
+	})
+
+	// Hanya izinkan redirect ke path lokal
+	if strings.HasPrefix(next, "/") && !strings.HasPrefix(next, "//") {
+		return c.Redirect(next)
+	}
+	return c.Redirect("/dashboard")
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"ticketing-fiber/apperrors"
+	"ticketing-fiber/forms"
+)
+
+// Request struct untuk form HTML dan JSON API. Aturan validate dijalankan
+// oleh forms.Bind; pengecekan yang butuh database (username terpakai dan
+// sejenisnya) tetap dilakukan di handler.
+
+// CreateTicketRequest input pembuatan tiket
+type CreateTicketRequest struct {
+	Title        string `form:"title" json:"title" validate:"trim,required,max=255" label:"field.title"`
+	Description  string `form:"description" json:"description" validate:"trim,required,max=10000" label:"field.description"`
+	ReplyToEmail string `form:"reply_to_email" json:"reply_to_email" validate:"trim,required,email,max=255" label:"field.reply_to_email"`
+	Priority     string `form:"priority" json:"priority" validate:"trim,upper,oneof=LOW MEDIUM HIGH" label:"field.priority"`
+	DepartmentID uint   `form:"department" json:"department"`
+}
+
+// RegisterRequest input registrasi akun
+type RegisterRequest struct {
+	Username  string `form:"username" json:"username" validate:"trim,required,max=150" label:"field.username"`
+	Email     string `form:"email" json:"email" validate:"trim,required,email,max=191" label:"field.email"`
+	Password1 string `form:"password1" json:"password1" validate:"required" label:"field.password"`
+	Password2 string `form:"password2" json:"password2" validate:"eqfield=Password1" label:"field.password"`
+}
+
+// UpdateProfileRequest input perubahan profil
+type UpdateProfileRequest struct {
+	Username  string `form:"username" json:"username" validate:"trim,required,max=150" label:"field.username"`
+	Email     string `form:"email" json:"email" validate:"trim,required,email,max=191" label:"field.email"`
+	FirstName string `form:"first_name" json:"first_name" validate:"trim,max=150" label:"field.first_name"`
+	LastName  string `form:"last_name" json:"last_name" validate:"trim,max=150" label:"field.last_name"`
+	Language  string `form:"language" json:"language" validate:"trim,oneof=id en" label:"field.language"`
+	TimeZone  string `form:"time_zone" json:"time_zone" validate:"trim,max=64" label:"field.time_zone"`
+}
+
+// NotificationPreferencesRequest input pilihan notifikasi; centang per jenis
+// dan saluran dibaca terpisah dari field <jenis>_<saluran>
+type NotificationPreferencesRequest struct {
+	WebhookURL  string `form:"webhook_url" json:"webhook_url" validate:"trim,max=500" label:"field.webhook_url"`
+	DailyDigest bool   `form:"daily_digest" json:"daily_digest"`
+}
+
+// ChangePasswordRequest input penggantian password
+type ChangePasswordRequest struct {
+	OldPassword  string `form:"old_password" json:"old_password" validate:"required" label:"field.old_password"`
+	NewPassword1 string `form:"new_password1" json:"new_password1" validate:"required" label:"field.new_password"`
+	NewPassword2 string `form:"new_password2" json:"new_password2" validate:"required,eqfield=NewPassword1" label:"field.new_password_confirm"`
+}
+
+// wantsJSON true jika client lebih memilih respons JSON daripada HTML
+func wantsJSON(c *fiber.Ctx) bool {
+	return c.Accepts(fiber.MIMETextHTML, fiber.MIMEApplicationJSON) == fiber.MIMEApplicationJSON
+}
+
+// formError menangani err dari forms.Bind atau service: browser mendapat form
+// yang dirender ulang dengan pesan per field, sedangkan client JSON dan error
+// selain validasi diteruskan ke error handler aplikasi.
+func formError(c *fiber.Ctx, err error, render func(fields map[string]string) error) error {
+	fields := forms.FieldErrors(apperrors.Localize(err, langOf(c)))
+	if fields == nil || wantsJSON(c) {
+		return err
+	}
+	c.Status(fiber.StatusBadRequest)
+	return render(fields)
+}
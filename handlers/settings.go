@@ -1,196 +1,382 @@
 package handlers
 
 import (
-	"log"
-	"strings"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
 
 	"ticketing-fiber/config"
+	"ticketing-fiber/forms"
+	"ticketing-fiber/i18n"
 	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/services"
 	"ticketing-fiber/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type SettingsHandler struct {
-	cfg *config.Config
+	cfg            *config.Config
+	passwordPolicy utils.PasswordPolicy
+	hasher         *utils.PasswordHasher
+	users          repository.UserRepository
+	sessions       repository.SessionRepository
+	audit          repository.AuditRepository
+	notifications  *services.NotificationService
 }
 
-func NewSettingsHandler(cfg *config.Config) *SettingsHandler {
-	return &SettingsHandler{cfg: cfg}
+func NewSettingsHandler(cfg *config.Config, users repository.UserRepository, sessions repository.SessionRepository, audit repository.AuditRepository, notifications *services.NotificationService) *SettingsHandler {
+	hasher := utils.NewPasswordHasher(cfg)
+	return &SettingsHandler{
+		cfg:            cfg,
+		users:          users,
+		sessions:       sessions,
+		audit:          audit,
+		notifications:  notifications,
+		passwordPolicy: hasher.Policy(utils.DefaultPasswordPolicy),
+		hasher:         hasher,
+	}
 }
 
-// ShowSettings menampilkan halaman settings
-func (h *SettingsHandler) ShowSettings(c *fiber.Ctx) error {
-	user := c.Locals("user").(*models.User)
+// preferenceCell satu checkbox di tabel pilihan notifikasi
+type preferenceCell struct {
+	Name      string
+	Available bool
+	Checked   bool
+}
 
-	// Get flash messages from query params (simplified)
-	successMsg := c.Query("success")
-	errorMsg := c.Query("error")
+// preferenceRow satu jenis notifikasi di tabel pilihan notifikasi
+type preferenceRow struct {
+	Kind  string
+	Cells []preferenceCell
+}
 
-	data := fiber.Map{
-		"title": "Settings - Portal Ticketing",
-		"user":  user,
+func preferenceRows(preference *models.NotificationPreference) []preferenceRow {
+	rows := make([]preferenceRow, 0, len(models.NotificationKinds))
+	for _, kind := range models.NotificationKinds {
+		row := preferenceRow{Kind: string(kind)}
+		for _, channel := range models.NotificationChannels {
+			row.Cells = append(row.Cells, preferenceCell{
+				Name:      preferenceField(kind, channel),
+				Available: preference.Available(kind, channel),
+				Checked:   preference.Subscribed(kind, channel),
+			})
+		}
+		rows = append(rows, row)
 	}
+	return rows
+}
 
-	if successMsg != "" {
-		data["success"] = successMsg
-	}
+func preferenceField(kind models.NotificationKind, channel models.NotificationChannel) string {
+	return fmt.Sprintf("%s_%s", kind, channel)
+}
 
-	if errorMsg != "" {
-		data["error"] = errorMsg
-	}
+// ShowSettings menampilkan halaman settings
+func (h *SettingsHandler) ShowSettings(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
 
-	return h.renderSettingsPage(c, data)
+	return h.renderSettingsPage(c, fiber.Map{
+		"title": tr(c, "page.settings.title"),
+		"user":  user,
+	})
 }
 
 // UpdateProfile update profil user
 func (h *SettingsHandler) UpdateProfile(c *fiber.Ctx) error {
 	user := c.Locals("user").(*models.User)
 
-	username := strings.TrimSpace(c.FormValue("username"))
-	email := strings.TrimSpace(c.FormValue("email"))
-	firstName := strings.TrimSpace(c.FormValue("first_name"))
-	lastName := strings.TrimSpace(c.FormValue("last_name"))
-
-	// Validasi
-	errors := make(map[string]string)
-
-	if username == "" {
-		errors["username"] = "Username wajib diisi"
-	}
-	if email == "" {
-		errors["email"] = "Email wajib diisi"
+	var req UpdateProfileRequest
+	fields := forms.FieldErrors(forms.Bind(c, &req))
+	if fields == nil {
+		fields = make(map[string]string)
 	}
 
 	// Check username exists (exclude current user)
-	var existingUser models.User
-	if username != "" {
-		if err := config.DB.Where("username = ? AND id != ?", username, user.ID).First(&existingUser).Error; err == nil {
-			errors["username"] = "Username sudah digunakan"
+	if _, ok := fields["username"]; !ok {
+		if taken, _ := h.users.UsernameTaken(c.UserContext(), req.Username, user.ID); taken {
+			fields["username"] = tr(c, "register.username_taken")
 		}
 	}
 
 	// Check email exists (exclude current user)
-	if email != "" {
-		if err := config.DB.Where("email = ? AND id != ?", email, user.ID).First(&existingUser).Error; err == nil {
-			errors["email"] = "Email sudah terdaftar"
+	if _, ok := fields["email"]; !ok {
+		if taken, _ := h.users.EmailTaken(c.UserContext(), req.Email, user.ID); taken {
+			fields["email"] = tr(c, "register.email_taken")
 		}
 	}
 
-	if len(errors) > 0 {
-		return h.renderSettingsPage(c, fiber.Map{
-			"errors":        errors,
-			"form_username": username,
-			"form_email":    email,
-			"form_first":    firstName,
-			"form_last":     lastName,
-		})
+	if _, ok := fields["time_zone"]; !ok && req.TimeZone != "" && !i18n.IsTimeZoneSupported(req.TimeZone) {
+		fields["time_zone"] = tr(c, "settings.time_zone_invalid")
+	}
+
+	renderForm := func(fields map[string]string) error {
+		return h.renderSettingsPage(c, fiber.Map{"errors": fields, "form": &req})
+	}
+	if err := forms.Invalid(langOf(c), fields); err != nil {
+		return formError(c, err, renderForm)
 	}
 
 	// Update user
-	user.Username = username
-	user.Email = email
-	user.FirstName = firstName
-	user.LastName = lastName
-
-	if err := config.DB.Save(user).Error; err != nil {
-		log.Printf("Failed to update user: %v", err)
-		return h.renderSettingsPage(c, fiber.Map{
-			"errors": map[string]string{
-				"__all__": "Gagal memperbarui profil. Silakan coba lagi.",
-			},
-			"form_username": username,
-			"form_email":    email,
-			"form_first":    firstName,
-			"form_last":     lastName,
+	before := userAuditFields(user)
+	user.Username = req.Username
+	user.Email = req.Email
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+	user.Language = req.Language
+	user.TimeZone = req.TimeZone
+
+	if err := h.users.Save(c.UserContext(), user); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to update user", "error", err)
+		if wantsJSON(c) {
+			return err
+		}
+		return renderForm(map[string]string{
+			forms.FormField: tr(c, "settings.profile_update_failed"),
 		})
 	}
 
 	// Update session username
 	sess, _ := config.Store.Get(c)
-	sess.Set("username", username)
+	sess.Set("username", req.Username)
 	sess.Save()
 
-	log.Printf("Profile updated for user: %s", username)
-
-	return c.Redirect("/settings?success=Profil berhasil diperbarui")
+	slog.InfoContext(c.UserContext(), "profile updated", "username", req.Username)
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditProfileUpdate,
+		Actor:      user,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     before,
+		After:      userAuditFields(user),
+	})
+
+	if wantsJSON(c) {
+		return c.JSON(user)
+	}
+	// Pesan sukses memakai bahasa yang baru dipilih
+	lang := i18n.Resolve(user.Language, c.Get(fiber.HeaderAcceptLanguage))
+	setFlash(c, FlashSuccess, i18n.T(lang, "settings.profile_updated"))
+	return c.Redirect("/settings")
 }
 
-// ChangePassword mengubah password user
-func (h *SettingsHandler) ChangePassword(c *fiber.Ctx) error {
+// UpdateNotifications menyimpan pilihan notifikasi per jenis dan saluran
+func (h *SettingsHandler) UpdateNotifications(c *fiber.Ctx) error {
 	user := c.Locals("user").(*models.User)
 
-	oldPassword := c.FormValue("old_password")
-	newPassword1 := c.FormValue("new_password1")
-	newPassword2 := c.FormValue("new_password2")
+	var req NotificationPreferencesRequest
+	fields := forms.FieldErrors(forms.Bind(c, &req))
+	if fields == nil {
+		fields = make(map[string]string)
+	}
 
-	// Validasi
-	errors := make(map[string]string)
+	preference := &models.NotificationPreference{UserID: user.ID}
+	webhook := false
+	for _, kind := range models.NotificationKinds {
+		for _, channel := range models.NotificationChannels {
+			checked := c.FormValue(preferenceField(kind, channel)) == "1"
+			preference.Set(kind, channel, checked)
+			webhook = webhook || (checked && channel == models.ChannelWebhook)
+		}
+	}
+	preference.WebhookURL = req.WebhookURL
+	preference.DailyDigest = req.DailyDigest && h.cfg.DigestInterval > 0
 
-	if oldPassword == "" {
-		errors["old_password"] = "Password lama wajib diisi"
+	if _, ok := fields["webhook_url"]; !ok {
+		var urlErr error
+		if req.WebhookURL != "" {
+			urlErr = utils.ValidateWebhookURL(c.UserContext(), req.WebhookURL, h.cfg.WebhookAllowPrivate)
+		}
+		switch {
+		case errors.Is(urlErr, utils.ErrWebhookPrivate):
+			fields["webhook_url"] = tr(c, "settings.webhook_url_private")
+		case urlErr != nil:
+			fields["webhook_url"] = tr(c, "settings.webhook_url_invalid")
+		case req.WebhookURL == "" && webhook:
+			fields["webhook_url"] = tr(c, "settings.webhook_url_required")
+		}
 	}
-	if newPassword1 == "" {
-		errors["new_password1"] = "Password baru wajib diisi"
+
+	renderForm := func(fields map[string]string) error {
+		return h.renderSettingsPage(c, fiber.Map{"errors": fields, "notification_preference": preference})
 	}
-	if newPassword2 == "" {
-		errors["new_password2"] = "Konfirmasi password baru wajib diisi"
+	if err := forms.Invalid(langOf(c), fields); err != nil {
+		return formError(c, err, renderForm)
 	}
 
-	if len(errors) > 0 {
-		return h.renderSettingsPage(c, fiber.Map{
-			"errors": errors,
+	before, err := h.notifications.Preferences(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	if err := h.notifications.SavePreferences(c.UserContext(), preference); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to update notification preferences", "error", err)
+		return renderForm(map[string]string{
+			forms.FormField: tr(c, "settings.notifications_update_failed"),
 		})
 	}
 
+	slog.InfoContext(c.UserContext(), "notification preferences updated", "username", user.Username)
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditNotifications,
+		Actor:      user,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     notificationAuditFields(before),
+		After:      notificationAuditFields(preference),
+	})
+
+	setFlash(c, FlashSuccess, tr(c, "settings.notifications_updated"))
+	return c.Redirect("/settings")
+}
+
+// RevokeSession me-logout satu perangkat milik user
+func (h *SettingsHandler) RevokeSession(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Redirect("/settings")
+	}
+
+	record, err := h.sessions.FindForUser(c.UserContext(), uint(id), user.ID)
+	if err != nil {
+		setFlash(c, FlashError, tr(c, "settings.session_not_found"))
+		return c.Redirect("/settings")
+	}
+
+	// Session saat ini dibaca sebelum dicabut; setelah datanya dihapus
+	// store akan membuat ID baru
+	sess, err := config.Store.Get(c)
+	current := err == nil && sess.ID() == record.SessionID
+
+	if err := revokeUserSession(c.UserContext(), h.sessions, record); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to revoke session", "error", err)
+		setFlash(c, FlashError, tr(c, "settings.session_revoke_failed"))
+		return c.Redirect("/settings")
+	}
+
+	slog.InfoContext(c.UserContext(), "session revoked", "user_session_id", record.ID, "username", user.Username)
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditSessionRevoke,
+		Actor:      user,
+		TargetType: "user_session",
+		TargetID:   record.ID,
+		Before:     map[string]interface{}{"ip_address": record.IPAddress, "user_agent": record.UserAgent},
+	})
+
+	// Jika yang dicabut adalah session saat ini, arahkan ke login
+	if current {
+		sess.Destroy()
+		return c.Redirect("/login")
+	}
+
+	setFlash(c, FlashSuccess, tr(c, "settings.session_revoked"))
+	return c.Redirect("/settings")
+}
+
+// RevokeOtherSessions me-logout semua perangkat lain milik user
+func (h *SettingsHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	sess, err := config.Store.Get(c)
+	if err != nil {
+		return c.Redirect("/login")
+	}
+
+	count, err := revokeOtherSessions(c.UserContext(), h.sessions, user.ID, sess.ID())
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to revoke sessions", "error", err)
+		setFlash(c, FlashError, tr(c, "settings.other_sessions_revoke_failed"))
+		return c.Redirect("/settings")
+	}
+
+	slog.InfoContext(c.UserContext(), "other sessions revoked", "count", count, "username", user.Username)
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditSessionRevoke,
+		Actor:      user,
+		TargetType: "user",
+		TargetID:   user.ID,
+		After:      map[string]interface{}{"revoked_sessions": count},
+	})
+
+	setFlash(c, FlashSuccess, tr(c, "settings.other_sessions_revoked"))
+	return c.Redirect("/settings")
+}
+
+// ChangePassword mengubah password user
+func (h *SettingsHandler) ChangePassword(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
+	var req ChangePasswordRequest
+	fields := forms.FieldErrors(forms.Bind(c, &req))
+	if fields == nil {
+		fields = make(map[string]string)
+	}
+
 	// Check old password
-	if !utils.CheckPasswordHash(oldPassword, user.Password) {
-		return h.renderSettingsPage(c, fiber.Map{
-			"errors": map[string]string{
-				"old_password": "Password lama tidak sesuai",
-			},
-		})
+	if _, ok := fields["old_password"]; !ok {
+		if valid, _ := h.hasher.Verify(req.OldPassword, user.Password); !valid {
+			fields["old_password"] = tr(c, "settings.old_password_mismatch")
+		}
 	}
 
-	// Check new passwords match
-	if newPassword1 != newPassword2 {
-		return h.renderSettingsPage(c, fiber.Map{
-			"errors": map[string]string{
-				"new_password2": "Password baru tidak cocok",
-			},
-		})
+	// Validate new password against policy
+	if _, ok := fields["new_password1"]; !ok {
+		if err := h.passwordPolicy.Validate(langOf(c), req.NewPassword1, utils.PasswordUserInfo{
+			Username:  user.Username,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+		}); err != nil {
+			fields["new_password1"] = err.Error()
+		}
 	}
 
-	// Validate new password length
-	if len(newPassword1) < 8 {
-		return h.renderSettingsPage(c, fiber.Map{
-			"errors": map[string]string{
-				"new_password1": "Password minimal 8 karakter",
-			},
-		})
+	renderForm := func(fields map[string]string) error {
+		return h.renderSettingsPage(c, fiber.Map{"errors": fields})
+	}
+	if err := forms.Invalid(langOf(c), fields); err != nil {
+		return formError(c, err, renderForm)
 	}
 
 	// Hash new password
-	hashedPassword, err := utils.HashPassword(newPassword1)
+	hashedPassword, err := h.hasher.Hash(req.NewPassword1)
 	if err != nil {
-		log.Printf("Failed to hash password: %v", err)
-		return c.Redirect("/settings?error=Gagal mengubah password")
+		slog.ErrorContext(c.UserContext(), "failed to hash password", "error", err)
+		setFlash(c, FlashError, tr(c, "settings.password_change_failed"))
+		return c.Redirect("/settings")
 	}
 
 	// Update password
 	user.Password = hashedPassword
-	if err := config.DB.Save(user).Error; err != nil {
-		log.Printf("Failed to update password: %v", err)
-		return h.renderSettingsPage(c, fiber.Map{
-			"errors": map[string]string{
-				"__all__": "Gagal mengubah password. Silakan coba lagi.",
-			},
+	if err := h.users.Save(c.UserContext(), user); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to update password", "error", err)
+		return renderForm(map[string]string{
+			forms.FormField: tr(c, "settings.password_change_failed_retry"),
 		})
 	}
 
-	log.Printf("Password changed for user: %s", user.Username)
+	slog.InfoContext(c.UserContext(), "password changed", "username", user.Username)
+
+	// Logout semua perangkat lain setelah password diganti
+	revoked := 0
+	if sess, err := config.Store.Get(c); err == nil {
+		if revoked, err = revokeOtherSessions(c.UserContext(), h.sessions, user.ID, sess.ID()); err != nil {
+			slog.ErrorContext(c.UserContext(), "failed to revoke sessions after password change", "error", err)
+		}
+	}
+
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditPasswordChange,
+		Actor:      user,
+		TargetType: "user",
+		TargetID:   user.ID,
+		After:      map[string]interface{}{"revoked_sessions": revoked},
+	})
 
-	return c.Redirect("/settings?success=Password berhasil diubah")
+	setFlash(c, FlashSuccess, tr(c, "settings.password_changed"))
+	return c.Redirect("/settings")
 }
 
 func (h *SettingsHandler) renderSettingsPage(c *fiber.Ctx, data fiber.Map) error {
@@ -198,16 +384,39 @@ func (h *SettingsHandler) renderSettingsPage(c *fiber.Ctx, data fiber.Map) error
 		data = fiber.Map{}
 	}
 	if data["title"] == nil {
-		data["title"] = "Settings - Portal Ticketing"
+		data["title"] = tr(c, "page.settings.title")
 	}
 	if data["page_title"] == nil {
-		data["page_title"] = "Pengaturan Akun"
+		data["page_title"] = tr(c, "page.settings.heading")
 	}
 	if data["page_subtitle"] == nil {
-		data["page_subtitle"] = "Kelola informasi profil dan keamanan akun Anda"
+		data["page_subtitle"] = tr(c, "page.settings.subtitle")
 	}
 	if data["template_name"] == nil {
 		data["template_name"] = "tickets/settings"
 	}
+	data["password_requirements"] = h.passwordPolicy.Requirements(langOf(c))
+	data["languages"] = i18n.Supported()
+	data["time_zones"] = i18n.TimeZones()
+	data["default_time_zone"] = h.cfg.DefaultTimeZone
+	data["digest_available"] = h.cfg.DigestInterval > 0
+
+	if user, ok := c.Locals("user").(*models.User); ok {
+		if data["sessions"] == nil {
+			data["sessions"] = listUserSessions(c.UserContext(), h.sessions, user.ID)
+		}
+		if data["notification_preference"] == nil {
+			preference, err := h.notifications.Preferences(c.UserContext(), user.ID)
+			if err != nil {
+				return err
+			}
+			data["notification_preference"] = preference
+		}
+		data["notification_rows"] = preferenceRows(data["notification_preference"].(*models.NotificationPreference))
+		data["notification_channels"] = models.NotificationChannels
+	}
+	if sess, err := config.Store.Get(c); err == nil {
+		data["current_session_id"] = sess.ID()
+	}
 	return c.Render("tickets/settings", addBaseData(c, data))
 }
@@ -2,139 +2,179 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strconv"
-	"time"
 
 	"ticketing-fiber/config"
+	"ticketing-fiber/forms"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/metrics"
 	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/services"
 	"ticketing-fiber/utils"
+	"ticketing-fiber/worker"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type TicketHandler struct {
-	cfg          *config.Config
-	emailService *utils.EmailService
+	cfg           *config.Config
+	emailService  *utils.EmailService
+	tickets       *services.TicketService
+	notifications *services.NotificationService
+	audit         repository.AuditRepository
+	workers       *worker.Group
 }
 
-func NewTicketHandler(cfg *config.Config, emailService *utils.EmailService) *TicketHandler {
+func NewTicketHandler(cfg *config.Config, emailService *utils.EmailService, tickets *services.TicketService, notifications *services.NotificationService, audit repository.AuditRepository, workers *worker.Group) *TicketHandler {
 	return &TicketHandler{
-		cfg:          cfg,
-		emailService: emailService,
+		cfg:           cfg,
+		emailService:  emailService,
+		tickets:       tickets,
+		notifications: notifications,
+		audit:         audit,
+		workers:       workers,
 	}
 }
 
 // ShowCreateTicket menampilkan form create ticket
 func (h *TicketHandler) ShowCreateTicket(c *fiber.Ctx) error {
-	user := c.Locals("user").(*models.User)
+	return h.renderCreateTicket(c, fiber.Map{})
+}
 
-	var departmentCount int64
-	config.DB.Model(&models.Department{}).Count(&departmentCount)
-	if departmentCount == 0 {
+func (h *TicketHandler) renderCreateTicket(c *fiber.Ctx, data fiber.Map) error {
+	departments, err := h.tickets.Departments(c.UserContext())
+	if err != nil {
+		return err
+	}
+	if len(departments) == 0 {
 		return c.Render("tickets/setup_error", fiber.Map{
-			"title": "Error Konfigurasi",
+			"title": tr(c, "page.setup_error.title"),
+			"lang":  langOf(c),
 		})
 	}
 
-	var departments []models.Department
-	config.DB.Find(&departments)
-
-	return c.Render("tickets/create_ticket", addBaseData(c, fiber.Map{
-		"title":         "Kirim Tiket Baru - Portal Ticketing",
-		"page_title":    "Kirim Tiket",
-		"page_subtitle": "Sampaikan kendala atau pertanyaan Anda kepada tim support kami",
-		"nav_active":    "create",
-		"template_name": "tickets/create_ticket",
-		"departments":   departments,
-		"user":          user,
-	}))
+	data["title"] = tr(c, "page.create_ticket.title")
+	data["page_title"] = tr(c, "page.create_ticket.heading")
+	data["page_subtitle"] = tr(c, "page.create_ticket.subtitle")
+	data["nav_active"] = "create"
+	data["template_name"] = "tickets/create_ticket"
+	data["departments"] = departments
+	return c.Render("tickets/create_ticket", addBaseData(c, data))
 }
 
 // CreateTicket proses pembuatan ticket baru
 func (h *TicketHandler) CreateTicket(c *fiber.Ctx) error {
 	user := c.Locals("user").(*models.User)
 
-	title := c.FormValue("title")
-	description := c.FormValue("description")
-	replyToEmail := c.FormValue("reply_to_email")
-	priority := c.FormValue("priority")
-	departmentIDStr := c.FormValue("department")
-
-	if title == "" || description == "" || replyToEmail == "" {
-		return c.Status(fiber.StatusBadRequest).SendString("Semua field wajib diisi")
+	var req CreateTicketRequest
+	renderForm := func(fields map[string]string) error {
+		return h.renderCreateTicket(c, fiber.Map{"form": &req, "errors": fields})
+	}
+	if err := forms.Bind(c, &req); err != nil {
+		return formError(c, err, renderForm)
 	}
 
 	var departmentID *uint
-	if departmentIDStr != "" {
-		id, err := strconv.ParseUint(departmentIDStr, 10, 32)
-		if err == nil {
-			uid := uint(id)
-			departmentID = &uid
-		}
+	if req.DepartmentID != 0 {
+		departmentID = &req.DepartmentID
 	}
 
-	ticket := models.Ticket{
-		Title:        title,
-		Description:  description,
-		ReplyToEmail: replyToEmail,
-		Priority:     models.TicketPriority(priority),
-		Status:       models.StatusWaiting,
-		CreatedByID:  user.ID,
+	ticket, err := h.tickets.Create(c.UserContext(), user, services.CreateTicketInput{
+		Title:        req.Title,
+		Description:  req.Description,
+		ReplyToEmail: req.ReplyToEmail,
+		Priority:     req.Priority,
 		DepartmentID: departmentID,
+	})
+	if err != nil {
+		return formError(c, err, renderForm)
 	}
 
-	if err := config.DB.Create(&ticket).Error; err != nil {
-		log.Printf("Failed to create ticket: %v", err)
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to create ticket")
-	}
-
-	config.DB.Preload("Department").First(&ticket, ticket.ID)
-
-	departmentName := "Tidak Ditentukan"
+	lang, loc := langOf(c), i18n.Location(c.UserContext())
+	departmentName := i18n.T(lang, "ticket.department_none")
 	if ticket.Department != nil {
 		departmentName = ticket.Department.Name
 	}
 
+	preference := h.notificationPreference(c, user.ID)
+	ticket.CreatedBy = *user
+	h.notifications.Deliver(c.UserContext(), preference, &models.Notification{
+		UserID:    user.ID,
+		TicketID:  ticket.ID,
+		Kind:      models.NotificationCreated,
+		CreatedAt: ticket.CreatedAt,
+	}, ticket, nil)
+
 	// Send confirmation email (Async)
-	println("masuk ga")
-	go func() {
-		err := h.emailService.SendTicketConfirmation(
-			replyToEmail,
-			user.GetFullName(),
-			ticket.Title,
-			ticket.ID,
-			departmentName,
-			ticket.GetPriorityDisplay(),
-			ticket.GetStatusDisplay(),
-			ticket.Description,
-		)
-		if err != nil {
-			log.Printf("Failed to send confirmation email: %v", err)
-		}
-	}()
+	if preference.Enabled(models.NotificationCreated, models.ChannelEmail) {
+		h.workers.Go(c.UserContext(), "ticket-confirmation-email", func(ctx context.Context) error {
+			err := h.emailService.SendTicketConfirmation(
+				ctx,
+				lang,
+				loc,
+				ticket.ReplyToEmail,
+				user.GetFullName(),
+				ticket.Title,
+				ticket.ID,
+				ticket.CreatedAt,
+				departmentName,
+				ticket.GetPriorityDisplay(lang),
+				ticket.GetStatusDisplay(lang),
+				ticket.Description,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to send confirmation email: %w", err)
+			}
+			return nil
+		})
+	}
 
-	log.Printf("Ticket #%d created by user %s", ticket.ID, user.Username)
+	slog.InfoContext(c.UserContext(), "ticket created", "ticket_id", ticket.ID, "username", user.Username)
+	metrics.TicketsCreated.Inc()
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditTicketCreate,
+		Actor:      user,
+		TargetType: "ticket",
+		TargetID:   ticket.ID,
+		After: map[string]interface{}{
+			"title":          ticket.Title,
+			"status":         ticket.Status,
+			"priority":       ticket.Priority,
+			"department_id":  ticket.DepartmentID,
+			"reply_to_email": ticket.ReplyToEmail,
+		},
+	})
 
+	if wantsJSON(c) {
+		return c.Status(fiber.StatusCreated).JSON(ticket)
+	}
 	return c.Redirect(fmt.Sprintf("/tiket/sukses/%d", ticket.ID))
 }
 
 // ShowTicketSuccess menampilkan halaman sukses
 func (h *TicketHandler) ShowTicketSuccess(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.User)
+
 	ticketID, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
 		return c.Redirect("/dashboard")
 	}
 
-	var ticket models.Ticket
-	if err := config.DB.First(&ticket, ticketID).Error; err != nil {
-		return c.Redirect("/dashboard")
+	// Sama seperti detail tiket: hanya pemilik yang boleh melihat
+	ticket, err := h.tickets.GetForUser(c.UserContext(), uint(ticketID), user.ID)
+	if err != nil {
+		return err
 	}
 
 	return c.Render("tickets/ticket_success", fiber.Map{
-		"title":  "Tiket Berhasil Dibuat",
-		"ticket": &ticket, 
+		"title":  tr(c, "page.ticket_success.title"),
+		"lang":   langOf(c),
+		"ticket": ticket, 
 	})
 }
 
@@ -146,47 +186,32 @@ func (h *TicketHandler) ShowMyTickets(c *fiber.Ctx) error {
 	statusFilter := c.Query("status", "all")
 	priorityFilter := c.Query("priority", "all")
 
-	query := config.DB.Preload("Department").
-		Preload("Replies").
-		Where("created_by_id = ?", user.ID)
-
-	if searchQuery != "" {
-		if ticketID, err := strconv.Atoi(searchQuery); err == nil {
-			query = query.Where("id = ? OR title LIKE ? OR description LIKE ?",
-				ticketID,
-				"%"+searchQuery+"%",
-				"%"+searchQuery+"%")
-		} else {
-			query = query.Where("title LIKE ? OR description LIKE ?",
-				"%"+searchQuery+"%",
-				"%"+searchQuery+"%")
-		}
-	}
+	filter := repository.TicketFilter{Search: searchQuery}
 
 	if statusFilter != "all" {
-		var status models.TicketStatus
 		switch statusFilter {
 		case "open":
-			status = models.StatusWaiting
+			filter.Status = models.StatusWaiting
 		case "in_progress":
-			status = models.StatusInProgress
+			filter.Status = models.StatusInProgress
 		case "closed":
-			status = models.StatusClosed
+			filter.Status = models.StatusClosed
 		}
-		query = query.Where("status = ?", status)
 	}
 
 	if priorityFilter != "all" {
-		query = query.Where("priority = ?", priorityFilter)
+		filter.Priority = models.TicketPriority(priorityFilter)
+	}
+
+	tickets, err := h.tickets.ListForUser(c.UserContext(), user.ID, filter)
+	if err != nil {
+		return err
 	}
-	// PERBAIKAN: Gunakan slice of pointers ([]*models.Ticket)
-	var tickets []*models.Ticket
-	query.Order("created_at DESC").Find(&tickets)
 
 	return c.Render("tickets/my_tickets", addBaseData(c, fiber.Map{
-		"title":           "Tiket Saya - Portal Ticketing",
-		"page_title":      "Tiket Saya",
-		"page_subtitle":   "Kelola semua tiket support Anda",
+		"title":           tr(c, "page.my_tickets.title"),
+		"page_title":      tr(c, "page.my_tickets.heading"),
+		"page_subtitle":   tr(c, "page.my_tickets.subtitle"),
 		"nav_active":      "tickets",
 		"template_name":   "tickets/my_tickets",
 		"tickets":         tickets,
@@ -205,27 +230,23 @@ func (h *TicketHandler) ShowTicketDetail(c *fiber.Ctx) error {
 		return c.Redirect("/tiket")
 	}
 
-	var ticket models.Ticket
-	if err := config.DB.Preload("CreatedBy").
-		Preload("Department").
-		Preload("Replies.User").
-		Where("id = ? AND created_by_id = ?", ticketID, user.ID).
-		First(&ticket).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).SendString("Ticket not found")
+	ticket, err := h.tickets.GetForUser(c.UserContext(), uint(ticketID), user.ID)
+	if err != nil {
+		return err
 	}
 
 	return c.Render("tickets/ticket_detail", addBaseData(c, fiber.Map{
-		"title":         fmt.Sprintf("Tiket #%d - %s", ticket.ID, ticket.Title),
-		"page_title":    fmt.Sprintf("Detail Tiket #%d", ticket.ID),
+		"title":         tr(c, "page.ticket_detail.title", ticket.ID, ticket.Title),
+		"page_title":    tr(c, "page.ticket_detail.heading", ticket.ID),
 		"page_subtitle": ticket.Title,
 		"nav_active":    "tickets",
 		"template_name": "tickets/ticket_detail",
-		"ticket":        &ticket, // PERBAIKAN: Kirim sebagai pointer
+		"ticket":        ticket, // PERBAIKAN: Kirim sebagai pointer
 		"replies":       ticket.Replies,
 	}))
 }
 
-// AddReply menambahkan reply ke tiket dan mengirim email
+// AddReply menambahkan reply pemilik tiket
 func (h *TicketHandler) AddReply(c *fiber.Ctx) error {
 	user := c.Locals("user").(*models.User)
 
@@ -234,53 +255,38 @@ func (h *TicketHandler) AddReply(c *fiber.Ctx) error {
 		return c.Redirect("/tiket")
 	}
 
-	message := c.FormValue("message")
-	if message == "" {
+	ticket, reply, err := h.tickets.AddReply(c.UserContext(), user, uint(ticketID), c.FormValue("message"))
+	switch {
+	case errors.Is(err, services.ErrEmptyReply):
 		return c.Redirect(fmt.Sprintf("/tiket/%d", ticketID))
-	}
-
-	var ticket models.Ticket
-	if err := config.DB.Preload("CreatedBy").
-		Where("id = ? AND created_by_id = ?", ticketID, user.ID).
-		First(&ticket).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).SendString("Ticket not found")
-	}
-
-	reply := models.TicketReply{
-		TicketID: ticket.ID,
-		UserID:   user.ID,
-		Message:  message,
-	}
-
-	if err := config.DB.Create(&reply).Error; err != nil {
-		log.Printf("Failed to create reply: %v", err)
+	case errors.Is(err, services.ErrTicketNotFound):
+		return err
+	case err != nil:
+		slog.ErrorContext(c.UserContext(), "failed to create reply", "ticket_id", ticketID, "error", err)
 		return c.Redirect(fmt.Sprintf("/tiket/%d", ticketID))
 	}
 
-	config.DB.Model(&ticket).Update("updated_at", time.Now())
-
-	log.Printf("Reply added to ticket #%d by user %s", ticketID, user.Username)
-	if reply.UserID != ticket.CreatedByID {
-		targetEmail := ticket.ReplyToEmail
-		if targetEmail == "" {
-			targetEmail = ticket.CreatedBy.Email
-		}
+	slog.InfoContext(c.UserContext(), "reply added", "ticket_id", ticketID, "username", user.Username)
+	metrics.RepliesAdded.Inc()
+	recordAudit(c, h.audit, auditEvent{
+		Action:     models.AuditTicketReply,
+		Actor:      user,
+		TargetType: "ticket",
+		TargetID:   ticket.ID,
+		After:      map[string]interface{}{"reply_id": reply.ID},
+	})
+	// Email dan webhook balasan staff dikirim NotificationService saat
+	// TicketWatcher mencatat balasannya
+	return c.Redirect(fmt.Sprintf("/tiket/%d", ticketID))
+}
 
-		go func() {
-			err := h.emailService.SendTicketReply(
-				targetEmail,
-				ticket.CreatedBy.GetFullName(),
-				ticket.Title,
-				ticket.ID,
-				ticket.GetStatusDisplay(),
-				reply.Message,
-				user.GetFullName(),
-			)
-			if err != nil {
-				log.Printf("Failed to send reply email notification: %v", err)
-			}
-		}()
+// notificationPreference pilihan notifikasi userID; pilihan bawaan jika
+// gagal dibaca agar email tetap terkirim
+func (h *TicketHandler) notificationPreference(c *fiber.Ctx, userID uint) *models.NotificationPreference {
+	preference, err := h.notifications.Preferences(c.UserContext(), userID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to load notification preferences", "user_id", userID, "error", err)
+		return models.DefaultNotificationPreference(userID)
 	}
-
-	return c.Redirect(fmt.Sprintf("/tiket/%d", ticketID))
+	return preference
 }
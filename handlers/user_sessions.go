@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// recordUserSession mencatat metadata perangkat untuk session yang baru disimpan
+func recordUserSession(c *fiber.Ctx, sessions repository.SessionRepository, userID uint, sessionID string) {
+	now := time.Now().UTC()
+	userSession := models.UserSession{
+		UserID:     userID,
+		SessionID:  sessionID,
+		IPAddress:  c.IP(),
+		UserAgent:  truncate(c.Get(fiber.HeaderUserAgent), 512),
+		LastSeenAt: now,
+	}
+	if err := sessions.Create(c.UserContext(), &userSession); err != nil {
+		slog.ErrorContext(c.UserContext(), "failed to record user session", "error", err)
+	}
+}
+
+// listUserSessions mengambil session aktif milik user dan membersihkan
+// catatan yang session-nya sudah tidak ada di storage (logout/kedaluwarsa)
+func listUserSessions(ctx context.Context, sessions repository.SessionRepository, userID uint) []models.UserSession {
+	records, err := sessions.ListForUser(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list user sessions", "error", err)
+	}
+
+	active := make([]models.UserSession, 0, len(records))
+	for _, record := range records {
+		data, err := config.Store.Storage.Get(record.SessionID)
+		if err == nil && data == nil {
+			sessions.Delete(ctx, record.ID)
+			continue
+		}
+		active = append(active, record)
+	}
+	return active
+}
+
+// sessionStored false jika session sudah tidak ada di storage (logout,
+// dicabut atau kedaluwarsa). Error storage dianggap masih ada agar gangguan
+// sementara tidak memutus stream.
+func sessionStored(ctx context.Context, sessionID string) bool {
+	data, err := config.Store.Storage.Get(sessionID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check session storage", "error", err)
+		return true
+	}
+	return data != nil
+}
+
+// revokeUserSession menghapus session dari storage sehingga perangkat tersebut logout
+func revokeUserSession(ctx context.Context, sessions repository.SessionRepository, record *models.UserSession) error {
+	if err := config.Store.Delete(record.SessionID); err != nil {
+		return err
+	}
+	return sessions.Delete(ctx, record.ID)
+}
+
+// revokeOtherSessions me-logout semua perangkat user kecuali session saat ini
+func revokeOtherSessions(ctx context.Context, sessions repository.SessionRepository, userID uint, currentSessionID string) (int, error) {
+	records, err := sessions.ListForUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for i := range records {
+		if records[i].SessionID == currentSessionID {
+			continue
+		}
+		if err := revokeUserSession(ctx, sessions, &records[i]); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+
+	"github.com/gofiber/fiber/v2/middleware/session"
+)
+
+// useMemorySessionStore mengganti config.Store dengan memory storage selama test
+func useMemorySessionStore(t *testing.T) {
+	t.Helper()
+	previous := config.Store
+	config.Store = session.New()
+	t.Cleanup(func() { config.Store = previous })
+}
+
+func storeSession(t *testing.T, sessions repository.SessionRepository, userID uint, sessionID string, lastSeen time.Time) *models.UserSession {
+	t.Helper()
+	if err := config.Store.Storage.Set(sessionID, []byte("data"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	record := &models.UserSession{UserID: userID, SessionID: sessionID, LastSeenAt: lastSeen}
+	if err := sessions.Create(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	useMemorySessionStore(t)
+	ctx := context.Background()
+	sessions := repository.NewMemory().Sessions()
+	now := time.Now()
+	storeSession(t, sessions, 1, "current", now)
+	storeSession(t, sessions, 1, "laptop", now.Add(-time.Hour))
+	storeSession(t, sessions, 1, "phone", now.Add(-2*time.Hour))
+	storeSession(t, sessions, 2, "other-user", now)
+
+	count, err := revokeOtherSessions(ctx, sessions, 1, "current")
+	if err != nil || count != 2 {
+		t.Fatalf("revokeOtherSessions = %d, %v; want 2, nil", count, err)
+	}
+
+	for sessionID, wantStored := range map[string]bool{"current": true, "laptop": false, "phone": false, "other-user": true} {
+		data, err := config.Store.Storage.Get(sessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (data != nil) != wantStored {
+			t.Errorf("session %q stored = %v; want %v", sessionID, data != nil, wantStored)
+		}
+	}
+
+	remaining := listUserSessions(ctx, sessions, 1)
+	if len(remaining) != 1 || remaining[0].SessionID != "current" {
+		t.Errorf("remaining sessions = %+v; want only current", remaining)
+	}
+}
+
+func TestListUserSessionsDropsExpired(t *testing.T) {
+	useMemorySessionStore(t)
+	ctx := context.Background()
+	sessions := repository.NewMemory().Sessions()
+	now := time.Now()
+	storeSession(t, sessions, 1, "active", now)
+	expired := storeSession(t, sessions, 1, "expired", now.Add(-time.Hour))
+	// Session yang sudah logout/kedaluwarsa hilang dari storage
+	if err := config.Store.Storage.Delete(expired.SessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	active := listUserSessions(ctx, sessions, 1)
+	if len(active) != 1 || active[0].SessionID != "active" {
+		t.Fatalf("listUserSessions = %+v; want only active", active)
+	}
+	if records, _ := sessions.ListForUser(ctx, 1); len(records) != 1 {
+		t.Errorf("stale record was not removed: %+v", records)
+	}
+}
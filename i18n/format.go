@@ -0,0 +1,43 @@
+package i18n
+
+import (
+	"strconv"
+	"time"
+)
+
+// FormatDate tanggal dan jam di zona waktu loc, misalnya "02 Agu 2025, 15:04"
+func FormatDate(lang string, loc *time.Location, t time.Time) string {
+	t = t.In(loc)
+	return formatDay(lang, t) + ", " + t.Format("15:04")
+}
+
+// FormatDateShort tanggal saja di zona waktu loc, misalnya "02 Agu 2025"
+func FormatDateShort(lang string, loc *time.Location, t time.Time) string {
+	return formatDay(lang, t.In(loc))
+}
+
+// formatDay "02 <bulan> 2006" dengan nama bulan singkat dari katalog
+// (key date.month.1 sampai date.month.12)
+func formatDay(lang string, t time.Time) string {
+	month := T(lang, "date.month."+strconv.Itoa(int(t.Month())))
+	return t.Format("02") + " " + month + " " + t.Format("2006")
+}
+
+// TimeSince selisih waktu sejak t dalam bentuk singkat, misalnya "3 jam lalu"
+func TimeSince(lang string, t time.Time) string {
+	diff := time.Since(t)
+
+	days := int(diff.Hours() / 24)
+	hours := int(diff.Hours())
+	minutes := int(diff.Minutes())
+
+	switch {
+	case days > 0:
+		return T(lang, "time.days_ago", days)
+	case hours > 0:
+		return T(lang, "time.hours_ago", hours)
+	case minutes > 0:
+		return T(lang, "time.minutes_ago", minutes)
+	}
+	return T(lang, "time.just_now")
+}
@@ -0,0 +1,67 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResolveLocation(t *testing.T) {
+	tests := []struct {
+		preference, fallback string
+		want                 string
+	}{
+		{"Asia/Tokyo", "Asia/Jakarta", "Asia/Tokyo"},
+		{"", "Asia/Jakarta", "Asia/Jakarta"},
+		{"Mars/Olympus", "Asia/Jakarta", "Asia/Jakarta"},
+		{"", "", "UTC"},
+		{"Mars/Olympus", "Venus/Maxwell", "UTC"},
+	}
+	for _, tt := range tests {
+		if got := ResolveLocation(tt.preference, tt.fallback).String(); got != tt.want {
+			t.Errorf("ResolveLocation(%q, %q) = %s; want %s", tt.preference, tt.fallback, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDateAcrossTimeZones(t *testing.T) {
+	// Tersimpan dalam UTC: 31 Des 2024 20:30
+	stored := time.Date(2024, time.December, 31, 20, 30, 0, 0, time.UTC)
+	tests := []struct {
+		lang, zone string
+		want       string
+		wantShort  string
+	}{
+		{Indonesian, "UTC", "31 Des 2024, 20:30", "31 Des 2024"},
+		{Indonesian, "Asia/Jakarta", "01 Jan 2025, 03:30", "01 Jan 2025"},
+		{English, "Asia/Tokyo", "01 Jan 2025, 05:30", "01 Jan 2025"},
+		{English, "America/New_York", "31 Dec 2024, 15:30", "31 Dec 2024"},
+		{English, "Asia/Kolkata", "01 Jan 2025, 02:00", "01 Jan 2025"},
+		{Indonesian, "Pacific/Auckland", "01 Jan 2025, 09:30", "01 Jan 2025"},
+	}
+	for _, tt := range tests {
+		loc := ResolveLocation(tt.zone, "")
+		if got := FormatDate(tt.lang, loc, stored); got != tt.want {
+			t.Errorf("FormatDate(%s, %s) = %q; want %q", tt.lang, tt.zone, got, tt.want)
+		}
+		if got := FormatDateShort(tt.lang, loc, stored); got != tt.wantShort {
+			t.Errorf("FormatDateShort(%s, %s) = %q; want %q", tt.lang, tt.zone, got, tt.wantShort)
+		}
+	}
+
+	// Waktu musim panas mengikuti tanggalnya, bukan offset tetap
+	summer := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	if got := FormatDate(English, ResolveLocation("America/New_York", ""), summer); got != "01 Jul 2025, 08:00" {
+		t.Errorf("FormatDate in EDT = %q", got)
+	}
+}
+
+func TestLocationFromContext(t *testing.T) {
+	if got := Location(context.Background()); got != time.UTC {
+		t.Errorf("Location without value = %v; want UTC", got)
+	}
+	tokyo := ResolveLocation("Asia/Tokyo", "")
+	if got := Location(WithLocation(context.Background(), tokyo)); got != tokyo {
+		t.Errorf("Location = %v; want Asia/Tokyo", got)
+	}
+}
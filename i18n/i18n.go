@@ -0,0 +1,130 @@
+// Package i18n berisi katalog pesan aplikasi (Bahasa Indonesia dan Inggris),
+// pemilihan bahasa dari header Accept-Language, serta format tanggal sesuai
+// bahasa dan zona waktu user.
+//
+// Katalog disimpan sebagai JSON datar di locales/<bahasa>.json. Nilai pesan
+// boleh berisi verb fmt (%s, %d) yang diisi argumen T. Key yang tidak ada di
+// bahasa yang diminta diambil dari bahasa default, lalu key itu sendiri.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	Indonesian = "id"
+	English    = "en"
+
+	// Default bahasa jika user tidak memilih dan browser tidak cocok
+	Default = Indonesian
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[string]map[string]string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	result := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", entry.Name(), err))
+		}
+		result[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+	return result
+}
+
+// Supported daftar kode bahasa yang punya katalog, terurut
+func Supported() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// IsSupported true jika lang punya katalog
+func IsSupported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// T menerjemahkan key ke bahasa lang dan mengisi argumen dengan fmt.Sprintf
+func T(lang, key string, args ...interface{}) string {
+	message, ok := catalogs[lang][key]
+	if !ok {
+		if message, ok = catalogs[Default][key]; !ok {
+			message = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Match memilih bahasa yang didukung dari header Accept-Language
+// (misalnya "en-US,en;q=0.9,id;q=0.8"). Mengembalikan Default jika tidak
+// ada yang cocok.
+func Match(acceptLanguage string) string {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if IsSupported(base) && q > bestQ {
+			best, bestQ = base, q
+		}
+	}
+	return best
+}
+
+// Resolve bahasa untuk user: preferensi tersimpan jika didukung, lalu
+// header Accept-Language (kosong untuk konteks tanpa request, misalnya email)
+func Resolve(preference, acceptLanguage string) string {
+	if IsSupported(preference) {
+		return preference
+	}
+	return Match(acceptLanguage)
+}
+
+type contextKey struct{}
+
+// WithLang menyimpan bahasa request di context, dipakai kode yang tidak
+// punya akses ke fiber.Ctx (service, job latar belakang)
+func WithLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// Lang bahasa dari context, Default jika tidak ada
+func Lang(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	return Default
+}
@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"context"
+	"time"
+
+	// Database zona waktu ikut di binary; image container minimal sering
+	// tidak punya /usr/share/zoneinfo
+	_ "time/tzdata"
+)
+
+// timeZones pilihan zona waktu di halaman settings (nama IANA)
+var timeZones = []string{
+	"Asia/Jakarta",
+	"Asia/Makassar",
+	"Asia/Jayapura",
+	"Asia/Singapore",
+	"Asia/Kuala_Lumpur",
+	"Asia/Bangkok",
+	"Asia/Manila",
+	"Asia/Hong_Kong",
+	"Asia/Shanghai",
+	"Asia/Tokyo",
+	"Asia/Seoul",
+	"Asia/Kolkata",
+	"Asia/Dubai",
+	"Australia/Perth",
+	"Australia/Sydney",
+	"Pacific/Auckland",
+	"Europe/London",
+	"Europe/Amsterdam",
+	"Europe/Berlin",
+	"Europe/Moscow",
+	"Africa/Johannesburg",
+	"America/Sao_Paulo",
+	"America/New_York",
+	"America/Chicago",
+	"America/Denver",
+	"America/Los_Angeles",
+	"UTC",
+}
+
+// TimeZones daftar zona waktu yang bisa dipilih user
+func TimeZones() []string {
+	return append([]string(nil), timeZones...)
+}
+
+// IsTimeZoneSupported true jika name ada di daftar TimeZones
+func IsTimeZoneSupported(name string) bool {
+	for _, zone := range timeZones {
+		if zone == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveLocation zona waktu pilihan user, lalu fallback (biasanya
+// default_time_zone dari konfigurasi), lalu UTC
+func ResolveLocation(preference, fallback string) *time.Location {
+	for _, name := range []string{preference, fallback} {
+		if name == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+type locationKey struct{}
+
+// WithLocation menyimpan zona waktu tampilan request di context
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// Location zona waktu dari context, UTC jika tidak ada
+func Location(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return time.UTC
+}
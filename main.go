@@ -2,290 +2,108 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
-	"strings"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
-	"github.com/gofiber/fiber/v2/middleware/session"
-	"github.com/gofiber/template/html/v2"
-
+	"ticketing-fiber/app"
 	"ticketing-fiber/config"
-	"ticketing-fiber/handlers"
-	"ticketing-fiber/middleware"
-	"ticketing-fiber/models"
-	"ticketing-fiber/utils"
+	"ticketing-fiber/logging"
+	"ticketing-fiber/tracing"
 )
 
 func main() {
 	// Load configuration
-	cfg := config.LoadConfig()
-
-	// Initialize database
-	if err := config.InitDatabase(cfg); err != nil {
-		log.Fatal(err)
-	}
-
-	// Auto migrate models
-	if err := config.AutoMigrate(
-		&models.User{},
-		&models.Group{},
-		&models.Department{},
-		&models.Ticket{},
-		&models.TicketReply{},
-	); err != nil {
+	cfg, err := config.LoadConfig()
+	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Initialize session store
-	config.Store = session.New(session.Config{
-		Expiration:     cfg.SessionExpiry,
-		CookieHTTPOnly: true,
-		CookieSecure:   false, // Set true in production
-		CookieSameSite: "Lax",
-	})
-
-	// Initialize template engine
-	engine := html.New("./templates", ".html")
-	engine.Reload(cfg.Debug)
-	// engine.Debug(cfg.Debug)
-
-	// --- TEMPLATE FUNCTIONS (HELPER) ---
-
-	//  Slice string (untuk avatar)
-	engine.AddFunc("slice", func(s string, start, end int) string {
-		if start < 0 || end > len(s) || start > end {
-			return s
-		}
-		return s[start:end]
-	})
-
-	//  Uppercase
-	engine.AddFunc("upper", func(s string) string {
-		return strings.ToUpper(s)
-	})
-
-	//  Date formatting standar
-	engine.AddFunc("date", func(t interface{}) string {
-		if t == nil {
-			return ""
-		}
-		switch v := t.(type) {
-		case time.Time:
-			return v.Format("02 Jan 2006, 15:04")
-		case *time.Time:
-			if v == nil {
-				return ""
-			}
-			return v.Format("02 Jan 2006, 15:04")
+	// `config check` harus bisa jalan walau konfigurasi tidak valid
+	// dan tanpa koneksi database
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
 		}
-		return ""
-	})
-
-	//  Date formatting pendek
-	engine.AddFunc("dateShort", func(t interface{}) string {
-		if t == nil {
-			return ""
-		}
-		switch v := t.(type) {
-		case time.Time:
-			return v.Format("02 Jan 2006")
-		case *time.Time:
-			if v == nil {
-				return ""
-			}
-			return v.Format("02 Jan 2006")
-		}
-		return ""
-	})
+		return
+	}
 
-	// Time Since
-	engine.AddFunc("timeSince", func(t time.Time) string {
-		now := time.Now()
-		diff := now.Sub(t)
+	if _, err := logging.Setup(cfg.LogFormat, cfg.LogLevel); err != nil {
+		log.Fatal(err)
+	}
 
-		days := int(diff.Hours() / 24)
-		hours := int(diff.Hours())
-		minutes := int(diff.Minutes())
+	warnings, err := cfg.Validate()
+	for _, w := range warnings {
+		slog.Warn("insecure or incomplete configuration", "problem", w)
+	}
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
 
-		if days > 0 {
-			return fmt.Sprintf("%d hari", days)
-		}
-		if hours > 0 {
-			return fmt.Sprintf("%d jam", hours)
+	// Subcommand CLI
+	if len(os.Args) > 1 {
+		if err := config.InitDatabase(cfg); err != nil {
+			fatal("database initialization failed", err)
 		}
-		if minutes > 0 {
-			return fmt.Sprintf("%d menit", minutes)
+		if err := runCommand(cfg, os.Args[1:]); err != nil {
+			fatal("command failed", err)
 		}
-		return "Baru saja"
-	})
+		return
+	}
 
-	// Helper untuk CSS Class Status
-	engine.AddFunc("getStatusClass", func(status interface{}) string {
-		s := fmt.Sprintf("%v", status)
-		switch s {
-		case "WAITING", "OPEN":
-			return "open"
-		case "IN_PROGRESS":
-			return "in-progress"
-		case "CLOSED", "RESOLVED":
-			return "closed"
-		default:
-			return "closed"
-		}
-	})
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		fatal("tracing setup failed", err)
+	}
 
-	//  Helper untuk CSS Class Priority (PERBAIKAN ERROR TIPE DATA)
-	engine.AddFunc("getPriorityClass", func(priority interface{}) string {
-		p := fmt.Sprintf("%v", priority) // Konversi nilai apa pun ke string
-		switch p {
-		case "HIGH":
-			return "high"
-		case "MEDIUM":
-			return "medium"
-		case "LOW":
-			return "low"
-		default:
-			return "low"
-		}
-	})
+	application, err := app.NewApp(cfg)
+	if err != nil {
+		fatal("application startup failed", err)
+	}
 
-	//  Equality check
-	engine.AddFunc("eq", func(a, b interface{}) bool {
-		return a == b
-	})
+	// Start Server
+	slog.Info("server starting", "port", cfg.Port, "url", "http://localhost:"+cfg.Port, "environment", cfg.Environment)
 
-	//  Length check
-	engine.AddFunc("len", func(arr interface{}) int {
-		if arr == nil {
-			return 0
-		}
-		switch v := arr.(type) {
-		case []interface{}:
-			return len(v)
-		case []models.Ticket:
-			return len(v)
-		case []models.TicketReply:
-			return len(v)
-		case []models.Department:
-			return len(v)
-		case string:
-			return len(v)
-		}
-		return 0
-	})
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Linebreaks
-	engine.AddFunc("linebreaks", func(val interface{}) string {
-		var s string
-		if val == nil {
-			return ""
-		}
-		s = fmt.Sprint(val)
-		return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "<br>"), "\n", "<br>")
-	})
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- application.Listen(":" + cfg.Port)
+	}()
 
-	//  Get Full Name
-	engine.AddFunc("getFullName", func(user interface{}) string {
-		if user == nil {
-			return "User"
-		}
-		// Handle pointer
-		if u, ok := user.(*models.User); ok {
-			if u.FirstName != "" || u.LastName != "" {
-				return strings.TrimSpace(u.FirstName + " " + u.LastName)
-			}
-			return u.Username
-		}
-		// Handle value
-		if u, ok := user.(models.User); ok {
-			if u.FirstName != "" || u.LastName != "" {
-				return strings.TrimSpace(u.FirstName + " " + u.LastName)
-			}
-			return u.Username
+	select {
+	case err := <-listenErr:
+		// Listen gagal (misalnya port sudah dipakai) sebelum ada sinyal
+		slog.Error("server error", "error", err)
+		if shutdownErr := application.Shutdown(cfg.ShutdownTimeout); shutdownErr != nil {
+			slog.Error("shutdown error", "error", shutdownErr)
 		}
-		return "User"
-	})
-
-	// Initialize Fiber app
-	app := fiber.New(fiber.Config{
-		Views:        engine,
-		ErrorHandler: customErrorHandler,
-	})
-
-	// Middleware
-	app.Use(recover.New())
-	app.Use(logger.New(logger.Config{
-		Format:     "${time} | ${status} | ${latency} | ${method} ${path}\n",
-		TimeFormat: "15:04:05",
-	}))
-
-	// Static files
-	app.Static("/static", "./static")
-
-	// Set user locals
-	app.Use(middleware.SetUserLocals)
-
-	// Services & Handlers
-	emailService := utils.NewEmailService(cfg)
-	authHandler := handlers.NewAuthHandler(cfg)
-	dashboardHandler := handlers.NewDashboardHandler(cfg)
-	ticketHandler := handlers.NewTicketHandler(cfg, emailService)
-	settingsHandler := handlers.NewSettingsHandler(cfg)
-
-	// Routes
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.Redirect("/login")
-	})
-
-	// Auth
-	app.Get("/login", middleware.GuestOnly, authHandler.ShowLogin)
-	app.Post("/login", authHandler.Login)
-	app.Get("/register", middleware.GuestOnly, authHandler.ShowRegister)
-	app.Post("/register", authHandler.Register)
-	app.Get("/logout", authHandler.Logout)
-	app.Post("/logout", authHandler.Logout)
-	// Protected Routes
-	protected := app.Group("/", middleware.AuthRequired, middleware.PortalUserRequired)
-	protected.Get("/dashboard", dashboardHandler.ShowDashboard)
-	protected.Get("/tiket", ticketHandler.ShowMyTickets)
-	protected.Get("/tiket/:id", ticketHandler.ShowTicketDetail)
-	protected.Post("/tiket/:id", ticketHandler.AddReply)
-	protected.Get("/kirim-tiket", ticketHandler.ShowCreateTicket)
-	protected.Post("/kirim-tiket", ticketHandler.CreateTicket)
-	protected.Get("/tiket/sukses/:id", ticketHandler.ShowTicketSuccess)
-	protected.Get("/settings", settingsHandler.ShowSettings)
-	protected.Post("/settings/profile", settingsHandler.UpdateProfile)
-	protected.Post("/settings/password", settingsHandler.ChangePassword)
-
-	// Seed Data
-	seedDefaultData()
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+	stop()
 
-	// Start Server
-	log.Printf("🚀 Server starting on port %s", cfg.Port)
-	log.Printf("🌐 Visit: http://localhost:%s", cfg.Port)
-	log.Fatal(app.Listen(":" + cfg.Port))
-}
+	slog.Info("shutting down", "timeout", cfg.ShutdownTimeout)
+	if err := application.Shutdown(cfg.ShutdownTimeout); err != nil {
+		fatal("shutdown incomplete", err)
+	}
 
-func customErrorHandler(c *fiber.Ctx, err error) error {
-	code := fiber.StatusInternalServerError
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
+	// Flush span yang masih di buffer exporter
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("tracing shutdown failed", "error", err)
 	}
-	log.Printf("Error: %v", err)
-	return c.Status(code).SendString(fmt.Sprintf("Internal Server Error: %v", err))
+	slog.Info("server stopped")
 }
 
-func seedDefaultData() {
-	var portalGroup models.Group
-	config.DB.FirstOrCreate(&portalGroup, models.Group{Name: "Portal Users"})
-
-	departments := []string{"Technical Support", "Customer Service", "Billing", "General"}
-	for _, deptName := range departments {
-		var dept models.Department
-		config.DB.FirstOrCreate(&dept, models.Department{Name: deptName})
-	}
+// fatal mencatat error lalu keluar dengan status 1
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
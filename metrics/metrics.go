@@ -0,0 +1,158 @@
+// Package metrics berisi metrik Prometheus aplikasi dan handler /metrics.
+// Metrik didaftarkan ke Registry sendiri (bukan default registry global)
+// agar isi /metrics hanya yang didefinisikan di sini.
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"ticketing-fiber/apperrors"
+)
+
+const namespace = "ticketing"
+
+// Registry registry Prometheus yang diekspos di /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	TicketsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tickets_created_total",
+		Help:      "Tickets created.",
+	})
+
+	RepliesAdded = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ticket_replies_total",
+		Help:      "Replies added to tickets.",
+	})
+
+	EmailsSent = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_sent_total",
+		Help:      "Emails delivered to the mail transport.",
+	})
+
+	EmailsFailed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_failed_total",
+		Help:      "Emails that could not be sent after all retries.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RequestDuration,
+		TicketsCreated,
+		RepliesAdded,
+		EmailsSent,
+		EmailsFailed,
+	)
+}
+
+// Middleware mencatat latensi setiap request. Label route memakai pola route
+// (misalnya /tiket/:id), bukan path asli, agar jumlah series tetap terbatas.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			} else if e, ok := apperrors.As(err); ok {
+				status = e.StatusCode()
+			}
+		}
+		// Method disalin karena string dari Fiber hanya valid selama request
+		RequestDuration.WithLabelValues(
+			utils.CopyString(c.Method()),
+			c.Route().Path,
+			strconv.Itoa(status),
+		).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// Handler mengembalikan handler /metrics dalam format teks Prometheus
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// OpenTickets satu nilai gauge ticketing_open_tickets
+type OpenTickets struct {
+	Status     string
+	Department string
+	Count      int64
+}
+
+// OpenTicketsFunc menghitung tiket yang belum ditutup saat /metrics di-scrape
+type OpenTicketsFunc func(ctx context.Context) ([]OpenTickets, error)
+
+var registeredTicketCollector *ticketCollector
+
+// RegisterTicketCollector mendaftarkan gauge ticketing_open_tickets yang
+// dihitung oleh count setiap kali /metrics di-scrape. Collector sebelumnya
+// (dari NewApp sebelumnya) diganti.
+func RegisterTicketCollector(count OpenTicketsFunc) error {
+	if registeredTicketCollector != nil {
+		Registry.Unregister(registeredTicketCollector)
+	}
+	registeredTicketCollector = &ticketCollector{count: count}
+	return Registry.Register(registeredTicketCollector)
+}
+
+var openTicketsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "open_tickets"),
+	"Tickets that are not closed, by status and department.",
+	[]string{"status", "department"}, nil,
+)
+
+type ticketCollector struct {
+	count OpenTicketsFunc
+}
+
+func (tc *ticketCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openTicketsDesc
+}
+
+func (tc *ticketCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts, err := tc.count(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "metrics: failed to count open tickets", "error", err)
+		ch <- prometheus.NewInvalidMetric(openTicketsDesc, err)
+		return
+	}
+	for _, count := range counts {
+		department := count.Department
+		if department == "" {
+			department = "none"
+		}
+		ch <- prometheus.MustNewConstMetric(openTicketsDesc, prometheus.GaugeValue,
+			float64(count.Count), count.Status, department)
+	}
+}
@@ -1,34 +1,34 @@
 package middleware
 
 import (
+	"ticketing-fiber/apperrors"
 	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
 	"ticketing-fiber/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// AuthRequired middleware untuk memastikan user sudah login
+// AuthRequired middleware untuk memastikan user sudah login. User dimuat oleh
+// SetUserLocals, jadi middleware ini harus dipasang setelahnya.
 func AuthRequired(c *fiber.Ctx) error {
-	sess, err := config.Store.Get(c)
-	if err != nil {
-		return c.Redirect("/login")
+	if _, ok := c.Locals("user").(*models.User); ok {
+		return c.Next()
 	}
 
-	userID := sess.Get("user_id")
-	if userID == nil {
-		return c.Redirect("/login")
-	}
-
-	// Load user dari database
-	var user models.User
-	if err := config.DB.Preload("Groups").First(&user, userID).Error; err != nil {
+	// user_id di session tapi user tidak ditemukan: bersihkan session lama
+	if sess, err := config.Store.Get(c); err == nil && sess.Get("user_id") != nil {
 		sess.Destroy()
-		return c.Redirect("/login")
 	}
+	return c.Redirect("/login")
+}
 
-	// Set user ke locals untuk diakses di handler
-	c.Locals("user", &user)
-	c.Locals("authenticated", true)
+// StaffRequired middleware untuk halaman khusus staff (dipasang setelah AuthRequired)
+func StaffRequired(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok || !user.IsStaff {
+		return apperrors.Forbidden(i18n.T(i18n.Lang(c.UserContext()), "error.staff_only"))
+	}
 
 	return c.Next()
 }
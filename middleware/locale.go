@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/models"
+)
+
+// Locale menentukan bahasa dan zona waktu request. Bahasa: pilihan user di
+// settings, lalu header Accept-Language. Zona waktu: pilihan user, lalu
+// default_time_zone. Keduanya disimpan di user context (i18n.Lang,
+// i18n.Location) dan locals "lang". Dipasang setelah SetUserLocals.
+func Locale(cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var language, timeZone string
+		if user, ok := c.Locals("user").(*models.User); ok {
+			language, timeZone = user.Language, user.TimeZone
+		}
+		lang := i18n.Resolve(language, c.Get(fiber.HeaderAcceptLanguage))
+		loc := i18n.ResolveLocation(timeZone, cfg.DefaultTimeZone)
+
+		c.Locals("lang", lang)
+		ctx := i18n.WithLang(c.UserContext(), lang)
+		c.SetUserContext(i18n.WithLocation(ctx, loc))
+		return c.Next()
+	}
+}
@@ -1,8 +1,16 @@
 package middleware
 
 import (
+	"log/slog"
+	"time"
+
+	"ticketing-fiber/apperrors"
 	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/logging"
 	"ticketing-fiber/models"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,9 +20,7 @@ func PortalUserRequired(c *fiber.Ctx) error {
 	user := c.Locals("user").(*models.User)
 
 	if !user.HasPortalAccess() {
-		return c.Status(fiber.StatusForbidden).Render("tickets/error", fiber.Map{
-			"error": "Akses ini khusus untuk akun pengguna portal.",
-		})
+		return apperrors.Forbidden(i18n.T(i18n.Lang(c.UserContext()), "error.portal_only"))
 	}
 
 	return c.Next()
@@ -34,29 +40,59 @@ func GuestOnly(c *fiber.Ctx) error {
 }
 
 // SetUserLocals middleware untuk set user info ke semua template
-func SetUserLocals(c *fiber.Ctx) error {
-	sess, err := config.Store.Get(c)
-	if err == nil {
-		userID := sess.Get("user_id")
-		if userID != nil {
-			var user models.User
-			if err := config.DB.Preload("Groups").First(&user, userID).Error; err == nil {
-				c.Locals("user", &user)
-				c.Locals("authenticated", true)
-
-				// Count active tickets
-				var activeCount int64
-				config.DB.Model(&models.Ticket{}).
-					Where("created_by_id = ? AND status != ?", user.ID, models.StatusClosed).
-					Count(&activeCount)
-				c.Locals("active_tickets_count", activeCount)
-
-				return c.Next()
+func SetUserLocals(users repository.UserRepository, sessions repository.SessionRepository, tickets *services.TicketService, notifications *services.NotificationService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sess, err := config.Store.Get(c)
+		if err == nil {
+			if userID, ok := sessionUserID(sess.Get("user_id")); ok {
+				if user, err := users.FindByID(c.UserContext(), userID); err == nil && user.IsActive {
+					c.Locals("user", user)
+					c.Locals("authenticated", true)
+					c.SetUserContext(logging.WithUserID(c.UserContext(), user.ID))
+
+					// Count active tickets
+					var activeCount int64
+					if stats, err := tickets.Stats(c.UserContext(), user.ID); err == nil {
+						activeCount = stats.Active()
+					}
+					c.Locals("active_tickets_count", activeCount)
+
+					// Count unread notifications
+					var unreadCount int64
+					if count, err := notifications.UnreadCount(c.UserContext(), user.ID); err == nil {
+						unreadCount = count
+					}
+					c.Locals("unread_count", unreadCount)
+
+					// Waktu terakhir aktif session diperbarui maksimal sekali per menit
+					if err := sessions.Touch(c.UserContext(), sess.ID(), time.Now().UTC(), time.Minute); err != nil {
+						slog.WarnContext(c.UserContext(), "failed to touch user session", "error", err)
+					}
+
+					return c.Next()
+				}
 			}
 		}
+
+		c.Locals("authenticated", false)
+		c.Locals("active_tickets_count", 0)
+		c.Locals("unread_count", 0)
+		return c.Next()
 	}
+}
 
-	c.Locals("authenticated", false)
-	c.Locals("active_tickets_count", 0)
-	return c.Next()
+// sessionUserID membaca user_id dari session yang bisa tersimpan sebagai
+// tipe integer apa pun tergantung storage
+func sessionUserID(value interface{}) (uint, bool) {
+	switch v := value.(type) {
+	case uint:
+		return v, true
+	case uint64:
+		return uint(v), true
+	case int:
+		return uint(v), v > 0
+	case int64:
+		return uint(v), v > 0
+	}
+	return 0, false
 }
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"log/slog"
+	"regexp"
+	"time"
+
+	"ticketing-fiber/apperrors"
+	"ticketing-fiber/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// RequestIDHeader header request/response yang membawa request ID
+const RequestIDHeader = fiber.HeaderXRequestID
+
+// validRequestID membatasi request ID dari client agar aman ditulis ke log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID memakai X-Request-ID dari client (jika valid) atau membuat UUID
+// baru, lalu menyimpannya di Locals "request_id", context request dan header
+// response
+func RequestID(c *fiber.Ctx) error {
+	id := c.Get(RequestIDHeader)
+	if !validRequestID.MatchString(id) {
+		id = utils.UUIDv4()
+	} else {
+		id = utils.CopyString(id)
+	}
+
+	c.Locals("request_id", id)
+	c.SetUserContext(logging.WithRequestID(c.UserContext(), id))
+	c.Set(RequestIDHeader, id)
+	return c.Next()
+}
+
+// AccessLog menulis satu baris log per request setelah request selesai
+func AccessLog(c *fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	status := c.Response().StatusCode()
+	if err != nil {
+		status = fiber.StatusInternalServerError
+		if e, ok := err.(*fiber.Error); ok {
+			status = e.Code
+		} else if e, ok := apperrors.As(err); ok {
+			status = e.StatusCode()
+		}
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case status >= 500:
+		level = slog.LevelError
+	case status >= 400:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", c.Method()),
+		slog.String("path", c.Path()),
+		slog.Int("status", status),
+		slog.Duration("latency", time.Since(start)),
+		slog.String("ip", c.IP()),
+	}
+	// request_id dan user_id (diset SetUserLocals) ikut dari context
+	slog.LogAttrs(c.UserContext(), level, "request", attrs...)
+	return err
+}
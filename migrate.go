@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/migrations"
+)
+
+const migrateUsage = `usage: ticketing-fiber migrate <command>
+
+commands:
+  status            tampilkan migrasi yang sudah dan belum diterapkan
+  up                terapkan semua migrasi yang belum diterapkan
+  down [n]          batalkan n migrasi terakhir (default 1)
+  to <version>      naik/turun sampai tepat di versi tertentu (0 = kosong)
+  baseline <version>
+                    tandai migrasi sampai versi tertentu sebagai sudah
+                    diterapkan, untuk database lama hasil AutoMigrate`
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return errors.New("missing migrate command")
+	}
+
+	migrator, err := migrations.New(config.DB)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(migrator)
+	case "up":
+		if err := migrator.Up(); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		if err := migrator.Down(steps); err != nil {
+			return err
+		}
+	case "to":
+		version, err := versionArg(args)
+		if err != nil {
+			return err
+		}
+		if err := migrator.To(version); err != nil {
+			return err
+		}
+	case "baseline":
+		version, err := versionArg(args)
+		if err != nil {
+			return err
+		}
+		if err := migrator.Baseline(version); err != nil {
+			return err
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Database schema is at version %d (latest %d)\n", version, migrator.Latest())
+	return nil
+}
+
+func versionArg(args []string) (int, error) {
+	if len(args) < 2 {
+		return 0, fmt.Errorf("migrate %s requires a version", args[0])
+	}
+	version, err := strconv.Atoi(args[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %q", args[1])
+	}
+	return version, nil
+}
+
+func printMigrationStatus(migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	return w.Flush()
+}
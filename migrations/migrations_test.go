@@ -0,0 +1,180 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrate.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, db
+}
+
+// recorded isi schema_migrations sebagai "0001_name", urut versi
+func recorded(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var rows []SchemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, row := range rows {
+		if row.AppliedAt.IsZero() {
+			t.Errorf("migration %d has no applied_at", row.Version)
+		}
+		names = append(names, fmt.Sprintf("%04d_%s", row.Version, row.Name))
+	}
+	return names
+}
+
+// expected nama migrasi di binary sampai versi target
+func expected(m *Migrator, target int) []string {
+	names := []string{}
+	for _, migration := range m.migrations {
+		if migration.Version <= target {
+			names = append(names, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+	}
+	return names
+}
+
+func hasTable(db *gorm.DB, name string) bool {
+	return db.Migrator().HasTable(name)
+}
+
+func TestMigratorUpDownUp(t *testing.T) {
+	m, db := newMigrator(t)
+	latest := m.Latest()
+
+	if err := m.Check(); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("Check on empty database = %v; want ErrSchemaMismatch", err)
+	}
+
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := recorded(t, db), expected(m, latest); !reflect.DeepEqual(got, want) {
+		t.Errorf("schema_migrations after Up = %v; want %v", got, want)
+	}
+	if err := m.Check(); err != nil {
+		t.Errorf("Check after Up = %v", err)
+	}
+	for _, table := range []string{"users", "tickets", "ticket_replies", "audit_events"} {
+		if !hasTable(db, table) {
+			t.Errorf("table %s missing after Up", table)
+		}
+	}
+
+	// Migrasi terakhir belum diterapkan: Check gagal dan menyebut namanya
+	last := m.migrations[len(m.migrations)-1]
+	previous := m.migrations[len(m.migrations)-2].Version
+	if err := m.To(previous); err != nil {
+		t.Fatal(err)
+	}
+	pending := fmt.Sprintf("%04d_%s", last.Version, last.Name)
+	err := m.Check()
+	if !errors.Is(err, ErrSchemaMismatch) || !strings.Contains(err.Error(), pending) {
+		t.Errorf("Check with %s pending = %v; want ErrSchemaMismatch naming it", pending, err)
+	}
+	if version, _ := m.Version(); version != previous {
+		t.Errorf("Version = %d; want %d", version, previous)
+	}
+
+	if err := m.To(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := recorded(t, db); len(got) != 0 {
+		t.Errorf("schema_migrations after To(0) = %v; want empty", got)
+	}
+	for _, table := range []string{"users", "tickets"} {
+		if hasTable(db, table) {
+			t.Errorf("table %s still exists after To(0)", table)
+		}
+	}
+
+	// Down script bersih sehingga migrasi bisa diterapkan ulang
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up after To(0): %v", err)
+	}
+	if got, want := recorded(t, db), expected(m, latest); !reflect.DeepEqual(got, want) {
+		t.Errorf("schema_migrations after second Up = %v; want %v", got, want)
+	}
+	if err := m.Check(); err != nil {
+		t.Errorf("Check after second Up = %v", err)
+	}
+}
+
+func TestMigratorCheckRejectsNewerDatabase(t *testing.T) {
+	m, db := newMigrator(t)
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	// Database sudah dimigrasi binary yang lebih baru
+	if err := db.Create(&SchemaMigration{Version: m.Latest() + 1, Name: "future", AppliedAt: time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Check(); !errors.Is(err, ErrSchemaMismatch) {
+		t.Errorf("Check = %v; want ErrSchemaMismatch", err)
+	}
+	if err := m.To(m.Latest() + 1); err == nil {
+		t.Error("To(unknown version) succeeded")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	script := &fstest.MapFile{Data: []byte("SELECT 1;")}
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int
+		wantErr string
+	}{
+		{"sorted by version", fstest.MapFS{
+			"x/0002_b.up.sql": script, "x/0002_b.down.sql": script,
+			"x/0001_a.up.sql": script, "x/0001_a.down.sql": script,
+		}, []int{1, 2}, ""},
+		{"missing dialect", fstest.MapFS{}, []int{}, ""},
+		{"missing down", fstest.MapFS{"x/0001_a.up.sql": script}, nil, "both up and down"},
+		{"conflicting names", fstest.MapFS{"x/0001_a.up.sql": script, "x/0001_b.down.sql": script}, nil, "conflicting names"},
+		{"invalid name", fstest.MapFS{"x/init.sql": script}, nil, "invalid migration file name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.files, "x")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("load error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			versions := []int{}
+			for _, migration := range migrations {
+				versions = append(versions, migration.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.want) {
+				t.Errorf("versions = %v; want %v", versions, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+-- DDL MySQL tidak transaksional: jika migrasi gagal di tengah jalan,
+-- perbaiki skema secara manual sebelum menjalankan `migrate up` lagi.
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `username` varchar(191) NOT NULL,
+    `email` varchar(191) NOT NULL,
+    `password` varchar(255) NOT NULL,
+    `first_name` varchar(255),
+    `last_name` varchar(255),
+    `is_staff` boolean DEFAULT false,
+    `is_active` boolean DEFAULT true,
+    `last_login` datetime(3),
+    `created_at` datetime(3),
+    `updated_at` datetime(3),
+    `deleted_at` datetime(3),
+    UNIQUE INDEX `idx_users_username` (`username`),
+    UNIQUE INDEX `idx_users_email` (`email`),
+    INDEX `idx_users_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `groups` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `name` varchar(191) NOT NULL,
+    `created_at` datetime(3),
+    UNIQUE INDEX `idx_groups_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `user_groups` (
+    `group_id` bigint unsigned,
+    `user_id` bigint unsigned,
+    PRIMARY KEY (`group_id`, `user_id`),
+    CONSTRAINT `fk_user_groups_group` FOREIGN KEY (`group_id`) REFERENCES `groups` (`id`),
+    CONSTRAINT `fk_user_groups_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `departments` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `name` varchar(191) NOT NULL,
+    `created_at` datetime(3),
+    `updated_at` datetime(3),
+    UNIQUE INDEX `idx_departments_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `tickets` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `title` varchar(255) NOT NULL,
+    `description` text NOT NULL,
+    `status` varchar(32) DEFAULT 'WAITING',
+    `priority` varchar(32) DEFAULT 'MEDIUM',
+    `reply_to_email` varchar(255),
+    `created_by_id` bigint unsigned NOT NULL,
+    `department_id` bigint unsigned,
+    `created_at` datetime(3),
+    `updated_at` datetime(3),
+    `deleted_at` datetime(3),
+    INDEX `idx_tickets_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_users_tickets` FOREIGN KEY (`created_by_id`) REFERENCES `users` (`id`),
+    CONSTRAINT `fk_departments_tickets` FOREIGN KEY (`department_id`) REFERENCES `departments` (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `ticket_replies` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `ticket_id` bigint unsigned NOT NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `message` text NOT NULL,
+    `created_at` datetime(3),
+    CONSTRAINT `fk_tickets_replies` FOREIGN KEY (`ticket_id`) REFERENCES `tickets` (`id`),
+    CONSTRAINT `fk_users_replies` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS `sessions` (
+    `id` varchar(128) PRIMARY KEY,
+    `data` longblob NOT NULL,
+    `expires_at` datetime(3),
+    `updated_at` datetime(3),
+    INDEX `idx_sessions_expires_at` (`expires_at`)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `user_sessions` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `user_id` bigint unsigned NOT NULL,
+    `session_id` varchar(128) NOT NULL,
+    `ip_address` varchar(64),
+    `user_agent` varchar(512),
+    `created_at` datetime(3),
+    `last_seen_at` datetime(3),
+    UNIQUE INDEX `idx_user_sessions_session_id` (`session_id`),
+    INDEX `idx_user_sessions_user_id` (`user_id`),
+    CONSTRAINT `fk_user_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `user_identities` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `user_id` bigint unsigned NOT NULL,
+    `provider` varchar(255) NOT NULL,
+    `subject` varchar(255) NOT NULL,
+    `email` varchar(255),
+    `created_at` datetime(3),
+    `last_login_at` datetime(3),
+    UNIQUE INDEX `idx_identity_provider_subject` (`provider`, `subject`),
+    INDEX `idx_user_identities_user_id` (`user_id`),
+    CONSTRAINT `fk_user_identities_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+);
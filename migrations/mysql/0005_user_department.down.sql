@@ -0,0 +1,2 @@
+ALTER TABLE `users` DROP FOREIGN KEY `fk_users_department`;
+ALTER TABLE `users` DROP COLUMN `department_id`;
@@ -0,0 +1,3 @@
+ALTER TABLE `users`
+    ADD COLUMN `department_id` bigint unsigned,
+    ADD CONSTRAINT `fk_users_department` FOREIGN KEY (`department_id`) REFERENCES `departments` (`id`);
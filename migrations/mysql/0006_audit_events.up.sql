@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `audit_events` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3),
+    `action` varchar(64) NOT NULL,
+    `actor_id` bigint unsigned,
+    `actor_username` varchar(255),
+    `ip_address` varchar(64),
+    `user_agent` varchar(512),
+    `target_type` varchar(64),
+    `target_id` varchar(64),
+    `changes` text,
+    INDEX `idx_audit_target` (`target_type`, `target_id`),
+    INDEX `idx_audit_events_actor_id` (`actor_id`),
+    INDEX `idx_audit_events_action` (`action`),
+    INDEX `idx_audit_events_created_at` (`created_at`)
+);
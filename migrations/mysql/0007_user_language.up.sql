@@ -0,0 +1 @@
+ALTER TABLE `users` ADD COLUMN `language` varchar(8) NOT NULL DEFAULT '';
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `notifications` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `user_id` bigint unsigned NOT NULL,
+    `ticket_id` bigint unsigned NOT NULL,
+    `kind` varchar(20) NOT NULL,
+    `detail` varchar(255),
+    `dedupe_key` varchar(191) NOT NULL,
+    `read_at` datetime(3),
+    `created_at` datetime(3),
+    UNIQUE INDEX `idx_notifications_dedupe` (`user_id`, `dedupe_key`),
+    INDEX `idx_notifications_unread` (`user_id`, `read_at`),
+    CONSTRAINT `fk_notifications_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`),
+    CONSTRAINT `fk_notifications_ticket` FOREIGN KEY (`ticket_id`) REFERENCES `tickets` (`id`)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE `notifications` DROP COLUMN `in_app`;
+DROP TABLE IF EXISTS `notification_preferences`;
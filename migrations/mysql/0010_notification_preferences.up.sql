@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS `notification_preferences` (
+    `user_id` bigint unsigned PRIMARY KEY,
+    `email_created` boolean NOT NULL DEFAULT true,
+    `email_reply` boolean NOT NULL DEFAULT true,
+    `email_status` boolean NOT NULL DEFAULT true,
+    `email_assignment` boolean NOT NULL DEFAULT true,
+    `in_app_reply` boolean NOT NULL DEFAULT true,
+    `in_app_status` boolean NOT NULL DEFAULT true,
+    `in_app_assignment` boolean NOT NULL DEFAULT true,
+    `webhook_created` boolean NOT NULL DEFAULT false,
+    `webhook_reply` boolean NOT NULL DEFAULT false,
+    `webhook_status` boolean NOT NULL DEFAULT false,
+    `webhook_assignment` boolean NOT NULL DEFAULT false,
+    `webhook_url` varchar(500) NOT NULL DEFAULT '',
+    `daily_digest` boolean NOT NULL DEFAULT false,
+    `digest_sent_at` datetime(3),
+    `updated_at` datetime(3),
+    INDEX `idx_notification_preferences_digest` (`daily_digest`, `digest_sent_at`),
+    CONSTRAINT `fk_notification_preferences_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+);
+ALTER TABLE `notifications` ADD COLUMN `in_app` boolean NOT NULL DEFAULT true;
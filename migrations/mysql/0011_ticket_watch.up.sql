@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `ticket_watch_cursors` (
+    `name` varchar(64) PRIMARY KEY,
+    `reply_id` bigint unsigned NOT NULL,
+    `ticket_updated_at` datetime(3) NOT NULL,
+    `ticket_id` bigint unsigned NOT NULL
+);
+CREATE TABLE IF NOT EXISTS `ticket_watch_states` (
+    `ticket_id` bigint unsigned PRIMARY KEY,
+    `status` varchar(32),
+    `department_id` bigint unsigned,
+    `seen_at` datetime(3) NOT NULL,
+    `status_changed` boolean NOT NULL DEFAULT false,
+    `department_changed` boolean NOT NULL DEFAULT false,
+    `version` bigint unsigned NOT NULL
+);
+CREATE INDEX `idx_tickets_updated_at_id` ON `tickets` (`updated_at`, `id`);
@@ -0,0 +1,61 @@
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial PRIMARY KEY,
+    "username" text NOT NULL,
+    "email" text NOT NULL,
+    "password" text NOT NULL,
+    "first_name" text,
+    "last_name" text,
+    "is_staff" boolean DEFAULT false,
+    "is_active" boolean DEFAULT true,
+    "last_login" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_username" ON "users" ("username");
+
+CREATE TABLE IF NOT EXISTS "groups" (
+    "id" bigserial PRIMARY KEY,
+    "name" text NOT NULL,
+    "created_at" timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_groups_name" ON "groups" ("name");
+
+CREATE TABLE IF NOT EXISTS "user_groups" (
+    "group_id" bigint REFERENCES "groups" ("id"),
+    "user_id" bigint REFERENCES "users" ("id"),
+    PRIMARY KEY ("group_id", "user_id")
+);
+
+CREATE TABLE IF NOT EXISTS "departments" (
+    "id" bigserial PRIMARY KEY,
+    "name" text NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_departments_name" ON "departments" ("name");
+
+CREATE TABLE IF NOT EXISTS "tickets" (
+    "id" bigserial PRIMARY KEY,
+    "title" text NOT NULL,
+    "description" text NOT NULL,
+    "status" text DEFAULT 'WAITING',
+    "priority" text DEFAULT 'MEDIUM',
+    "reply_to_email" text,
+    "created_by_id" bigint NOT NULL REFERENCES "users" ("id"),
+    "department_id" bigint REFERENCES "departments" ("id"),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz
+);
+CREATE INDEX IF NOT EXISTS "idx_tickets_deleted_at" ON "tickets" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "ticket_replies" (
+    "id" bigserial PRIMARY KEY,
+    "ticket_id" bigint NOT NULL REFERENCES "tickets" ("id"),
+    "user_id" bigint NOT NULL REFERENCES "users" ("id"),
+    "message" text NOT NULL,
+    "created_at" timestamptz
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS "sessions" (
+    "id" varchar(128) PRIMARY KEY,
+    "data" bytea NOT NULL,
+    "expires_at" timestamptz,
+    "updated_at" timestamptz
+);
+CREATE INDEX IF NOT EXISTS "idx_sessions_expires_at" ON "sessions" ("expires_at");
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "user_sessions" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES "users" ("id"),
+    "session_id" varchar(128) NOT NULL,
+    "ip_address" varchar(64),
+    "user_agent" varchar(512),
+    "created_at" timestamptz,
+    "last_seen_at" timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_sessions_session_id" ON "user_sessions" ("session_id");
+CREATE INDEX IF NOT EXISTS "idx_user_sessions_user_id" ON "user_sessions" ("user_id");
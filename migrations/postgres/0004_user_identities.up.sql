@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "user_identities" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES "users" ("id"),
+    "provider" varchar(255) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    "email" text,
+    "created_at" timestamptz,
+    "last_login_at" timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_identity_provider_subject" ON "user_identities" ("provider", "subject");
+CREATE INDEX IF NOT EXISTS "idx_user_identities_user_id" ON "user_identities" ("user_id");
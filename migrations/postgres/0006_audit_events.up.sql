@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS "audit_events" (
+    "id" bigserial PRIMARY KEY,
+    "created_at" timestamptz,
+    "action" varchar(64) NOT NULL,
+    "actor_id" bigint,
+    "actor_username" varchar(255),
+    "ip_address" varchar(64),
+    "user_agent" varchar(512),
+    "target_type" varchar(64),
+    "target_id" varchar(64),
+    "changes" text
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_target" ON "audit_events" ("target_type", "target_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_actor_id" ON "audit_events" ("actor_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_action" ON "audit_events" ("action");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_created_at" ON "audit_events" ("created_at");
@@ -0,0 +1 @@
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "time_zone" varchar(64) NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "notifications" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES "users" ("id"),
+    "ticket_id" bigint NOT NULL REFERENCES "tickets" ("id"),
+    "kind" varchar(20) NOT NULL,
+    "detail" varchar(255),
+    "dedupe_key" varchar(191) NOT NULL,
+    "read_at" timestamptz,
+    "created_at" timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_notifications_dedupe" ON "notifications" ("user_id", "dedupe_key");
+CREATE INDEX IF NOT EXISTS "idx_notifications_unread" ON "notifications" ("user_id", "read_at");
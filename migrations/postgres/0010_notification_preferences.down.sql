@@ -0,0 +1,2 @@
+ALTER TABLE "notifications" DROP COLUMN IF EXISTS "in_app";
+DROP TABLE IF EXISTS "notification_preferences";
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "notification_preferences" (
+    "user_id" bigint PRIMARY KEY REFERENCES "users" ("id"),
+    "email_created" boolean NOT NULL DEFAULT true,
+    "email_reply" boolean NOT NULL DEFAULT true,
+    "email_status" boolean NOT NULL DEFAULT true,
+    "email_assignment" boolean NOT NULL DEFAULT true,
+    "in_app_reply" boolean NOT NULL DEFAULT true,
+    "in_app_status" boolean NOT NULL DEFAULT true,
+    "in_app_assignment" boolean NOT NULL DEFAULT true,
+    "webhook_created" boolean NOT NULL DEFAULT false,
+    "webhook_reply" boolean NOT NULL DEFAULT false,
+    "webhook_status" boolean NOT NULL DEFAULT false,
+    "webhook_assignment" boolean NOT NULL DEFAULT false,
+    "webhook_url" varchar(500) NOT NULL DEFAULT '',
+    "daily_digest" boolean NOT NULL DEFAULT false,
+    "digest_sent_at" timestamptz,
+    "updated_at" timestamptz
+);
+CREATE INDEX IF NOT EXISTS "idx_notification_preferences_digest" ON "notification_preferences" ("daily_digest", "digest_sent_at");
+ALTER TABLE "notifications" ADD COLUMN IF NOT EXISTS "in_app" boolean NOT NULL DEFAULT true;
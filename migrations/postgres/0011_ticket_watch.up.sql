@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS "ticket_watch_cursors" (
+    "name" varchar(64) PRIMARY KEY,
+    "reply_id" bigint NOT NULL,
+    "ticket_updated_at" timestamptz NOT NULL,
+    "ticket_id" bigint NOT NULL
+);
+CREATE TABLE IF NOT EXISTS "ticket_watch_states" (
+    "ticket_id" bigint PRIMARY KEY,
+    "status" varchar(32),
+    "department_id" bigint,
+    "seen_at" timestamptz NOT NULL,
+    "status_changed" boolean NOT NULL DEFAULT false,
+    "department_changed" boolean NOT NULL DEFAULT false,
+    "version" bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS "idx_tickets_updated_at_id" ON "tickets" ("updated_at", "id");
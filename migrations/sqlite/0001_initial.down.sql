@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `ticket_replies`;
+DROP TABLE IF EXISTS `tickets`;
+DROP TABLE IF EXISTS `user_groups`;
+DROP TABLE IF EXISTS `groups`;
+DROP TABLE IF EXISTS `users`;
+DROP TABLE IF EXISTS `departments`;
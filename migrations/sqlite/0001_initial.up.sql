@@ -0,0 +1,19 @@
+-- Skema awal portal (users, groups, departments, tickets, replies).
+-- Memakai IF NOT EXISTS agar database lama hasil AutoMigrate bisa diadopsi.
+CREATE TABLE IF NOT EXISTS `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`username` text NOT NULL,`email` text NOT NULL,`password` text NOT NULL,`first_name` text,`last_name` text,`is_staff` numeric DEFAULT false,`is_active` numeric DEFAULT true,`last_login` datetime,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime);
+CREATE INDEX IF NOT EXISTS `idx_users_deleted_at` ON `users`(`deleted_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_email` ON `users`(`email`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_username` ON `users`(`username`);
+
+CREATE TABLE IF NOT EXISTS `groups` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`created_at` datetime);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_groups_name` ON `groups`(`name`);
+
+CREATE TABLE IF NOT EXISTS `user_groups` (`group_id` integer,`user_id` integer,PRIMARY KEY (`group_id`,`user_id`),CONSTRAINT `fk_user_groups_group` FOREIGN KEY (`group_id`) REFERENCES `groups`(`id`),CONSTRAINT `fk_user_groups_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+
+CREATE TABLE IF NOT EXISTS `departments` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`created_at` datetime,`updated_at` datetime);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_departments_name` ON `departments`(`name`);
+
+CREATE TABLE IF NOT EXISTS `tickets` (`id` integer PRIMARY KEY AUTOINCREMENT,`title` text NOT NULL,`description` text NOT NULL,`status` text DEFAULT 'WAITING',`priority` text DEFAULT 'MEDIUM',`reply_to_email` text,`created_by_id` integer NOT NULL,`department_id` integer,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,CONSTRAINT `fk_users_tickets` FOREIGN KEY (`created_by_id`) REFERENCES `users`(`id`),CONSTRAINT `fk_departments_tickets` FOREIGN KEY (`department_id`) REFERENCES `departments`(`id`));
+CREATE INDEX IF NOT EXISTS `idx_tickets_deleted_at` ON `tickets`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `ticket_replies` (`id` integer PRIMARY KEY AUTOINCREMENT,`ticket_id` integer NOT NULL,`user_id` integer NOT NULL,`message` text NOT NULL,`created_at` datetime,CONSTRAINT `fk_tickets_replies` FOREIGN KEY (`ticket_id`) REFERENCES `tickets`(`id`),CONSTRAINT `fk_users_replies` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
@@ -0,0 +1,2 @@
+CREATE TABLE IF NOT EXISTS `sessions` (`id` text,`data` blob NOT NULL,`expires_at` datetime,`updated_at` datetime,PRIMARY KEY (`id`));
+CREATE INDEX IF NOT EXISTS `idx_sessions_expires_at` ON `sessions`(`expires_at`);
@@ -0,0 +1,3 @@
+CREATE TABLE IF NOT EXISTS `user_sessions` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`session_id` text NOT NULL,`ip_address` text,`user_agent` text,`created_at` datetime,`last_seen_at` datetime,CONSTRAINT `fk_user_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_user_sessions_session_id` ON `user_sessions`(`session_id`);
+CREATE INDEX IF NOT EXISTS `idx_user_sessions_user_id` ON `user_sessions`(`user_id`);
@@ -0,0 +1,9 @@
+-- SQLite tidak bisa DROP COLUMN yang punya foreign key, jadi tabel dibangun ulang
+CREATE TABLE `users__old` (`id` integer PRIMARY KEY AUTOINCREMENT,`username` text NOT NULL,`email` text NOT NULL,`password` text NOT NULL,`first_name` text,`last_name` text,`is_staff` numeric DEFAULT false,`is_active` numeric DEFAULT true,`last_login` datetime,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime);
+INSERT INTO `users__old` (`id`,`username`,`email`,`password`,`first_name`,`last_name`,`is_staff`,`is_active`,`last_login`,`created_at`,`updated_at`,`deleted_at`)
+SELECT `id`,`username`,`email`,`password`,`first_name`,`last_name`,`is_staff`,`is_active`,`last_login`,`created_at`,`updated_at`,`deleted_at` FROM `users`;
+DROP TABLE `users`;
+ALTER TABLE `users__old` RENAME TO `users`;
+CREATE INDEX `idx_users_deleted_at` ON `users`(`deleted_at`);
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+CREATE UNIQUE INDEX `idx_users_username` ON `users`(`username`);
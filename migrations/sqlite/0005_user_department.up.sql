@@ -0,0 +1 @@
+ALTER TABLE `users` ADD COLUMN `department_id` integer REFERENCES `departments`(`id`);
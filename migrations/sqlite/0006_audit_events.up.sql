@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS `audit_events` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`action` text NOT NULL,`actor_id` integer,`actor_username` text,`ip_address` text,`user_agent` text,`target_type` text,`target_id` text,`changes` text);
+CREATE INDEX IF NOT EXISTS `idx_audit_target` ON `audit_events`(`target_type`,`target_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_events_actor_id` ON `audit_events`(`actor_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_events_action` ON `audit_events`(`action`);
+CREATE INDEX IF NOT EXISTS `idx_audit_events_created_at` ON `audit_events`(`created_at`);
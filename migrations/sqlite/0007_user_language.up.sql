@@ -0,0 +1 @@
+ALTER TABLE `users` ADD COLUMN `language` text NOT NULL DEFAULT '';
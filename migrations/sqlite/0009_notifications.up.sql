@@ -0,0 +1,3 @@
+CREATE TABLE IF NOT EXISTS `notifications` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`ticket_id` integer NOT NULL,`kind` text NOT NULL,`detail` text,`dedupe_key` text NOT NULL,`read_at` datetime,`created_at` datetime,CONSTRAINT `fk_notifications_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),CONSTRAINT `fk_notifications_ticket` FOREIGN KEY (`ticket_id`) REFERENCES `tickets`(`id`));
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_notifications_dedupe` ON `notifications`(`user_id`,`dedupe_key`);
+CREATE INDEX IF NOT EXISTS `idx_notifications_unread` ON `notifications`(`user_id`,`read_at`);
@@ -0,0 +1,3 @@
+CREATE TABLE IF NOT EXISTS `notification_preferences` (`user_id` integer PRIMARY KEY,`email_created` numeric NOT NULL DEFAULT true,`email_reply` numeric NOT NULL DEFAULT true,`email_status` numeric NOT NULL DEFAULT true,`email_assignment` numeric NOT NULL DEFAULT true,`in_app_reply` numeric NOT NULL DEFAULT true,`in_app_status` numeric NOT NULL DEFAULT true,`in_app_assignment` numeric NOT NULL DEFAULT true,`webhook_created` numeric NOT NULL DEFAULT false,`webhook_reply` numeric NOT NULL DEFAULT false,`webhook_status` numeric NOT NULL DEFAULT false,`webhook_assignment` numeric NOT NULL DEFAULT false,`webhook_url` text NOT NULL DEFAULT '',`daily_digest` numeric NOT NULL DEFAULT false,`digest_sent_at` datetime,`updated_at` datetime,CONSTRAINT `fk_notification_preferences_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+CREATE INDEX IF NOT EXISTS `idx_notification_preferences_digest` ON `notification_preferences`(`daily_digest`,`digest_sent_at`);
+ALTER TABLE `notifications` ADD COLUMN `in_app` numeric NOT NULL DEFAULT true;
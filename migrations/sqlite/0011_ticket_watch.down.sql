@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS `idx_tickets_updated_at_id`;
+DROP TABLE IF EXISTS `ticket_watch_states`;
+DROP TABLE IF EXISTS `ticket_watch_cursors`;
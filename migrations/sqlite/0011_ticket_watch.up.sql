@@ -0,0 +1,3 @@
+CREATE TABLE IF NOT EXISTS `ticket_watch_cursors` (`name` text PRIMARY KEY,`reply_id` integer NOT NULL,`ticket_updated_at` datetime NOT NULL,`ticket_id` integer NOT NULL);
+CREATE TABLE IF NOT EXISTS `ticket_watch_states` (`ticket_id` integer PRIMARY KEY,`status` text,`department_id` integer,`seen_at` datetime NOT NULL,`status_changed` numeric NOT NULL DEFAULT false,`department_changed` numeric NOT NULL DEFAULT false,`version` integer NOT NULL);
+CREATE INDEX IF NOT EXISTS `idx_tickets_updated_at_id` ON `tickets`(`updated_at`,`id`);
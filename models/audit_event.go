@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Jenis aksi yang dicatat di audit log
+const (
+	AuditLogin          = "auth.login"
+	AuditLoginFailed    = "auth.login_failed"
+	AuditLogout         = "auth.logout"
+	AuditRegister       = "user.register"
+	AuditProfileUpdate  = "user.profile_update"
+	AuditPasswordChange = "user.password_change"
+	AuditSessionRevoke  = "user.session_revoke"
+	AuditNotifications  = "user.notification_preferences"
+	AuditTicketCreate   = "ticket.create"
+	AuditTicketReply    = "ticket.reply"
+	// AuditTicketStatus dan AuditTicketAssignment perubahan dari luar
+	// aplikasi (panel staff, termasuk menutup tiket) yang dideteksi
+	// TicketWatcher, sehingga tidak punya actor
+	AuditTicketStatus     = "ticket.status_change"
+	AuditTicketAssignment = "ticket.assignment"
+)
+
+// AuditEvent catatan permanen aksi penting pada akun dan tiket
+type AuditEvent struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+	Action        string    `gorm:"size:64;not null;index" json:"action"`
+	ActorID       *uint     `gorm:"index" json:"actor_id"`
+	ActorUsername string    `gorm:"size:255" json:"actor_username"`
+	IPAddress     string    `gorm:"size:64" json:"ip_address"`
+	UserAgent     string    `gorm:"size:512" json:"user_agent"`
+	TargetType    string    `gorm:"size:64;index:idx_audit_target" json:"target_type"`
+	TargetID      string    `gorm:"size:64;index:idx_audit_target" json:"target_id"`
+	// Changes berisi JSON {"field": {"before": ..., "after": ...}}
+	Changes string `gorm:"type:text" json:"changes"`
+}
+
+// GetActionDisplay label aksi dalam bahasa lang untuk halaman admin
+func (e *AuditEvent) GetActionDisplay(lang string) string {
+	return displayName(lang, "audit.action.", e.Action)
+}
+
+// AuditActions daftar semua aksi untuk filter
+func AuditActions() []string {
+	return []string{
+		AuditLogin,
+		AuditLoginFailed,
+		AuditLogout,
+		AuditRegister,
+		AuditProfileUpdate,
+		AuditPasswordChange,
+		AuditSessionRevoke,
+		AuditNotifications,
+		AuditTicketCreate,
+		AuditTicketReply,
+		AuditTicketStatus,
+		AuditTicketAssignment,
+	}
+}
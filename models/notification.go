@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"ticketing-fiber/i18n"
+)
+
+type NotificationKind string
+
+const (
+	// NotificationCreated tiket baru dibuat; hanya dikirim lewat email dan
+	// webhook, tidak pernah dicatat sebagai notifikasi di aplikasi
+	NotificationCreated    NotificationKind = "created"
+	NotificationReply      NotificationKind = "reply"
+	NotificationStatus     NotificationKind = "status"
+	NotificationAssignment NotificationKind = "assignment"
+)
+
+// Notification pemberitahuan di aplikasi untuk pemilik tiket
+type Notification struct {
+	ID       uint             `gorm:"primarykey" json:"id"`
+	UserID   uint             `gorm:"not null;uniqueIndex:idx_notifications_dedupe;index:idx_notifications_unread" json:"user_id"`
+	TicketID uint             `gorm:"not null" json:"ticket_id"`
+	Kind     NotificationKind `gorm:"size:20;not null" json:"kind"`
+	// Detail nilai pelengkap pesan: nama pembalas (reply), status baru
+	// (status) atau nama departemen (assignment)
+	Detail string `gorm:"size:255" json:"detail"`
+	// DedupeKey mencegah notifikasi ganda untuk perubahan yang sama jika
+	// beberapa instance aplikasi mendeteksinya
+	DedupeKey string     `gorm:"size:191;not null;uniqueIndex:idx_notifications_dedupe" json:"-"`
+	ReadAt    *time.Time `gorm:"index:idx_notifications_unread" json:"read_at"`
+	// InApp false jika user mematikan notifikasi di aplikasi untuk jenis ini;
+	// notifikasinya tetap disimpan untuk email ringkasan
+	InApp     bool      `gorm:"not null" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relations
+	Ticket Ticket `gorm:"foreignKey:TicketID" json:"-"`
+}
+
+// IsRead true jika notifikasi sudah dibaca
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// Message isi notifikasi dalam bahasa lang
+func (n *Notification) Message(lang string) string {
+	switch n.Kind {
+	case NotificationCreated:
+		return i18n.T(lang, "notification.created", n.TicketID)
+	case NotificationReply:
+		return i18n.T(lang, "notification.reply", n.Detail, n.TicketID)
+	case NotificationStatus:
+		return i18n.T(lang, "notification.status", n.TicketID, displayName(lang, "ticket.status.", n.Detail))
+	case NotificationAssignment:
+		department := n.Detail
+		if department == "" {
+			department = i18n.T(lang, "ticket.department_none")
+		}
+		return i18n.T(lang, "notification.assignment", n.TicketID, department)
+	}
+	return i18n.T(lang, "notification.generic", n.TicketID)
+}
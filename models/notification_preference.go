@@ -0,0 +1,136 @@
+package models
+
+import "time"
+
+type NotificationChannel string
+
+const (
+	ChannelEmail   NotificationChannel = "email"
+	ChannelInApp   NotificationChannel = "in_app"
+	ChannelWebhook NotificationChannel = "webhook"
+)
+
+// NotificationKinds jenis notifikasi yang bisa diatur user, sesuai urutan
+// di halaman settings
+var NotificationKinds = []NotificationKind{
+	NotificationCreated,
+	NotificationReply,
+	NotificationStatus,
+	NotificationAssignment,
+}
+
+// NotificationChannels saluran pengiriman notifikasi
+var NotificationChannels = []NotificationChannel{ChannelEmail, ChannelInApp, ChannelWebhook}
+
+// NotificationPreference pilihan notifikasi satu user per jenis dan saluran.
+// User tanpa baris di tabel memakai DefaultNotificationPreference.
+type NotificationPreference struct {
+	UserID uint `gorm:"primarykey;autoIncrement:false" json:"user_id"`
+
+	EmailCreated    bool `gorm:"not null" json:"email_created"`
+	EmailReply      bool `gorm:"not null" json:"email_reply"`
+	EmailStatus     bool `gorm:"not null" json:"email_status"`
+	EmailAssignment bool `gorm:"not null" json:"email_assignment"`
+
+	InAppReply      bool `gorm:"not null" json:"in_app_reply"`
+	InAppStatus     bool `gorm:"not null" json:"in_app_status"`
+	InAppAssignment bool `gorm:"not null" json:"in_app_assignment"`
+
+	WebhookCreated    bool   `gorm:"not null" json:"webhook_created"`
+	WebhookReply      bool   `gorm:"not null" json:"webhook_reply"`
+	WebhookStatus     bool   `gorm:"not null" json:"webhook_status"`
+	WebhookAssignment bool   `gorm:"not null" json:"webhook_assignment"`
+	WebhookURL        string `gorm:"size:500;not null;default:''" json:"webhook_url"`
+
+	// DailyDigest mengganti email per balasan, status dan pengalihan dengan
+	// satu email ringkasan; DigestSentAt batas awal ringkasan berikutnya
+	DailyDigest  bool       `gorm:"not null;index:idx_notification_preferences_digest" json:"daily_digest"`
+	DigestSentAt *time.Time `gorm:"index:idx_notification_preferences_digest" json:"digest_sent_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// DefaultNotificationPreference email dan notifikasi di aplikasi aktif,
+// webhook mati
+func DefaultNotificationPreference(userID uint) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:          userID,
+		EmailCreated:    true,
+		EmailReply:      true,
+		EmailStatus:     true,
+		EmailAssignment: true,
+		InAppReply:      true,
+		InAppStatus:     true,
+		InAppAssignment: true,
+	}
+}
+
+// Subscribed pilihan user untuk jenis dan saluran; false jika kombinasi
+// tersebut tidak tersedia (misalnya tiket dibuat di aplikasi)
+func (p *NotificationPreference) Subscribed(kind NotificationKind, channel NotificationChannel) bool {
+	if field := p.field(kind, channel); field != nil {
+		return *field
+	}
+	return false
+}
+
+// Available true jika jenis notifikasi bisa dikirim lewat channel
+func (p *NotificationPreference) Available(kind NotificationKind, channel NotificationChannel) bool {
+	return p.field(kind, channel) != nil
+}
+
+// Set mengubah pilihan user; kombinasi yang tidak tersedia diabaikan
+func (p *NotificationPreference) Set(kind NotificationKind, channel NotificationChannel, enabled bool) {
+	if field := p.field(kind, channel); field != nil {
+		*field = enabled
+	}
+}
+
+// Enabled true jika notifikasi perlu dikirim langsung lewat channel. Email
+// balasan, status dan pengalihan tidak dikirim langsung dalam mode ringkasan,
+// dan webhook butuh URL.
+func (p *NotificationPreference) Enabled(kind NotificationKind, channel NotificationChannel) bool {
+	switch {
+	case channel == ChannelEmail && p.DailyDigest && kind != NotificationCreated:
+		return false
+	case channel == ChannelWebhook && p.WebhookURL == "":
+		return false
+	}
+	return p.Subscribed(kind, channel)
+}
+
+func (p *NotificationPreference) field(kind NotificationKind, channel NotificationChannel) *bool {
+	switch channel {
+	case ChannelEmail:
+		switch kind {
+		case NotificationCreated:
+			return &p.EmailCreated
+		case NotificationReply:
+			return &p.EmailReply
+		case NotificationStatus:
+			return &p.EmailStatus
+		case NotificationAssignment:
+			return &p.EmailAssignment
+		}
+	case ChannelInApp:
+		switch kind {
+		case NotificationReply:
+			return &p.InAppReply
+		case NotificationStatus:
+			return &p.InAppStatus
+		case NotificationAssignment:
+			return &p.InAppAssignment
+		}
+	case ChannelWebhook:
+		switch kind {
+		case NotificationCreated:
+			return &p.WebhookCreated
+		case NotificationReply:
+			return &p.WebhookReply
+		case NotificationStatus:
+			return &p.WebhookStatus
+		case NotificationAssignment:
+			return &p.WebhookAssignment
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import "testing"
+
+func TestNotificationPreferenceEnabled(t *testing.T) {
+	tests := []struct {
+		name      string
+		preferred func(p *NotificationPreference)
+		kind      NotificationKind
+		channel   NotificationChannel
+		want      bool
+	}{
+		{"default reply email", nil, NotificationReply, ChannelEmail, true},
+		{"default status in app", nil, NotificationStatus, ChannelInApp, true},
+		{"default webhook off", nil, NotificationStatus, ChannelWebhook, false},
+		{"created has no in-app notification", nil, NotificationCreated, ChannelInApp, false},
+		{"unknown kind", nil, NotificationKind("closed"), ChannelEmail, false},
+		{"email turned off", func(p *NotificationPreference) { p.EmailAssignment = false }, NotificationAssignment, ChannelEmail, false},
+		{"digest holds reply email", func(p *NotificationPreference) { p.DailyDigest = true }, NotificationReply, ChannelEmail, false},
+		{"digest holds status email", func(p *NotificationPreference) { p.DailyDigest = true }, NotificationStatus, ChannelEmail, false},
+		{"digest keeps created email", func(p *NotificationPreference) { p.DailyDigest = true }, NotificationCreated, ChannelEmail, true},
+		{"digest keeps in app", func(p *NotificationPreference) { p.DailyDigest = true }, NotificationReply, ChannelInApp, true},
+		{"webhook without URL", func(p *NotificationPreference) { p.WebhookReply = true }, NotificationReply, ChannelWebhook, false},
+		{"webhook with URL", func(p *NotificationPreference) {
+			p.WebhookReply = true
+			p.WebhookURL = "https://hooks.example.com/"
+		}, NotificationReply, ChannelWebhook, true},
+		{"webhook with URL but kind off", func(p *NotificationPreference) {
+			p.WebhookReply = true
+			p.WebhookURL = "https://hooks.example.com/"
+		}, NotificationStatus, ChannelWebhook, false},
+		{"webhook with digest", func(p *NotificationPreference) {
+			p.WebhookStatus = true
+			p.WebhookURL = "https://hooks.example.com/"
+			p.DailyDigest = true
+		}, NotificationStatus, ChannelWebhook, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultNotificationPreference(1)
+			if tt.preferred != nil {
+				tt.preferred(p)
+			}
+			if got := p.Enabled(tt.kind, tt.channel); got != tt.want {
+				t.Errorf("Enabled(%s, %s) = %v; want %v", tt.kind, tt.channel, got, tt.want)
+			}
+		})
+	}
+}
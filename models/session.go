@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Session menyimpan data session Fiber yang sudah di-encode (gob) di database
+type Session struct {
+	ID        string     `gorm:"primarykey;size:128" json:"id"`
+	Data      []byte     `gorm:"not null" json:"-"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// IsExpired mengecek apakah session sudah kedaluwarsa pada waktu now
+func (s *Session) IsExpired(now time.Time) bool {
+	return s.ExpiresAt != nil && !s.ExpiresAt.After(now)
+}
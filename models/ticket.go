@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"ticketing-fiber/i18n"
 )
 
 type TicketStatus string
@@ -38,30 +40,35 @@ type Ticket struct {
 	Replies    []TicketReply `gorm:"foreignKey:TicketID" json:"replies"`
 }
 
-func (t *Ticket) GetStatusDisplay() string {
+// GetStatusDisplay nama status dalam bahasa lang
+func (t *Ticket) GetStatusDisplay(lang string) string {
+	return displayName(lang, "ticket.status.", string(t.Status))
+}
+
+// GetPriorityDisplay nama prioritas dalam bahasa lang
+func (t *Ticket) GetPriorityDisplay(lang string) string {
+	return displayName(lang, "ticket.priority.", string(t.Priority))
+}
+
+// GetStatusClass class CSS badge status: open, in-progress atau closed
+func (t *Ticket) GetStatusClass() string {
 	switch t.Status {
 	case StatusWaiting:
-		return "Menunggu Balasan"
+		return "open"
 	case StatusInProgress:
-		return "In Progress"
-	case StatusClosed:
-		return "Closed"
-	default:
-		return string(t.Status)
+		return "in-progress"
 	}
+	return "closed"
 }
 
-func (t *Ticket) GetPriorityDisplay() string {
-	switch t.Priority {
-	case PriorityLow:
-		return "Low"
-	case PriorityMedium:
-		return "Medium"
-	case PriorityHigh:
-		return "High"
-	default:
-		return string(t.Priority)
+// displayName terjemahan prefix+value, atau value apa adanya jika tidak ada
+// di katalog
+func displayName(lang, prefix, value string) string {
+	key := prefix + value
+	if message := i18n.T(lang, key); message != key {
+		return message
 	}
+	return value
 }
 
 func (t *Ticket) GetReplyCount() int {
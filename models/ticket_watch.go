@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// TicketWatchCursor posisi terakhir pengecekan TicketWatcher yang disimpan
+// di database: balasan dengan ID lebih besar dari ReplyID dan tiket dengan
+// (updated_at, id) setelah (TicketUpdatedAt, TicketID) belum diproses
+type TicketWatchCursor struct {
+	Name            string    `gorm:"primaryKey;size:64"`
+	ReplyID         uint      `gorm:"not null"`
+	TicketUpdatedAt time.Time `gorm:"not null"`
+	TicketID        uint      `gorm:"not null"`
+}
+
+// TicketWatchState keadaan tiket yang terakhir diproses TicketWatcher,
+// pembanding untuk mendeteksi perubahan status dan departemen
+type TicketWatchState struct {
+	TicketID     uint         `gorm:"primaryKey;autoIncrement:false"`
+	Status       TicketStatus `gorm:"size:32"`
+	DepartmentID *uint
+	// SeenAt updated_at tiket saat keadaan ini dicatat. StatusChanged dan
+	// DepartmentChanged menandai perubahan yang terdeteksi pada versi itu,
+	// agar instance lain yang belum memprosesnya tetap bisa mempublikasikan
+	// event ke client-nya sendiri.
+	SeenAt            time.Time `gorm:"not null"`
+	StatusChanged     bool      `gorm:"not null"`
+	DepartmentChanged bool      `gorm:"not null"`
+	// Version naik setiap kali disimpan, untuk compare-and-set antar instance
+	Version uint `gorm:"not null"`
+}
@@ -7,23 +7,27 @@ import (
 )
 
 type User struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	Username  string         `gorm:"uniqueIndex;not null" json:"username"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
-	Password  string         `gorm:"not null" json:"-"`
-	FirstName string         `json:"first_name"`
-	LastName  string         `json:"last_name"`
-	IsStaff   bool           `gorm:"default:false" json:"is_staff"`
-	IsActive  bool           `gorm:"default:true" json:"is_active"`
-	LastLogin *time.Time     `json:"last_login"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uint           `gorm:"primarykey" json:"id"`
+	Username     string         `gorm:"uniqueIndex;not null" json:"username"`
+	Email        string         `gorm:"uniqueIndex;not null" json:"email"`
+	Password     string         `gorm:"not null" json:"-"`
+	FirstName    string         `json:"first_name"`
+	LastName     string         `json:"last_name"`
+	IsStaff      bool           `gorm:"default:false" json:"is_staff"`
+	IsActive     bool           `gorm:"default:true" json:"is_active"`
+	DepartmentID *uint          `json:"department_id"`
+	LastLogin    *time.Time     `json:"last_login"`
+	Language     string         `gorm:"size:8;not null;default:''" json:"language"`
+	TimeZone     string         `gorm:"size:64;not null;default:''" json:"time_zone"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relations
-	Tickets []Ticket      `gorm:"foreignKey:CreatedByID" json:"-"`
-	Replies []TicketReply `gorm:"foreignKey:UserID" json:"-"`
-	Groups  []Group       `gorm:"many2many:user_groups;" json:"-"`
+	Department *Department   `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+	Tickets    []Ticket      `gorm:"foreignKey:CreatedByID" json:"-"`
+	Replies    []TicketReply `gorm:"foreignKey:UserID" json:"-"`
+	Groups     []Group       `gorm:"many2many:user_groups;" json:"-"`
 }
 
 type Group struct {
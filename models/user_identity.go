@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// UserIdentity menghubungkan user lokal dengan akun di identity provider eksternal (OIDC)
+type UserIdentity struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Provider    string     `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject" json:"provider"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject" json:"subject"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
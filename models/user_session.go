@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"ticketing-fiber/i18n"
+)
+
+// UserSession menyimpan metadata perangkat untuk setiap session login user
+type UserSession struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	SessionID  string    `gorm:"uniqueIndex;size:128;not null" json:"-"`
+	IPAddress  string    `gorm:"size:64" json:"ip_address"`
+	UserAgent  string    `gorm:"size:512" json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// GetDeviceDisplay mengembalikan deskripsi singkat browser dan sistem operasi
+func (s *UserSession) GetDeviceDisplay(lang string) string {
+	ua := s.UserAgent
+	if ua == "" {
+		return i18n.T(lang, "session.unknown_device")
+	}
+
+	browser := i18n.T(lang, "session.other_browser")
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"), strings.Contains(ua, "Opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		os = "iOS"
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		os = "macOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+
+	if os == "" {
+		return browser
+	}
+	return i18n.T(lang, "session.device_on", browser, os)
+}
@@ -0,0 +1,173 @@
+// Package realtime berisi hub publish/subscribe di dalam proses untuk event
+// tiket (balasan baru, perubahan status dan departemen). Event dikirim ke
+// topic per tiket dan per pemilik tiket; handler stream /events berlangganan
+// topic yang boleh dilihat user lalu meneruskannya ke browser.
+package realtime
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"ticketing-fiber/models"
+)
+
+// ErrClosed dikembalikan Subscribe setelah Close dipanggil
+var ErrClosed = errors.New("realtime hub is closed")
+
+// subscriptionBuffer jumlah event yang bisa antre per subscriber sebelum
+// event berikutnya dibuang
+const subscriptionBuffer = 16
+
+type EventType string
+
+const (
+	EventReply      EventType = "reply"
+	EventStatus     EventType = "status"
+	EventAssignment EventType = "assignment"
+)
+
+// Event perubahan pada satu tiket. Reply (beserta User) hanya terisi untuk
+// EventReply, Status untuk EventStatus dan Department (nama departemen baru)
+// untuk EventAssignment.
+type Event struct {
+	Type       EventType
+	TicketID   uint
+	OwnerID    uint
+	Reply      *models.TicketReply
+	Status     models.TicketStatus
+	Department string
+	// ChangedAt waktu perubahan (created_at balasan atau updated_at tiket)
+	ChangedAt time.Time
+}
+
+const (
+	ticketTopicPrefix = "ticket:"
+	userTopicPrefix   = "user:"
+)
+
+// TicketTopic topic event satu tiket
+func TicketTopic(ticketID uint) string {
+	return ticketTopicPrefix + strconv.FormatUint(uint64(ticketID), 10)
+}
+
+// UserTopic topic event semua tiket milik user
+func UserTopic(userID uint) string {
+	return userTopicPrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+// Topics topic tempat event dipublikasikan: tiketnya dan pemilik tiket
+func (e Event) Topics() []string {
+	return []string{TicketTopic(e.TicketID), UserTopic(e.OwnerID)}
+}
+
+// Hub meneruskan event ke semua subscription yang berlangganan topic-nya.
+// Publish tidak pernah menunggu subscriber yang lambat.
+type Hub struct {
+	mu     sync.RWMutex
+	closed bool
+	topics map[string]map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{topics: make(map[string]map[*Subscription]struct{})}
+}
+
+// Subscription langganan ke satu atau lebih topic; channel Events ditutup
+// saat Close dipanggil atau hub ditutup
+type Subscription struct {
+	hub    *Hub
+	topics []string
+	events chan Event
+	closed bool
+}
+
+// Events channel event untuk subscription ini
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close berhenti berlangganan; aman dipanggil berulang kali
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Subscribe berlangganan topics
+func (h *Hub) Subscribe(topics ...string) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrClosed
+	}
+	sub := &Subscription{
+		hub:    h,
+		topics: topics,
+		events: make(chan Event, subscriptionBuffer),
+	}
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*Subscription]struct{})
+		}
+		h.topics[topic][sub] = struct{}{}
+	}
+	return sub, nil
+}
+
+// Publish mengirim event ke subscriber topic-topic event. Subscription yang
+// berlangganan beberapa topic tersebut tetap hanya menerima satu kali.
+func (h *Hub) Publish(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.closed {
+		return
+	}
+	delivered := make(map[*Subscription]struct{})
+	for _, topic := range event.Topics() {
+		for sub := range h.topics[topic] {
+			if _, ok := delivered[sub]; ok {
+				continue
+			}
+			delivered[sub] = struct{}{}
+			select {
+			case sub.events <- event:
+			default:
+				slog.Warn("realtime event dropped, subscriber too slow", "type", event.Type, "ticket_id", event.TicketID)
+			}
+		}
+	}
+}
+
+// Close menutup semua subscription sehingga stream yang terbuka selesai;
+// dipanggil sebelum server berhenti menerima koneksi
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.topics {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove melepas sub dari semua topic-nya; h.mu harus sudah dikunci
+func (h *Hub) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	for _, topic := range sub.topics {
+		delete(h.topics[topic], sub)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	close(sub.events)
+}
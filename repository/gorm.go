@@ -0,0 +1,662 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"ticketing-fiber/models"
+	"ticketing-fiber/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type gormTicketRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTicketRepository(db *gorm.DB) TicketRepository {
+	return &gormTicketRepository{db: db}
+}
+
+func (r *gormTicketRepository) Create(ctx context.Context, ticket *models.Ticket) error {
+	return r.db.WithContext(ctx).Create(ticket).Error
+}
+
+func (r *gormTicketRepository) FindByID(ctx context.Context, id uint) (*models.Ticket, error) {
+	var ticket models.Ticket
+	if err := r.db.WithContext(ctx).Preload("Department").First(&ticket, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &ticket, nil
+}
+
+func (r *gormTicketRepository) FindOwned(ctx context.Context, id, ownerID uint) (*models.Ticket, error) {
+	var ticket models.Ticket
+	if err := r.db.WithContext(ctx).
+		Preload("CreatedBy").
+		Preload("Department").
+		Preload("Replies.User").
+		Where("id = ? AND created_by_id = ?", id, ownerID).
+		First(&ticket).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &ticket, nil
+}
+
+func (r *gormTicketRepository) List(ctx context.Context, filter TicketFilter) ([]*models.Ticket, error) {
+	query := r.db.WithContext(ctx).
+		Preload("Department").
+		Preload("Replies")
+
+	if filter.CreatedByID != 0 {
+		query = query.Where("created_by_id = ?", filter.CreatedByID)
+	}
+
+	if filter.Search != "" {
+		pattern := utils.ContainsPattern(filter.Search)
+		textMatch := utils.ILikeClause("title") + " OR " + utils.ILikeClause("description")
+		if ticketID, err := strconv.Atoi(filter.Search); err == nil {
+			query = query.Where("id = ? OR "+textMatch, ticketID, pattern, pattern)
+		} else {
+			query = query.Where(textMatch, pattern, pattern)
+		}
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var tickets []*models.Ticket
+	if err := query.Order("created_at DESC").Find(&tickets).Error; err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (r *gormTicketRepository) CountByStatus(ctx context.Context, ownerID uint) (map[models.TicketStatus]int64, error) {
+	var rows []struct {
+		Status models.TicketStatus
+		Count  int64
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Ticket{}).
+		Select("status, COUNT(*) AS count").
+		Where("created_by_id = ?", ownerID).
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.TicketStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *gormTicketRepository) CountOpen(ctx context.Context) ([]TicketCount, error) {
+	var counts []TicketCount
+	err := r.db.WithContext(ctx).Model(&models.Ticket{}).
+		Select("tickets.status AS status, COALESCE(departments.name, '') AS department, COUNT(*) AS count").
+		Joins("LEFT JOIN departments ON departments.id = tickets.department_id").
+		Where("tickets.status <> ?", models.StatusClosed).
+		Group("tickets.status, departments.name").
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *gormTicketRepository) AddReply(ctx context.Context, reply *models.TicketReply) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reply).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Ticket{}).
+			Where("id = ?", reply.TicketID).
+			Update("updated_at", time.Now().UTC()).Error
+	})
+}
+
+func (r *gormTicketRepository) LastReplyID(ctx context.Context) (uint, error) {
+	var id uint
+	err := r.db.WithContext(ctx).Model(&models.TicketReply{}).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&id).Error
+	return id, err
+}
+
+func (r *gormTicketRepository) RepliesAfter(ctx context.Context, afterID uint, limit int) ([]*models.TicketReply, error) {
+	query := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Ticket").
+		Where("id > ?", afterID)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var replies []*models.TicketReply
+	err := query.Order("id").Find(&replies).Error
+	return replies, err
+}
+
+func (r *gormTicketRepository) ChangedSince(ctx context.Context, updatedAt time.Time, afterID uint, limit int) ([]*models.Ticket, error) {
+	query := r.db.WithContext(ctx).
+		Select("id", "created_by_id", "status", "department_id", "updated_at").
+		Where("updated_at > ? OR (updated_at = ? AND id > ?)", updatedAt, updatedAt, afterID)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var tickets []*models.Ticket
+	err := query.Order("updated_at, id").Find(&tickets).Error
+	return tickets, err
+}
+
+// ticketWatchCursorName nama baris cursor TicketWatcher
+const ticketWatchCursorName = "tickets"
+
+type gormTicketWatchRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTicketWatchRepository(db *gorm.DB) TicketWatchRepository {
+	return &gormTicketWatchRepository{db: db}
+}
+
+func (r *gormTicketWatchRepository) Cursor(ctx context.Context) (*models.TicketWatchCursor, error) {
+	var cursor models.TicketWatchCursor
+	err := r.db.WithContext(ctx).Where("name = ?", ticketWatchCursorName).First(&cursor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &cursor, err
+}
+
+func (r *gormTicketWatchRepository) Seed(ctx context.Context) (*models.TicketWatchCursor, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		cursor := models.TicketWatchCursor{Name: ticketWatchCursorName}
+		err := tx.Model(&models.TicketReply{}).
+			Select("COALESCE(MAX(id), 0)").
+			Scan(&cursor.ReplyID).Error
+		if err != nil {
+			return err
+		}
+		var last []models.Ticket
+		err = tx.Select("id", "updated_at").
+			Order("updated_at DESC, id DESC").
+			Limit(1).
+			Find(&last).Error
+		if err != nil {
+			return err
+		}
+		if len(last) > 0 {
+			cursor.TicketUpdatedAt, cursor.TicketID = last[0].UpdatedAt, last[0].ID
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&cursor)
+		if result.Error != nil || result.RowsAffected == 0 {
+			// Sudah di-seed instance lain
+			return result.Error
+		}
+		return tx.Exec(`INSERT INTO ticket_watch_states (ticket_id, status, department_id, seen_at, status_changed, department_changed, version)
+			SELECT id, status, department_id, updated_at, ?, ?, 1 FROM tickets WHERE deleted_at IS NULL`, false, false).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Cursor(ctx)
+}
+
+func (r *gormTicketWatchRepository) Advance(ctx context.Context, cursor models.TicketWatchCursor) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.TicketWatchCursor{}).
+			Where("name = ? AND reply_id < ?", ticketWatchCursorName, cursor.ReplyID).
+			Update("reply_id", cursor.ReplyID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.TicketWatchCursor{}).
+			Where("name = ? AND (ticket_updated_at < ? OR (ticket_updated_at = ? AND ticket_id < ?))",
+				ticketWatchCursorName, cursor.TicketUpdatedAt, cursor.TicketUpdatedAt, cursor.TicketID).
+			Updates(map[string]interface{}{
+				"ticket_updated_at": cursor.TicketUpdatedAt,
+				"ticket_id":         cursor.TicketID,
+			}).Error
+	})
+}
+
+func (r *gormTicketWatchRepository) States(ctx context.Context, ticketIDs []uint) (map[uint]*models.TicketWatchState, error) {
+	states := make(map[uint]*models.TicketWatchState, len(ticketIDs))
+	if len(ticketIDs) == 0 {
+		return states, nil
+	}
+
+	var rows []*models.TicketWatchState
+	if err := r.db.WithContext(ctx).Where("ticket_id IN ?", ticketIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, state := range rows {
+		states[state.TicketID] = state
+	}
+	return states, nil
+}
+
+func (r *gormTicketWatchRepository) SaveState(ctx context.Context, state *models.TicketWatchState) (bool, error) {
+	next := *state
+	next.Version = state.Version + 1
+
+	var result *gorm.DB
+	if state.Version == 0 {
+		result = r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&next)
+	} else {
+		// Select("*") agar nilai false dan nil ikut ditulis
+		result = r.db.WithContext(ctx).Model(&models.TicketWatchState{}).
+			Where("ticket_id = ? AND version = ?", state.TicketID, state.Version).
+			Select("*").
+			Updates(&next)
+	}
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	state.Version = next.Version
+	return true, nil
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func NewGormUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Preload("Groups").First(&user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Preload("Groups").Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Preload("Groups").Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByLogin(ctx context.Context, login string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Preload("Groups").
+		Where("username = ? OR email = ?", login, login).
+		First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) UsernameTaken(ctx context.Context, username string, excludeID uint) (bool, error) {
+	return r.exists(ctx, "username = ? AND id != ?", username, excludeID)
+}
+
+func (r *gormUserRepository) EmailTaken(ctx context.Context, email string, excludeID uint) (bool, error) {
+	return r.exists(ctx, "email = ? AND id != ?", email, excludeID)
+}
+
+func (r *gormUserRepository) exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Unscoped().Where(query, args...).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *gormUserRepository) Save(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
+func (r *gormUserRepository) UpdatePassword(ctx context.Context, id uint, hash string) error {
+	return r.db.WithContext(ctx).Model(&models.User{ID: id}).Update("password", hash).Error
+}
+
+func (r *gormUserRepository) AddToGroup(ctx context.Context, user *models.User, groupName string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		if err := tx.FirstOrCreate(&group, models.Group{Name: groupName}).Error; err != nil {
+			return err
+		}
+		return tx.Model(user).Association("Groups").Append(&group)
+	})
+}
+
+func (r *gormUserRepository) RemoveFromGroup(ctx context.Context, user *models.User, groupName string) error {
+	var group models.Group
+	err := r.db.WithContext(ctx).Where("name = ?", groupName).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(user).Association("Groups").Delete(&group)
+}
+
+type gormIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewGormIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &gormIdentityRepository{db: db}
+}
+
+func (r *gormIdentityRepository) Find(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &identity, nil
+}
+
+func (r *gormIdentityRepository) FindForUser(ctx context.Context, userID uint, provider string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		First(&identity).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &identity, nil
+}
+
+func (r *gormIdentityRepository) Save(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Save(identity).Error
+}
+
+type gormSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSessionRepository(db *gorm.DB) SessionRepository {
+	return &gormSessionRepository{db: db}
+}
+
+func (r *gormSessionRepository) Create(ctx context.Context, session *models.UserSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *gormSessionRepository) ListForUser(ctx context.Context, userID uint) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *gormSessionRepository) FindForUser(ctx context.Context, id, userID uint) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &session, nil
+}
+
+func (r *gormSessionRepository) Touch(ctx context.Context, sessionID string, now time.Time, minInterval time.Duration) error {
+	return r.db.WithContext(ctx).Model(&models.UserSession{}).
+		Where("session_id = ? AND last_seen_at < ?", sessionID, now.Add(-minInterval)).
+		Update("last_seen_at", now).Error
+}
+
+func (r *gormSessionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.UserSession{}, id).Error
+}
+
+func (r *gormSessionRepository) DeleteBySessionID(ctx context.Context, sessionID string) error {
+	return r.db.WithContext(ctx).Where("session_id = ?", sessionID).Delete(&models.UserSession{}).Error
+}
+
+type gormAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAuditRepository(db *gorm.DB) AuditRepository {
+	return &gormAuditRepository{db: db}
+}
+
+func (r *gormAuditRepository) Create(ctx context.Context, event *models.AuditEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *gormAuditRepository) query(ctx context.Context, filter AuditFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.AuditEvent{})
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor_username = ?", filter.Actor)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+	return query
+}
+
+func (r *gormAuditRepository) List(ctx context.Context, filter AuditFilter, offset, limit int) ([]models.AuditEvent, int64, error) {
+	var total int64
+	if err := r.query(ctx, filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []models.AuditEvent
+	err := r.query(ctx, filter).
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&events).Error
+	return events, total, err
+}
+
+func (r *gormAuditRepository) Each(ctx context.Context, filter AuditFilter, fn func(*models.AuditEvent) error) error {
+	rows, err := r.query(ctx, filter).Order("created_at DESC, id DESC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.AuditEvent
+		if err := r.db.ScanRows(rows, &event); err != nil {
+			return err
+		}
+		if err := fn(&event); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+type gormDepartmentRepository struct {
+	db *gorm.DB
+}
+
+func NewGormDepartmentRepository(db *gorm.DB) DepartmentRepository {
+	return &gormDepartmentRepository{db: db}
+}
+
+func (r *gormDepartmentRepository) List(ctx context.Context) ([]models.Department, error) {
+	var departments []models.Department
+	if err := r.db.WithContext(ctx).Find(&departments).Error; err != nil {
+		return nil, err
+	}
+	return departments, nil
+}
+
+func (r *gormDepartmentRepository) FindByID(ctx context.Context, id uint) (*models.Department, error) {
+	var department models.Department
+	if err := r.db.WithContext(ctx).First(&department, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &department, nil
+}
+
+func (r *gormDepartmentRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Department{}).Count(&count).Error
+	return count, err
+}
+
+func (r *gormDepartmentRepository) FirstOrCreate(ctx context.Context, name string) (*models.Department, error) {
+	var department models.Department
+	if err := r.db.WithContext(ctx).FirstOrCreate(&department, models.Department{Name: name}).Error; err != nil {
+		return nil, err
+	}
+	return &department, nil
+}
+
+type gormNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewGormNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &gormNotificationRepository{db: db}
+}
+
+func (r *gormNotificationRepository) Create(ctx context.Context, notification *models.Notification) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(notification)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormNotificationRepository) ListForUser(ctx context.Context, userID uint, limit int) ([]*models.Notification, error) {
+	query := r.db.WithContext(ctx).
+		Preload("Ticket").
+		Where("user_id = ? AND in_app = ?", userID, true)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var notifications []*models.Notification
+	err := query.Order("created_at DESC, id DESC").Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *gormNotificationRepository) ListSince(ctx context.Context, userID uint, since time.Time) ([]*models.Notification, error) {
+	var notifications []*models.Notification
+	err := r.db.WithContext(ctx).
+		Preload("Ticket").
+		Where("user_id = ? AND created_at > ?", userID, since).
+		Order("created_at, id").
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *gormNotificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND in_app = ? AND read_at IS NULL", userID, true).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *gormNotificationRepository) MarkRead(ctx context.Context, id, userID uint) (*models.Notification, error) {
+	var notification models.Notification
+	if err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND in_app = ?", id, userID, true).
+		First(&notification).Error; err != nil {
+		return nil, notFound(err)
+	}
+	if notification.ReadAt == nil {
+		now := time.Now().UTC()
+		if err := r.db.WithContext(ctx).Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+		notification.ReadAt = &now
+	}
+	return &notification, nil
+}
+
+func (r *gormNotificationRepository) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND in_app = ? AND read_at IS NULL", userID, true).
+		Update("read_at", time.Now().UTC())
+	return result.RowsAffected, result.Error
+}
+
+type gormNotificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewGormNotificationPreferenceRepository(db *gorm.DB) NotificationPreferenceRepository {
+	return &gormNotificationPreferenceRepository{db: db}
+}
+
+func (r *gormNotificationPreferenceRepository) Find(ctx context.Context, userID uint) (*models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+	if err := r.db.WithContext(ctx).First(&preference, "user_id = ?", userID).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &preference, nil
+}
+
+func (r *gormNotificationPreferenceRepository) Save(ctx context.Context, preference *models.NotificationPreference) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(preference).Error
+}
+
+func (r *gormNotificationPreferenceRepository) ListDigestDue(ctx context.Context, before time.Time) ([]*models.NotificationPreference, error) {
+	var preferences []*models.NotificationPreference
+	err := r.db.WithContext(ctx).
+		Where("daily_digest = ? AND (digest_sent_at IS NULL OR digest_sent_at <= ?)", true, before).
+		Order("user_id").
+		Find(&preferences).Error
+	return preferences, err
+}
+
+func (r *gormNotificationPreferenceRepository) ClaimDigest(ctx context.Context, userID uint, previous *time.Time, sentAt time.Time) (bool, error) {
+	query := r.db.WithContext(ctx).Model(&models.NotificationPreference{}).
+		Where("user_id = ? AND daily_digest = ?", userID, true)
+	if previous == nil {
+		query = query.Where("digest_sent_at IS NULL")
+	} else {
+		query = query.Where("digest_sent_at = ?", *previous)
+	}
+	result := query.Update("digest_sent_at", sentAt)
+	return result.RowsAffected > 0, result.Error
+}
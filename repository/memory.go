@@ -0,0 +1,1002 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ticketing-fiber/models"
+)
+
+// Memory menyimpan data di memori dan menyediakan implementasi in-memory
+// dari semua repository. Relasi (CreatedBy, Department, Replies, Groups)
+// diisi dari data yang sama sehingga perilakunya mendekati versi GORM.
+// Dipakai untuk pengujian tanpa database.
+type Memory struct {
+	mu          sync.Mutex
+	nextID      uint
+	users       map[uint]models.User
+	groups      map[string]models.Group
+	userGroups  map[uint][]string
+	departments map[uint]models.Department
+	tickets     map[uint]models.Ticket
+	replies     []models.TicketReply
+	// notifications terurut naik menurut ID
+	notifications []models.Notification
+	preferences   map[uint]models.NotificationPreference
+	identities    []models.UserIdentity
+	sessions      []models.UserSession
+	// auditEvents terurut naik menurut ID
+	auditEvents []models.AuditEvent
+	watchCursor *models.TicketWatchCursor
+	watchStates map[uint]models.TicketWatchState
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		users:       make(map[uint]models.User),
+		groups:      make(map[string]models.Group),
+		userGroups:  make(map[uint][]string),
+		departments: make(map[uint]models.Department),
+		tickets:     make(map[uint]models.Ticket),
+		preferences: make(map[uint]models.NotificationPreference),
+		watchStates: make(map[uint]models.TicketWatchState),
+	}
+}
+
+func (m *Memory) Tickets() TicketRepository {
+	return memoryTickets{m}
+}
+
+func (m *Memory) Users() UserRepository {
+	return memoryUsers{m}
+}
+
+func (m *Memory) Departments() DepartmentRepository {
+	return memoryDepartments{m}
+}
+
+func (m *Memory) Notifications() NotificationRepository {
+	return memoryNotifications{m}
+}
+
+func (m *Memory) NotificationPreferences() NotificationPreferenceRepository {
+	return memoryNotificationPreferences{m}
+}
+
+func (m *Memory) Identities() IdentityRepository {
+	return memoryIdentities{m}
+}
+
+func (m *Memory) Sessions() SessionRepository {
+	return memorySessions{m}
+}
+
+func (m *Memory) Audit() AuditRepository {
+	return memoryAudit{m}
+}
+
+func (m *Memory) TicketWatch() TicketWatchRepository {
+	return memoryTicketWatch{m}
+}
+
+func (m *Memory) newID() uint {
+	m.nextID++
+	return m.nextID
+}
+
+func (m *Memory) department(id *uint) *models.Department {
+	if id == nil {
+		return nil
+	}
+	if d, ok := m.departments[*id]; ok {
+		return &d
+	}
+	return nil
+}
+
+func (m *Memory) user(id uint) models.User {
+	u := m.users[id]
+	u.Groups = nil
+	for _, name := range m.userGroups[id] {
+		u.Groups = append(u.Groups, m.groups[name])
+	}
+	return u
+}
+
+func (m *Memory) ticketReplies(ticketID uint, withUser bool) []models.TicketReply {
+	var replies []models.TicketReply
+	for _, r := range m.replies {
+		if r.TicketID != ticketID {
+			continue
+		}
+		if withUser {
+			r.User = m.user(r.UserID)
+		}
+		replies = append(replies, r)
+	}
+	return replies
+}
+
+type memoryTickets struct {
+	*Memory
+}
+
+func (m memoryTickets) Create(ctx context.Context, ticket *models.Ticket) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	ticket.ID = m.newID()
+	ticket.CreatedAt = now
+	ticket.UpdatedAt = now
+	if ticket.Status == "" {
+		ticket.Status = models.StatusWaiting
+	}
+	if ticket.Priority == "" {
+		ticket.Priority = models.PriorityMedium
+	}
+
+	stored := *ticket
+	stored.Department = nil
+	stored.Replies = nil
+	stored.CreatedBy = models.User{}
+	m.tickets[ticket.ID] = stored
+	return nil
+}
+
+func (m memoryTickets) FindByID(ctx context.Context, id uint) (*models.Ticket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tickets[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	t.Department = m.department(t.DepartmentID)
+	return &t, nil
+}
+
+func (m memoryTickets) FindOwned(ctx context.Context, id, ownerID uint) (*models.Ticket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tickets[id]
+	if !ok || t.CreatedByID != ownerID {
+		return nil, ErrNotFound
+	}
+	t.CreatedBy = m.user(t.CreatedByID)
+	t.Department = m.department(t.DepartmentID)
+	t.Replies = m.ticketReplies(t.ID, true)
+	return &t, nil
+}
+
+func (m memoryTickets) List(ctx context.Context, filter TicketFilter) ([]*models.Ticket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	search := strings.ToLower(filter.Search)
+	searchID, searchIsID := 0, false
+	if filter.Search != "" {
+		if id, err := strconv.Atoi(filter.Search); err == nil {
+			searchID, searchIsID = id, true
+		}
+	}
+
+	var tickets []*models.Ticket
+	for _, t := range m.tickets {
+		if filter.CreatedByID != 0 && t.CreatedByID != filter.CreatedByID {
+			continue
+		}
+		if filter.Status != "" && t.Status != filter.Status {
+			continue
+		}
+		if filter.Priority != "" && t.Priority != filter.Priority {
+			continue
+		}
+		if search != "" {
+			matched := strings.Contains(strings.ToLower(t.Title), search) ||
+				strings.Contains(strings.ToLower(t.Description), search) ||
+				(searchIsID && int(t.ID) == searchID)
+			if !matched {
+				continue
+			}
+		}
+
+		t := t
+		t.Department = m.department(t.DepartmentID)
+		t.Replies = m.ticketReplies(t.ID, false)
+		tickets = append(tickets, &t)
+	}
+
+	sort.Slice(tickets, func(i, j int) bool {
+		if tickets[i].CreatedAt.Equal(tickets[j].CreatedAt) {
+			return tickets[i].ID > tickets[j].ID
+		}
+		return tickets[i].CreatedAt.After(tickets[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(tickets) > filter.Limit {
+		tickets = tickets[:filter.Limit]
+	}
+	return tickets, nil
+}
+
+func (m memoryTickets) CountByStatus(ctx context.Context, ownerID uint) (map[models.TicketStatus]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := make(map[models.TicketStatus]int64)
+	for _, t := range m.tickets {
+		if t.CreatedByID == ownerID {
+			counts[t.Status]++
+		}
+	}
+	return counts, nil
+}
+
+func (m memoryTickets) CountOpen(ctx context.Context) ([]TicketCount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type key struct {
+		status     models.TicketStatus
+		department string
+	}
+	byKey := make(map[key]int64)
+	for _, t := range m.tickets {
+		if t.Status == models.StatusClosed {
+			continue
+		}
+		k := key{status: t.Status}
+		if d := m.department(t.DepartmentID); d != nil {
+			k.department = d.Name
+		}
+		byKey[k]++
+	}
+
+	counts := make([]TicketCount, 0, len(byKey))
+	for k, n := range byKey {
+		counts = append(counts, TicketCount{Status: k.status, Department: k.department, Count: n})
+	}
+	return counts, nil
+}
+
+func (m memoryTickets) AddReply(ctx context.Context, reply *models.TicketReply) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tickets[reply.TicketID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	now := time.Now()
+	reply.ID = m.newID()
+	reply.CreatedAt = now
+
+	stored := *reply
+	stored.Ticket = models.Ticket{}
+	stored.User = models.User{}
+	m.replies = append(m.replies, stored)
+
+	t.UpdatedAt = now
+	m.tickets[t.ID] = t
+	return nil
+}
+
+func (m memoryTickets) LastReplyID(ctx context.Context) (uint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.replies) == 0 {
+		return 0, nil
+	}
+	return m.replies[len(m.replies)-1].ID, nil
+}
+
+func (m memoryTickets) RepliesAfter(ctx context.Context, afterID uint, limit int) ([]*models.TicketReply, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// m.replies terurut naik menurut ID karena ID diambil dari newID
+	var replies []*models.TicketReply
+	for _, r := range m.replies {
+		if r.ID <= afterID {
+			continue
+		}
+		if limit > 0 && len(replies) >= limit {
+			break
+		}
+		r := r
+		r.User = m.user(r.UserID)
+		r.Ticket = m.tickets[r.TicketID]
+		replies = append(replies, &r)
+	}
+	return replies, nil
+}
+
+func (m memoryTickets) ChangedSince(ctx context.Context, updatedAt time.Time, afterID uint, limit int) ([]*models.Ticket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var tickets []*models.Ticket
+	for _, t := range m.tickets {
+		if t.UpdatedAt.Before(updatedAt) || (t.UpdatedAt.Equal(updatedAt) && t.ID <= afterID) {
+			continue
+		}
+		tickets = append(tickets, &models.Ticket{
+			ID:           t.ID,
+			CreatedByID:  t.CreatedByID,
+			Status:       t.Status,
+			DepartmentID: t.DepartmentID,
+			UpdatedAt:    t.UpdatedAt,
+		})
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		if !tickets[i].UpdatedAt.Equal(tickets[j].UpdatedAt) {
+			return tickets[i].UpdatedAt.Before(tickets[j].UpdatedAt)
+		}
+		return tickets[i].ID < tickets[j].ID
+	})
+	if limit > 0 && len(tickets) > limit {
+		tickets = tickets[:limit]
+	}
+	return tickets, nil
+}
+
+type memoryTicketWatch struct {
+	*Memory
+}
+
+func (m memoryTicketWatch) Cursor(ctx context.Context) (*models.TicketWatchCursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.watchCursor == nil {
+		return nil, ErrNotFound
+	}
+	cursor := *m.watchCursor
+	return &cursor, nil
+}
+
+func (m memoryTicketWatch) Seed(ctx context.Context) (*models.TicketWatchCursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.watchCursor == nil {
+		cursor := &models.TicketWatchCursor{Name: "tickets"}
+		if len(m.replies) > 0 {
+			cursor.ReplyID = m.replies[len(m.replies)-1].ID
+		}
+		for _, t := range m.tickets {
+			if t.UpdatedAt.After(cursor.TicketUpdatedAt) || (t.UpdatedAt.Equal(cursor.TicketUpdatedAt) && t.ID > cursor.TicketID) {
+				cursor.TicketUpdatedAt, cursor.TicketID = t.UpdatedAt, t.ID
+			}
+			m.watchStates[t.ID] = models.TicketWatchState{
+				TicketID:     t.ID,
+				Status:       t.Status,
+				DepartmentID: t.DepartmentID,
+				SeenAt:       t.UpdatedAt,
+				Version:      1,
+			}
+		}
+		m.watchCursor = cursor
+	}
+	cursor := *m.watchCursor
+	return &cursor, nil
+}
+
+func (m memoryTicketWatch) Advance(ctx context.Context, cursor models.TicketWatchCursor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.watchCursor
+	if stored == nil {
+		return nil
+	}
+	if cursor.ReplyID > stored.ReplyID {
+		stored.ReplyID = cursor.ReplyID
+	}
+	if cursor.TicketUpdatedAt.After(stored.TicketUpdatedAt) ||
+		(cursor.TicketUpdatedAt.Equal(stored.TicketUpdatedAt) && cursor.TicketID > stored.TicketID) {
+		stored.TicketUpdatedAt, stored.TicketID = cursor.TicketUpdatedAt, cursor.TicketID
+	}
+	return nil
+}
+
+func (m memoryTicketWatch) States(ctx context.Context, ticketIDs []uint) (map[uint]*models.TicketWatchState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	states := make(map[uint]*models.TicketWatchState, len(ticketIDs))
+	for _, id := range ticketIDs {
+		if state, ok := m.watchStates[id]; ok {
+			states[id] = &state
+		}
+	}
+	return states, nil
+}
+
+func (m memoryTicketWatch) SaveState(ctx context.Context, state *models.TicketWatchState) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.watchStates[state.TicketID].Version != state.Version {
+		return false, nil
+	}
+	state.Version++
+	m.watchStates[state.TicketID] = *state
+	return true, nil
+}
+
+type memoryUsers struct {
+	*Memory
+}
+
+func (m memoryUsers) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[id]; !ok {
+		return nil, ErrNotFound
+	}
+	u := m.user(id)
+	return &u, nil
+}
+
+func (m memoryUsers) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, u := range m.users {
+		if u.Username == username {
+			found := m.user(id)
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m memoryUsers) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return m.findUser(func(u models.User) bool { return u.Email == email })
+}
+
+func (m memoryUsers) FindByLogin(ctx context.Context, login string) (*models.User, error) {
+	return m.findUser(func(u models.User) bool { return u.Username == login || u.Email == login })
+}
+
+// findUser mengembalikan user dengan ID terkecil yang cocok, seperti First di GORM
+func (m memoryUsers) findUser(match func(models.User) bool) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var found uint
+	for id, u := range m.users {
+		if match(u) && (found == 0 || id < found) {
+			found = id
+		}
+	}
+	if found == 0 {
+		return nil, ErrNotFound
+	}
+	u := m.user(found)
+	return &u, nil
+}
+
+func (m memoryUsers) UsernameTaken(ctx context.Context, username string, excludeID uint) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.ID != excludeID && u.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m memoryUsers) EmailTaken(ctx context.Context, email string, excludeID uint) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.ID != excludeID && u.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m memoryUsers) Create(ctx context.Context, user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	user.ID = m.newID()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	m.users[user.ID] = stripUser(*user)
+	for _, g := range user.Groups {
+		m.addToGroup(user.ID, g.Name)
+	}
+	return nil
+}
+
+func (m memoryUsers) Save(ctx context.Context, user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if user.ID == 0 {
+		user.ID = m.newID()
+		user.CreatedAt = time.Now()
+	}
+	user.UpdatedAt = time.Now()
+	m.users[user.ID] = stripUser(*user)
+	return nil
+}
+
+func (m memoryUsers) UpdatePassword(ctx context.Context, id uint, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	u.Password = hash
+	m.users[id] = u
+	return nil
+}
+
+func (m memoryUsers) AddToGroup(ctx context.Context, user *models.User, groupName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[user.ID]; !ok {
+		return ErrNotFound
+	}
+	m.addToGroup(user.ID, groupName)
+	user.Groups = m.user(user.ID).Groups
+	return nil
+}
+
+func (m memoryUsers) RemoveFromGroup(ctx context.Context, user *models.User, groupName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := m.userGroups[user.ID][:0]
+	for _, name := range m.userGroups[user.ID] {
+		if name != groupName {
+			names = append(names, name)
+		}
+	}
+	m.userGroups[user.ID] = names
+	user.Groups = m.user(user.ID).Groups
+	return nil
+}
+
+func (m *Memory) addToGroup(userID uint, groupName string) {
+	if _, ok := m.groups[groupName]; !ok {
+		m.groups[groupName] = models.Group{ID: m.newID(), Name: groupName, CreatedAt: time.Now()}
+	}
+	for _, name := range m.userGroups[userID] {
+		if name == groupName {
+			return
+		}
+	}
+	m.userGroups[userID] = append(m.userGroups[userID], groupName)
+}
+
+func stripUser(u models.User) models.User {
+	u.Department = nil
+	u.Tickets = nil
+	u.Replies = nil
+	u.Groups = nil
+	return u
+}
+
+type memoryIdentities struct {
+	*Memory
+}
+
+func (m memoryIdentities) Find(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	return m.find(func(i models.UserIdentity) bool { return i.Provider == provider && i.Subject == subject })
+}
+
+func (m memoryIdentities) FindForUser(ctx context.Context, userID uint, provider string) (*models.UserIdentity, error) {
+	return m.find(func(i models.UserIdentity) bool { return i.UserID == userID && i.Provider == provider })
+}
+
+func (m memoryIdentities) find(match func(models.UserIdentity) bool) (*models.UserIdentity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, identity := range m.identities {
+		if match(identity) {
+			return &identity, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m memoryIdentities) Save(ctx context.Context, identity *models.UserIdentity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *identity
+	stored.User = models.User{}
+	for i, existing := range m.identities {
+		if existing.ID == identity.ID && identity.ID != 0 {
+			m.identities[i] = stored
+			return nil
+		}
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return errors.New("duplicate identity provider and subject")
+		}
+	}
+	identity.ID = m.newID()
+	identity.CreatedAt = time.Now()
+	stored.ID = identity.ID
+	stored.CreatedAt = identity.CreatedAt
+	m.identities = append(m.identities, stored)
+	return nil
+}
+
+type memorySessions struct {
+	*Memory
+}
+
+func (m memorySessions) Create(ctx context.Context, session *models.UserSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.sessions {
+		if s.SessionID == session.SessionID {
+			return errors.New("duplicate session id")
+		}
+	}
+	session.ID = m.newID()
+	session.CreatedAt = time.Now()
+	stored := *session
+	stored.User = models.User{}
+	m.sessions = append(m.sessions, stored)
+	return nil
+}
+
+func (m memorySessions) ListForUser(ctx context.Context, userID uint) ([]models.UserSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sessions []models.UserSession
+	for _, s := range m.sessions {
+		if s.UserID == userID {
+			sessions = append(sessions, s)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (m memorySessions) FindForUser(ctx context.Context, id, userID uint) (*models.UserSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.sessions {
+		if s.ID == id && s.UserID == userID {
+			return &s, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m memorySessions) Touch(ctx context.Context, sessionID string, now time.Time, minInterval time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.sessions {
+		s := &m.sessions[i]
+		if s.SessionID == sessionID && s.LastSeenAt.Before(now.Add(-minInterval)) {
+			s.LastSeenAt = now
+		}
+	}
+	return nil
+}
+
+func (m memorySessions) Delete(ctx context.Context, id uint) error {
+	return m.delete(func(s models.UserSession) bool { return s.ID == id })
+}
+
+func (m memorySessions) DeleteBySessionID(ctx context.Context, sessionID string) error {
+	return m.delete(func(s models.UserSession) bool { return s.SessionID == sessionID })
+}
+
+func (m memorySessions) delete(match func(models.UserSession) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.sessions[:0]
+	for _, s := range m.sessions {
+		if !match(s) {
+			kept = append(kept, s)
+		}
+	}
+	m.sessions = kept
+	return nil
+}
+
+type memoryAudit struct {
+	*Memory
+}
+
+func (m memoryAudit) Create(ctx context.Context, event *models.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event.ID = m.newID()
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	m.auditEvents = append(m.auditEvents, *event)
+	return nil
+}
+
+func (m memoryAudit) List(ctx context.Context, filter AuditFilter, offset, limit int) ([]models.AuditEvent, int64, error) {
+	matched := m.matching(filter)
+	total := int64(len(matched))
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	matched = matched[offset:]
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+func (m memoryAudit) Each(ctx context.Context, filter AuditFilter, fn func(*models.AuditEvent) error) error {
+	for _, event := range m.matching(filter) {
+		if err := fn(&event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matching event yang cocok dengan filter, terbaru lebih dulu
+func (m memoryAudit) matching(filter AuditFilter) []models.AuditEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []models.AuditEvent
+	for i := len(m.auditEvents) - 1; i >= 0; i-- {
+		e := m.auditEvents[i]
+		switch {
+		case filter.Action != "" && e.Action != filter.Action,
+			filter.Actor != "" && e.ActorUsername != filter.Actor,
+			filter.TargetType != "" && e.TargetType != filter.TargetType,
+			filter.TargetID != "" && e.TargetID != filter.TargetID,
+			!filter.From.IsZero() && e.CreatedAt.Before(filter.From),
+			!filter.To.IsZero() && !e.CreatedAt.Before(filter.To):
+			continue
+		}
+		events = append(events, e)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].CreatedAt.After(events[j].CreatedAt) })
+	return events
+}
+
+type memoryDepartments struct {
+	*Memory
+}
+
+func (m memoryDepartments) List(ctx context.Context) ([]models.Department, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	departments := make([]models.Department, 0, len(m.departments))
+	for _, d := range m.departments {
+		departments = append(departments, d)
+	}
+	sort.Slice(departments, func(i, j int) bool {
+		return departments[i].ID < departments[j].ID
+	})
+	return departments, nil
+}
+
+func (m memoryDepartments) FindByID(ctx context.Context, id uint) (*models.Department, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, ok := m.departments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &d, nil
+}
+
+func (m memoryDepartments) Count(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return int64(len(m.departments)), nil
+}
+
+func (m memoryDepartments) FirstOrCreate(ctx context.Context, name string) (*models.Department, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, d := range m.departments {
+		if d.Name == name {
+			return &d, nil
+		}
+	}
+	now := time.Now()
+	d := models.Department{ID: m.newID(), Name: name, CreatedAt: now, UpdatedAt: now}
+	m.departments[d.ID] = d
+	return &d, nil
+}
+
+type memoryNotifications struct {
+	*Memory
+}
+
+func (m memoryNotifications) Create(ctx context.Context, notification *models.Notification) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, n := range m.notifications {
+		if n.UserID == notification.UserID && n.DedupeKey == notification.DedupeKey {
+			return false, nil
+		}
+	}
+
+	notification.ID = m.newID()
+	notification.CreatedAt = time.Now()
+
+	stored := *notification
+	stored.Ticket = models.Ticket{}
+	m.notifications = append(m.notifications, stored)
+	return true, nil
+}
+
+func (m memoryNotifications) ListForUser(ctx context.Context, userID uint, limit int) ([]*models.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var notifications []*models.Notification
+	for i := len(m.notifications) - 1; i >= 0; i-- {
+		n := m.notifications[i]
+		if n.UserID != userID || !n.InApp {
+			continue
+		}
+		if limit > 0 && len(notifications) >= limit {
+			break
+		}
+		n.Ticket = m.tickets[n.TicketID]
+		notifications = append(notifications, &n)
+	}
+	return notifications, nil
+}
+
+func (m memoryNotifications) ListSince(ctx context.Context, userID uint, since time.Time) ([]*models.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var notifications []*models.Notification
+	for _, n := range m.notifications {
+		if n.UserID != userID || !n.CreatedAt.After(since) {
+			continue
+		}
+		n.Ticket = m.tickets[n.TicketID]
+		notifications = append(notifications, &n)
+	}
+	return notifications, nil
+}
+
+func (m memoryNotifications) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count int64
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.InApp && n.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m memoryNotifications) MarkRead(ctx context.Context, id, userID uint) (*models.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.notifications {
+		n := &m.notifications[i]
+		if n.ID != id || n.UserID != userID || !n.InApp {
+			continue
+		}
+		if n.ReadAt == nil {
+			now := time.Now()
+			n.ReadAt = &now
+		}
+		result := *n
+		return &result, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (m memoryNotifications) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var count int64
+	for i := range m.notifications {
+		n := &m.notifications[i]
+		if n.UserID == userID && n.InApp && n.ReadAt == nil {
+			n.ReadAt = &now
+			count++
+		}
+	}
+	return count, nil
+}
+
+type memoryNotificationPreferences struct {
+	*Memory
+}
+
+func (m memoryNotificationPreferences) Find(ctx context.Context, userID uint) (*models.NotificationPreference, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	preference, ok := m.preferences[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &preference, nil
+}
+
+func (m memoryNotificationPreferences) Save(ctx context.Context, preference *models.NotificationPreference) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	preference.UpdatedAt = time.Now()
+	m.preferences[preference.UserID] = *preference
+	return nil
+}
+
+func (m memoryNotificationPreferences) ListDigestDue(ctx context.Context, before time.Time) ([]*models.NotificationPreference, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var preferences []*models.NotificationPreference
+	for _, p := range m.preferences {
+		if p.DailyDigest && (p.DigestSentAt == nil || !p.DigestSentAt.After(before)) {
+			preferences = append(preferences, &p)
+		}
+	}
+	sort.Slice(preferences, func(i, j int) bool { return preferences[i].UserID < preferences[j].UserID })
+	return preferences, nil
+}
+
+func (m memoryNotificationPreferences) ClaimDigest(ctx context.Context, userID uint, previous *time.Time, sentAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.preferences[userID]
+	if !ok || !p.DailyDigest {
+		return false, nil
+	}
+	switch {
+	case p.DigestSentAt == nil && previous != nil,
+		p.DigestSentAt != nil && (previous == nil || !p.DigestSentAt.Equal(*previous)):
+		return false, nil
+	}
+	p.DigestSentAt = &sentAt
+	m.preferences[userID] = p
+	return true, nil
+}
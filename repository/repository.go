@@ -0,0 +1,189 @@
+// Package repository memisahkan akses data dari handler. Setiap repository
+// punya implementasi GORM untuk produksi dan implementasi in-memory untuk
+// pengujian tanpa database.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ticketing-fiber/models"
+)
+
+// ErrNotFound dikembalikan jika data yang dicari tidak ada
+var ErrNotFound = errors.New("record not found")
+
+// TicketFilter kriteria pencarian tiket; field kosong berarti tanpa filter
+type TicketFilter struct {
+	CreatedByID uint
+	// Search dicocokkan dengan ID (jika berupa angka), judul dan deskripsi
+	Search   string
+	Status   models.TicketStatus
+	Priority models.TicketPriority
+	Limit    int
+}
+
+// TicketCount jumlah tiket untuk satu kombinasi status dan departemen;
+// Department kosong berarti tiket tanpa departemen
+type TicketCount struct {
+	Status     models.TicketStatus
+	Department string
+	Count      int64
+}
+
+type TicketRepository interface {
+	Create(ctx context.Context, ticket *models.Ticket) error
+	// FindByID mengembalikan tiket beserta Department
+	FindByID(ctx context.Context, id uint) (*models.Ticket, error)
+	// FindOwned seperti FindByID tetapi hanya jika tiket dibuat oleh ownerID,
+	// beserta CreatedBy dan Replies.User
+	FindOwned(ctx context.Context, id, ownerID uint) (*models.Ticket, error)
+	// List mengembalikan tiket terbaru lebih dulu beserta Department dan Replies
+	List(ctx context.Context, filter TicketFilter) ([]*models.Ticket, error)
+	CountByStatus(ctx context.Context, ownerID uint) (map[models.TicketStatus]int64, error)
+	// CountOpen menghitung semua tiket yang belum CLOSED per status dan departemen
+	CountOpen(ctx context.Context) ([]TicketCount, error)
+	// AddReply menyimpan balasan dan memperbarui updated_at tiket
+	AddReply(ctx context.Context, reply *models.TicketReply) error
+	// LastReplyID ID balasan terbaru, 0 jika belum ada balasan
+	LastReplyID(ctx context.Context) (uint, error)
+	// RepliesAfter balasan dengan ID lebih besar dari afterID, terurut naik,
+	// beserta User dan Ticket
+	RepliesAfter(ctx context.Context, afterID uint, limit int) ([]*models.TicketReply, error)
+	// ChangedSince tiket dengan (updated_at, id) setelah (updatedAt, afterID),
+	// terurut naik menurut updated_at lalu id, hanya ID, CreatedByID, Status,
+	// DepartmentID dan UpdatedAt tanpa relasi
+	ChangedSince(ctx context.Context, updatedAt time.Time, afterID uint, limit int) ([]*models.Ticket, error)
+}
+
+// TicketWatchRepository cursor dan keadaan tiket milik TicketWatcher yang
+// disimpan di database, dipakai bersama semua instance aplikasi
+type TicketWatchRepository interface {
+	// Cursor posisi tersimpan; ErrNotFound jika belum pernah di-seed
+	Cursor(ctx context.Context) (*models.TicketWatchCursor, error)
+	// Seed membuat cursor di balasan dan tiket terakhir lalu mencatat
+	// keadaan semua tiket. Jika instance lain sudah lebih dulu seed, cursor
+	// miliknya yang dikembalikan.
+	Seed(ctx context.Context) (*models.TicketWatchCursor, error)
+	// Advance memajukan cursor tersimpan; posisi yang tidak lebih baru dari
+	// yang tersimpan diabaikan
+	Advance(ctx context.Context, cursor models.TicketWatchCursor) error
+	// States keadaan tersimpan untuk ticketIDs, dikunci dengan ID tiket;
+	// tiket tanpa keadaan tidak ada di map
+	States(ctx context.Context, ticketIDs []uint) (map[uint]*models.TicketWatchState, error)
+	// SaveState menyimpan state hanya jika Version tersimpan masih sama
+	// dengan state.Version (0 = belum ada), lalu menaikkan state.Version.
+	// false jika instance lain lebih dulu menyimpan.
+	SaveState(ctx context.Context, state *models.TicketWatchState) (bool, error)
+}
+
+type UserRepository interface {
+	// FindByID mengembalikan user beserta Groups
+	FindByID(ctx context.Context, id uint) (*models.User, error)
+	// FindByUsername mengembalikan user beserta Groups
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	// FindByEmail mengembalikan user beserta Groups
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindByLogin mencari user dengan username atau email sama dengan login,
+	// beserta Groups
+	FindByLogin(ctx context.Context, login string) (*models.User, error)
+	// UsernameTaken mengecek username dipakai user lain selain excludeID,
+	// termasuk user yang sudah dihapus karena unique index tetap berlaku
+	UsernameTaken(ctx context.Context, username string, excludeID uint) (bool, error)
+	EmailTaken(ctx context.Context, email string, excludeID uint) (bool, error)
+	Create(ctx context.Context, user *models.User) error
+	Save(ctx context.Context, user *models.User) error
+	// UpdatePassword hanya mengubah kolom password
+	UpdatePassword(ctx context.Context, id uint, hash string) error
+	// AddToGroup menambahkan user ke grup, membuat grup jika belum ada
+	AddToGroup(ctx context.Context, user *models.User, groupName string) error
+	// RemoveFromGroup mengeluarkan user dari grup; tidak error jika user
+	// bukan anggota atau grup belum ada
+	RemoveFromGroup(ctx context.Context, user *models.User, groupName string) error
+}
+
+// IdentityRepository tautan user lokal dengan akun di identity provider
+// eksternal (OIDC issuer atau "ldap")
+type IdentityRepository interface {
+	// Find tautan provider+subject; ErrNotFound jika belum ada
+	Find(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	// FindForUser tautan userID di provider; ErrNotFound jika belum ada
+	FindForUser(ctx context.Context, userID uint, provider string) (*models.UserIdentity, error)
+	// Save membuat atau memperbarui tautan
+	Save(ctx context.Context, identity *models.UserIdentity) error
+}
+
+// SessionRepository catatan perangkat untuk setiap session login
+// (tabel user_sessions); data session sendiri ada di session storage
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.UserSession) error
+	// ListForUser session milik userID, terakhir aktif lebih dulu
+	ListForUser(ctx context.Context, userID uint) ([]models.UserSession, error)
+	// FindForUser ErrNotFound jika session tidak ada atau bukan milik userID
+	FindForUser(ctx context.Context, id, userID uint) (*models.UserSession, error)
+	// Touch mengisi LastSeenAt dengan now jika nilai sebelumnya lebih lama
+	// dari minInterval, agar tidak menulis ke database di setiap request
+	Touch(ctx context.Context, sessionID string, now time.Time, minInterval time.Duration) error
+	Delete(ctx context.Context, id uint) error
+	DeleteBySessionID(ctx context.Context, sessionID string) error
+}
+
+// AuditFilter kriteria pencarian audit log; field kosong berarti tanpa
+// filter. To eksklusif.
+type AuditFilter struct {
+	Action     string
+	Actor      string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, event *models.AuditEvent) error
+	// List event terbaru lebih dulu beserta jumlah semua event yang cocok
+	List(ctx context.Context, filter AuditFilter, offset, limit int) ([]models.AuditEvent, int64, error)
+	// Each memanggil fn untuk setiap event yang cocok, terbaru lebih dulu,
+	// tanpa memuat semuanya ke memori; berhenti di error pertama dari fn
+	Each(ctx context.Context, filter AuditFilter, fn func(*models.AuditEvent) error) error
+}
+
+// NotificationRepository notifikasi di aplikasi. Selain ListSince, semua
+// method hanya melihat notifikasi dengan InApp true.
+type NotificationRepository interface {
+	// Create menyimpan notifikasi dan mengembalikan true; notifikasi dengan
+	// UserID dan DedupeKey yang sudah ada diabaikan (false) tanpa error
+	Create(ctx context.Context, notification *models.Notification) (bool, error)
+	// ListForUser notifikasi terbaru lebih dulu beserta Ticket
+	ListForUser(ctx context.Context, userID uint, limit int) ([]*models.Notification, error)
+	// ListSince semua notifikasi userID yang dibuat setelah since, terlama
+	// lebih dulu, beserta Ticket
+	ListSince(ctx context.Context, userID uint, since time.Time) ([]*models.Notification, error)
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+	// MarkRead menandai notifikasi sudah dibaca dan mengembalikannya;
+	// ErrNotFound jika notifikasi bukan milik userID
+	MarkRead(ctx context.Context, id, userID uint) (*models.Notification, error)
+	// MarkAllRead menandai semua notifikasi userID sudah dibaca
+	MarkAllRead(ctx context.Context, userID uint) (int64, error)
+}
+
+type NotificationPreferenceRepository interface {
+	// Find pilihan notifikasi userID; ErrNotFound jika user belum menyimpan
+	Find(ctx context.Context, userID uint) (*models.NotificationPreference, error)
+	// Save membuat atau menimpa pilihan notifikasi user
+	Save(ctx context.Context, preference *models.NotificationPreference) error
+	// ListDigestDue pilihan user dengan ringkasan harian yang terakhir
+	// dikirim sebelum atau tepat pada before
+	ListDigestDue(ctx context.Context, before time.Time) ([]*models.NotificationPreference, error)
+	// ClaimDigest mengubah DigestSentAt dari previous ke sentAt; false jika
+	// nilainya sudah diubah proses lain sehingga ringkasan tidak dikirim dua kali
+	ClaimDigest(ctx context.Context, userID uint, previous *time.Time, sentAt time.Time) (bool, error)
+}
+
+type DepartmentRepository interface {
+	List(ctx context.Context) ([]models.Department, error)
+	FindByID(ctx context.Context, id uint) (*models.Department, error)
+	Count(ctx context.Context) (int64, error)
+	FirstOrCreate(ctx context.Context, name string) (*models.Department, error)
+}
@@ -0,0 +1,456 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ticketing-fiber/migrations"
+	"ticketing-fiber/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// stores satu set repository dengan backend yang sama
+type stores struct {
+	users      UserRepository
+	identities IdentityRepository
+	sessions   SessionRepository
+	audit      AuditRepository
+	tickets    TicketRepository
+	watch      TicketWatchRepository
+	prefs      NotificationPreferenceRepository
+}
+
+// eachBackend menjalankan test yang sama terhadap implementasi GORM (SQLite
+// sementara) dan in-memory, agar fake yang dipakai test lain berperilaku
+// sama dengan database
+func eachBackend(t *testing.T, test func(t *testing.T, s stores)) {
+	t.Run("gorm", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "repo.db")), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatal(err)
+		}
+		migrator, err := migrations.New(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := migrator.Up(); err != nil {
+			t.Fatal(err)
+		}
+		test(t, stores{
+			users:      NewGormUserRepository(db),
+			identities: NewGormIdentityRepository(db),
+			sessions:   NewGormSessionRepository(db),
+			audit:      NewGormAuditRepository(db),
+			tickets:    NewGormTicketRepository(db),
+			watch:      NewGormTicketWatchRepository(db),
+			prefs:      NewGormNotificationPreferenceRepository(db),
+		})
+	})
+	t.Run("memory", func(t *testing.T) {
+		m := NewMemory()
+		test(t, stores{
+			users:      m.Users(),
+			identities: m.Identities(),
+			sessions:   m.Sessions(),
+			audit:      m.Audit(),
+			tickets:    m.Tickets(),
+			watch:      m.TicketWatch(),
+			prefs:      m.NotificationPreferences(),
+		})
+	})
+}
+
+func createUser(t *testing.T, users UserRepository, username, email string) *models.User {
+	t.Helper()
+	user := &models.User{Username: username, Email: email, Password: "x", IsActive: true}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func groupNames(user *models.User) []string {
+	var names []string
+	for _, g := range user.Groups {
+		names = append(names, g.Name)
+	}
+	return names
+}
+
+func TestUserRepositoryLookups(t *testing.T) {
+	eachBackend(t, func(t *testing.T, s stores) {
+		ctx := context.Background()
+		alice := createUser(t, s.users, "alice", "alice@example.com")
+		createUser(t, s.users, "bob", "bob@example.com")
+
+		for _, login := range []string{"alice", "alice@example.com"} {
+			user, err := s.users.FindByLogin(ctx, login)
+			if err != nil || user.ID != alice.ID {
+				t.Errorf("FindByLogin(%q) = %v, %v; want alice", login, user, err)
+			}
+		}
+		if _, err := s.users.FindByLogin(ctx, "carol"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindByLogin(unknown) error = %v; want ErrNotFound", err)
+		}
+
+		user, err := s.users.FindByEmail(ctx, "bob@example.com")
+		if err != nil || user.Username != "bob" {
+			t.Errorf("FindByEmail = %v, %v; want bob", user, err)
+		}
+		if _, err := s.users.FindByEmail(ctx, "BOB@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindByEmail must match exactly, got error %v", err)
+		}
+
+		if err := s.users.UpdatePassword(ctx, alice.ID, "new-hash"); err != nil {
+			t.Fatal(err)
+		}
+		if user, _ := s.users.FindByID(ctx, alice.ID); user.Password != "new-hash" {
+			t.Errorf("password after UpdatePassword = %q", user.Password)
+		}
+	})
+}
+
+func TestUserRepositoryGroups(t *testing.T) {
+	eachBackend(t, func(t *testing.T, s stores) {
+		ctx := context.Background()
+		user := createUser(t, s.users, "alice", "alice@example.com")
+
+		for _, name := range []string{"Portal Users", "Agents"} {
+			if err := s.users.AddToGroup(ctx, user, name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.users.RemoveFromGroup(ctx, user, "Agents"); err != nil {
+			t.Fatal(err)
+		}
+		// Grup yang tidak ada atau bukan keanggotaan user tidak error
+		if err := s.users.RemoveFromGroup(ctx, user, "Missing"); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := s.users.FindByID(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := groupNames(found); len(names) != 1 || names[0] != "Portal Users" {
+			t.Errorf("groups = %v; want [Portal Users]", names)
+		}
+	})
+}
+
+func TestIdentityRepository(t *testing.T) {
+	eachBackend(t, func(t *testing.T, s stores) {
+		ctx := context.Background()
+		user := createUser(t, s.users, "alice", "alice@example.com")
+
+		if _, err := s.identities.Find(ctx, "ldap", "uid=alice"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Find before Save error = %v; want ErrNotFound", err)
+		}
+
+		identity := &models.UserIdentity{UserID: user.ID, Provider: "ldap", Subject: "uid=alice", Email: "alice@example.com"}
+		if err := s.identities.Save(ctx, identity); err != nil {
+			t.Fatal(err)
+		}
+		now := time.Now().UTC()
+		identity.LastLoginAt = &now
+		identity.Email = "alice@corp.example.com"
+		if err := s.identities.Save(ctx, identity); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := s.identities.Find(ctx, "ldap", "uid=alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.ID != identity.ID || found.UserID != user.ID || found.Email != "alice@corp.example.com" || found.LastLoginAt == nil {
+			t.Errorf("Find = %+v", found)
+		}
+		if found, err := s.identities.FindForUser(ctx, user.ID, "ldap"); err != nil || found.Subject != "uid=alice" {
+			t.Errorf("FindForUser = %v, %v", found, err)
+		}
+		if _, err := s.identities.FindForUser(ctx, user.ID, "https://idp.example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindForUser(other provider) error = %v; want ErrNotFound", err)
+		}
+
+		duplicate := &models.UserIdentity{UserID: user.ID + 1, Provider: "ldap", Subject: "uid=alice"}
+		if err := s.identities.Save(ctx, duplicate); err == nil {
+			t.Error("Save accepted a duplicate provider and subject")
+		}
+	})
+}
+
+func TestSessionRepository(t *testing.T) {
+	eachBackend(t, func(t *testing.T, s stores) {
+		ctx := context.Background()
+		alice := createUser(t, s.users, "alice", "alice@example.com")
+		bob := createUser(t, s.users, "bob", "bob@example.com")
+
+		base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+		create := func(userID uint, sessionID string, lastSeen time.Time) *models.UserSession {
+			session := &models.UserSession{UserID: userID, SessionID: sessionID, LastSeenAt: lastSeen}
+			if err := s.sessions.Create(ctx, session); err != nil {
+				t.Fatal(err)
+			}
+			return session
+		}
+		older := create(alice.ID, "sess-old", base)
+		newer := create(alice.ID, "sess-new", base.Add(10*time.Minute))
+		create(bob.ID, "sess-bob", base)
+
+		list, err := s.sessions.ListForUser(ctx, alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 || list[0].ID != newer.ID || list[1].ID != older.ID {
+			t.Fatalf("ListForUser = %+v; want newest first", list)
+		}
+
+		if _, err := s.sessions.FindForUser(ctx, older.ID, bob.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindForUser(other user) error = %v; want ErrNotFound", err)
+		}
+
+		// Touch hanya menulis jika LastSeenAt lebih lama dari minInterval
+		touched := base.Add(30 * time.Second)
+		if err := s.sessions.Touch(ctx, "sess-old", touched, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if found, _ := s.sessions.FindForUser(ctx, older.ID, alice.ID); !found.LastSeenAt.Equal(base) {
+			t.Errorf("Touch within interval changed LastSeenAt to %v", found.LastSeenAt)
+		}
+		touched = base.Add(30 * time.Minute)
+		if err := s.sessions.Touch(ctx, "sess-old", touched, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if found, _ := s.sessions.FindForUser(ctx, older.ID, alice.ID); !found.LastSeenAt.Equal(touched) {
+			t.Errorf("LastSeenAt after Touch = %v; want %v", found.LastSeenAt, touched)
+		}
+
+		if err := s.sessions.DeleteBySessionID(ctx, "sess-new"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.sessions.Delete(ctx, older.ID); err != nil {
+			t.Fatal(err)
+		}
+		if list, _ := s.sessions.ListForUser(ctx, alice.ID); len(list) != 0 {
+			t.Errorf("sessions after delete = %+v", list)
+		}
+		if list, _ := s.sessions.ListForUser(ctx, bob.ID); len(list) != 1 {
+			t.Errorf("other user's sessions were deleted: %+v", list)
+		}
+	})
+}
+
+func TestAuditRepository(t *testing.T) {
+	eachBackend(t, func(t *testing.T, s stores) {
+		ctx := context.Background()
+		day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+		events := []models.AuditEvent{
+			{Action: models.AuditLogin, ActorUsername: "alice", TargetType: "user", TargetID: "1", CreatedAt: day.Add(-48 * time.Hour)},
+			{Action: models.AuditLogin, ActorUsername: "bob", TargetType: "user", TargetID: "2", CreatedAt: day},
+			{Action: models.AuditLogout, ActorUsername: "alice", TargetType: "user", TargetID: "1", CreatedAt: day.Add(time.Hour)},
+			{Action: models.AuditLogin, ActorUsername: "alice", TargetType: "user", TargetID: "1", CreatedAt: day.Add(2 * time.Hour)},
+		}
+		for i := range events {
+			if err := s.audit.Create(ctx, &events[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tests := []struct {
+			name   string
+			filter AuditFilter
+			want   []uint
+		}{
+			{"all", AuditFilter{}, []uint{events[3].ID, events[2].ID, events[1].ID, events[0].ID}},
+			{"action", AuditFilter{Action: models.AuditLogin}, []uint{events[3].ID, events[1].ID, events[0].ID}},
+			{"actor and action", AuditFilter{Action: models.AuditLogin, Actor: "alice"}, []uint{events[3].ID, events[0].ID}},
+			{"date range", AuditFilter{From: day.Add(-time.Hour), To: day.Add(2 * time.Hour)}, []uint{events[2].ID, events[1].ID}},
+			{"target", AuditFilter{TargetType: "user", TargetID: "2"}, []uint{events[1].ID}},
+		}
+		for _, tt := range tests {
+			var got []uint
+			err := s.audit.Each(ctx, tt.filter, func(e *models.AuditEvent) error {
+				got = append(got, e.ID)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalIDs(got, tt.want) {
+				t.Errorf("%s: Each = %v; want %v", tt.name, got, tt.want)
+			}
+		}
+
+		page, total, err := s.audit.List(ctx, AuditFilter{Actor: "alice"}, 1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 3 || len(page) != 1 || page[0].ID != events[2].ID {
+			t.Errorf("List page = %d events (total %d); want events[2] of 3", len(page), total)
+		}
+
+		stop := errors.New("stop")
+		calls := 0
+		err = s.audit.Each(ctx, AuditFilter{}, func(*models.AuditEvent) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Each did not stop at first error: err=%v calls=%d", err, calls)
+		}
+	})
+}
+
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTicketWatchRepository(t *testing.T) {
+	eachBackend(t, func(t *testing.T, s stores) {
+		ctx := context.Background()
+		alice := createUser(t, s.users, "alice", "alice@example.com")
+		var tickets []*models.Ticket
+		for _, title := range []string{"Printer", "Email", "VPN"} {
+			ticket := &models.Ticket{Title: title, Description: "x", CreatedByID: alice.ID}
+			if err := s.tickets.Create(ctx, ticket); err != nil {
+				t.Fatal(err)
+			}
+			tickets = append(tickets, ticket)
+		}
+
+		// ChangedSince berhalaman menurut (updated_at, id)
+		page, err := s.tickets.ChangedSince(ctx, time.Time{}, 0, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) != 2 || page[0].ID != tickets[0].ID || page[1].ID != tickets[1].ID {
+			t.Fatalf("first page = %+v", page)
+		}
+		last := page[1]
+		page, err = s.tickets.ChangedSince(ctx, last.UpdatedAt, last.ID, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) != 1 || page[0].ID != tickets[2].ID {
+			t.Fatalf("second page = %+v", page)
+		}
+
+		if _, err := s.watch.Cursor(ctx); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Cursor before Seed error = %v; want ErrNotFound", err)
+		}
+		seeded, err := s.watch.Seed(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seeded.TicketID != tickets[2].ID {
+			t.Errorf("seeded cursor = %+v; want last ticket %d", seeded, tickets[2].ID)
+		}
+		states, err := s.watch.States(ctx, []uint{tickets[0].ID, tickets[2].ID, 9999})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(states) != 2 || states[tickets[0].ID].Status != models.StatusWaiting || states[tickets[0].ID].Version != 1 {
+			t.Errorf("seeded states = %+v", states)
+		}
+
+		// Seed kedua (instance lain) tidak menimpa cursor yang sudah maju
+		moved := *seeded
+		moved.ReplyID = 42
+		if err := s.watch.Advance(ctx, moved); err != nil {
+			t.Fatal(err)
+		}
+		if again, err := s.watch.Seed(ctx); err != nil || again.ReplyID != 42 {
+			t.Errorf("second Seed = %+v, %v; want stored cursor", again, err)
+		}
+		// Advance tidak pernah memundurkan cursor
+		if err := s.watch.Advance(ctx, *seeded); err != nil {
+			t.Fatal(err)
+		}
+		if cursor, _ := s.watch.Cursor(ctx); cursor.ReplyID != 42 || cursor.TicketID != tickets[2].ID {
+			t.Errorf("cursor after stale Advance = %+v", cursor)
+		}
+
+		// SaveState compare-and-set pada Version
+		state := states[tickets[0].ID]
+		stale := *state
+		state.Status = models.StatusInProgress
+		if ok, err := s.watch.SaveState(ctx, state); err != nil || !ok || state.Version != 2 {
+			t.Fatalf("SaveState = %v, %v (version %d); want saved as version 2", ok, err, state.Version)
+		}
+		stale.Status = models.StatusClosed
+		if ok, err := s.watch.SaveState(ctx, &stale); err != nil || ok {
+			t.Errorf("SaveState with stale version = %v, %v; want false", ok, err)
+		}
+		fresh := &models.TicketWatchState{TicketID: 9999, Status: models.StatusWaiting}
+		if ok, err := s.watch.SaveState(ctx, fresh); err != nil || !ok {
+			t.Errorf("SaveState new = %v, %v", ok, err)
+		}
+		if ok, _ := s.watch.SaveState(ctx, &models.TicketWatchState{TicketID: 9999}); ok {
+			t.Errorf("second insert of the same ticket state succeeded")
+		}
+		states, _ = s.watch.States(ctx, []uint{tickets[0].ID})
+		if got := states[tickets[0].ID]; got.Status != models.StatusInProgress || got.Version != 2 {
+			t.Errorf("stored state = %+v", got)
+		}
+	})
+}
+
+func TestNotificationPreferenceClaimDigest(t *testing.T) {
+	eachBackend(t, func(t *testing.T, s stores) {
+		ctx := context.Background()
+		alice := createUser(t, s.users, "alice", "alice@example.com")
+		previous := time.Now().UTC().Add(-25 * time.Hour).Truncate(time.Second)
+		preference := models.DefaultNotificationPreference(alice.ID)
+		preference.DailyDigest = true
+		preference.DigestSentAt = &previous
+		if err := s.prefs.Save(ctx, preference); err != nil {
+			t.Fatal(err)
+		}
+
+		due, err := s.prefs.ListDigestDue(ctx, time.Now().UTC().Add(-24*time.Hour))
+		if err != nil || len(due) != 1 {
+			t.Fatalf("ListDigestDue = %v, %v; want alice", due, err)
+		}
+
+		// Instance kedua yang membaca DigestSentAt lama tidak bisa mengklaim
+		// periode yang sama
+		sentAt := previous.Add(24 * time.Hour)
+		if ok, err := s.prefs.ClaimDigest(ctx, alice.ID, &previous, sentAt); err != nil || !ok {
+			t.Fatalf("first ClaimDigest = %v, %v; want true", ok, err)
+		}
+		if ok, err := s.prefs.ClaimDigest(ctx, alice.ID, &previous, sentAt.Add(time.Second)); err != nil || ok {
+			t.Errorf("second ClaimDigest = %v, %v; want false", ok, err)
+		}
+		if due, _ := s.prefs.ListDigestDue(ctx, time.Now().UTC().Add(-24*time.Hour)); len(due) != 0 {
+			t.Errorf("digest still due after claim: %+v", due)
+		}
+
+		// Ringkasan yang belum pernah dikirim diklaim dari NULL
+		bob := createUser(t, s.users, "bob", "bob@example.com")
+		preference = models.DefaultNotificationPreference(bob.ID)
+		preference.DailyDigest = true
+		if err := s.prefs.Save(ctx, preference); err != nil {
+			t.Fatal(err)
+		}
+		if ok, _ := s.prefs.ClaimDigest(ctx, bob.ID, nil, sentAt); !ok {
+			t.Errorf("ClaimDigest from NULL failed")
+		}
+		if ok, _ := s.prefs.ClaimDigest(ctx, bob.ID, nil, sentAt); ok {
+			t.Errorf("second ClaimDigest from NULL succeeded")
+		}
+	})
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ticketing-fiber/apperrors"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/models"
+	"ticketing-fiber/realtime"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+	"ticketing-fiber/worker"
+)
+
+var ErrNotificationNotFound = apperrors.NotFoundKey("error.notification_not_found")
+
+// NotificationDelivery saluran pengiriman notifikasi di luar aplikasi
+type NotificationDelivery struct {
+	Email    *utils.EmailService
+	Webhooks *utils.WebhookSender
+	Workers  *worker.Group
+	// DefaultTimeZone zona waktu email untuk user yang belum memilih
+	DefaultTimeZone string
+}
+
+// NotificationService notifikasi untuk pemilik tiket: dicatat di aplikasi
+// dan dikirim lewat email atau webhook sesuai pilihan user
+type NotificationService struct {
+	notifications repository.NotificationRepository
+	preferences   repository.NotificationPreferenceRepository
+	users         repository.UserRepository
+	tickets       repository.TicketRepository
+	delivery      NotificationDelivery
+}
+
+func NewNotificationService(notifications repository.NotificationRepository, preferences repository.NotificationPreferenceRepository, users repository.UserRepository, tickets repository.TicketRepository, delivery NotificationDelivery) *NotificationService {
+	return &NotificationService{
+		notifications: notifications,
+		preferences:   preferences,
+		users:         users,
+		tickets:       tickets,
+		delivery:      delivery,
+	}
+}
+
+// webhookPayload body JSON yang dikirim ke webhook user
+type webhookPayload struct {
+	Event       models.NotificationKind `json:"event"`
+	TicketID    uint                    `json:"ticket_id"`
+	TicketTitle string                  `json:"ticket_title"`
+	Message     string                  `json:"message"`
+	Detail      string                  `json:"detail,omitempty"`
+	OccurredAt  time.Time               `json:"occurred_at"`
+}
+
+// Record membuat notifikasi untuk pemilik tiket dari event lalu
+// mengirimkannya sesuai pilihan user. Balasan yang ditulis pemilik tiket
+// sendiri tidak menghasilkan notifikasi, dan event yang sama tidak dicatat
+// atau dikirim dua kali.
+func (s *NotificationService) Record(ctx context.Context, event realtime.Event) error {
+	notification := &models.Notification{
+		UserID:   event.OwnerID,
+		TicketID: event.TicketID,
+	}
+	switch event.Type {
+	case realtime.EventReply:
+		if event.Reply.UserID == event.OwnerID {
+			return nil
+		}
+		notification.Kind = models.NotificationReply
+		notification.Detail = event.Reply.User.GetFullName()
+		notification.DedupeKey = fmt.Sprintf("reply:%d", event.Reply.ID)
+	case realtime.EventStatus:
+		notification.Kind = models.NotificationStatus
+		notification.Detail = string(event.Status)
+		notification.DedupeKey = fmt.Sprintf("status:%d:%s:%d", event.TicketID, event.Status, event.ChangedAt.UnixMilli())
+	case realtime.EventAssignment:
+		notification.Kind = models.NotificationAssignment
+		notification.Detail = event.Department
+		notification.DedupeKey = fmt.Sprintf("assignment:%d:%s:%d", event.TicketID, event.Department, event.ChangedAt.UnixMilli())
+	default:
+		return nil
+	}
+
+	preference, err := s.Preferences(ctx, event.OwnerID)
+	if err != nil {
+		return err
+	}
+	// Notifikasi yang dimatikan di aplikasi tetap disimpan untuk ringkasan
+	notification.InApp = preference.Subscribed(notification.Kind, models.ChannelInApp)
+	created, err := s.notifications.Create(ctx, notification)
+	if err != nil || !created {
+		return err
+	}
+
+	if !preference.Enabled(notification.Kind, models.ChannelWebhook) && !s.emailsDirectly(preference, notification.Kind) {
+		return nil
+	}
+	ticket, err := s.tickets.FindOwned(ctx, event.TicketID, event.OwnerID)
+	if err != nil {
+		return err
+	}
+	s.Deliver(ctx, preference, notification, ticket, event.Reply)
+	return nil
+}
+
+// Deliver mengirim notifikasi lewat webhook dan email di latar belakang.
+// ticket harus berisi CreatedBy, dan reply (berisi User) untuk notifikasi
+// balasan. Email konfirmasi tiket baru dikirim TicketHandler.
+func (s *NotificationService) Deliver(ctx context.Context, preference *models.NotificationPreference, notification *models.Notification, ticket *models.Ticket, reply *models.TicketReply) {
+	owner := ticket.CreatedBy
+	lang := i18n.Resolve(owner.Language, "")
+	message := notification.Message(lang)
+	occurredAt := notification.CreatedAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+
+	if preference.Enabled(notification.Kind, models.ChannelWebhook) {
+		target := preference.WebhookURL
+		payload := webhookPayload{
+			Event:       notification.Kind,
+			TicketID:    ticket.ID,
+			TicketTitle: ticket.Title,
+			Message:     message,
+			Detail:      notification.Detail,
+			OccurredAt:  occurredAt.UTC(),
+		}
+		s.delivery.Workers.Go(ctx, "notification-webhook", func(ctx context.Context) error {
+			if err := s.delivery.Webhooks.Send(ctx, target, payload); err != nil {
+				return fmt.Errorf("failed to send notification webhook: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if !s.emailsDirectly(preference, notification.Kind) {
+		return
+	}
+	loc := i18n.ResolveLocation(owner.TimeZone, s.delivery.DefaultTimeZone)
+	to := ticketRecipient(ticket)
+	if notification.Kind == models.NotificationReply {
+		if reply == nil {
+			return
+		}
+		status := ticket.GetStatusDisplay(lang)
+		replier := reply.User.GetFullName()
+		s.delivery.Workers.Go(ctx, "ticket-reply-email", func(ctx context.Context) error {
+			err := s.delivery.Email.SendTicketReply(ctx, lang, loc, to, owner.GetFullName(), ticket.Title, ticket.ID, status, reply.CreatedAt, reply.Message, replier)
+			if err != nil {
+				return fmt.Errorf("failed to send reply email notification: %w", err)
+			}
+			return nil
+		})
+		return
+	}
+	s.delivery.Workers.Go(ctx, "ticket-update-email", func(ctx context.Context) error {
+		err := s.delivery.Email.SendTicketUpdate(ctx, lang, loc, to, owner.GetFullName(), ticket.Title, ticket.ID, message, occurredAt)
+		if err != nil {
+			return fmt.Errorf("failed to send ticket update email: %w", err)
+		}
+		return nil
+	})
+}
+
+// emailsDirectly true jika Deliver perlu mengirim email untuk jenis ini;
+// email tiket baru dikirim TicketHandler
+func (s *NotificationService) emailsDirectly(preference *models.NotificationPreference, kind models.NotificationKind) bool {
+	if kind == models.NotificationCreated {
+		return false
+	}
+	return preference.Enabled(kind, models.ChannelEmail)
+}
+
+// Preferences pilihan notifikasi user, atau pilihan bawaan jika user belum
+// pernah menyimpannya
+func (s *NotificationService) Preferences(ctx context.Context, userID uint) (*models.NotificationPreference, error) {
+	preference, err := s.preferences.Find(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.DefaultNotificationPreference(userID), nil
+	}
+	return preference, err
+}
+
+// SavePreferences menyimpan pilihan notifikasi user. Ringkasan yang baru
+// diaktifkan dimulai dari sekarang; pembaruan sebelumnya sudah dikirim
+// langsung.
+func (s *NotificationService) SavePreferences(ctx context.Context, preference *models.NotificationPreference) error {
+	current, err := s.Preferences(ctx, preference.UserID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !preference.DailyDigest:
+		preference.DigestSentAt = nil
+	case current.DailyDigest:
+		preference.DigestSentAt = current.DigestSentAt
+	default:
+		now := time.Now().UTC()
+		preference.DigestSentAt = &now
+	}
+	return s.preferences.Save(ctx, preference)
+}
+
+// SendDigests mengirim email ringkasan ke setiap user yang ringkasan
+// terakhirnya sudah lewat interval
+func (s *NotificationService) SendDigests(ctx context.Context, interval time.Duration) error {
+	now := time.Now().UTC()
+	due, err := s.preferences.ListDigestDue(ctx, now.Add(-interval))
+	if err != nil {
+		return err
+	}
+	for _, preference := range due {
+		if err := s.sendDigest(ctx, preference, now.Add(-interval), now); err != nil {
+			slog.ErrorContext(ctx, "failed to send notification digest", "user_id", preference.UserID, "error", err)
+		}
+	}
+	return nil
+}
+
+// sendDigest menandai ringkasan terkirim lebih dulu agar instance lain tidak
+// mengirim ringkasan yang sama; jika email gagal, pembaruannya tidak diulang
+func (s *NotificationService) sendDigest(ctx context.Context, preference *models.NotificationPreference, fallbackSince, now time.Time) error {
+	claimed, err := s.preferences.ClaimDigest(ctx, preference.UserID, preference.DigestSentAt, now)
+	if err != nil || !claimed {
+		return err
+	}
+
+	since := fallbackSince
+	if preference.DigestSentAt != nil {
+		since = *preference.DigestSentAt
+	}
+	notifications, err := s.notifications.ListSince(ctx, preference.UserID, since)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.users.FindByID(ctx, preference.UserID)
+	if err != nil {
+		return err
+	}
+	lang := i18n.Resolve(user.Language, "")
+	loc := i18n.ResolveLocation(user.TimeZone, s.delivery.DefaultTimeZone)
+
+	var items []utils.DigestItem
+	for _, n := range notifications {
+		if !preference.Subscribed(n.Kind, models.ChannelEmail) {
+			continue
+		}
+		items = append(items, utils.DigestItem{
+			Message:     n.Message(lang),
+			TicketTitle: n.Ticket.Title,
+			At:          n.CreatedAt,
+		})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	if err := s.delivery.Email.SendNotificationDigest(ctx, lang, loc, user.Email, user.GetFullName(), items); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "notification digest sent", "user_id", user.ID, "items", len(items))
+	return nil
+}
+
+// List notifikasi terbaru milik user
+func (s *NotificationService) List(ctx context.Context, userID uint, limit int) ([]*models.Notification, error) {
+	return s.notifications.ListForUser(ctx, userID, limit)
+}
+
+// UnreadCount jumlah notifikasi user yang belum dibaca
+func (s *NotificationService) UnreadCount(ctx context.Context, userID uint) (int64, error) {
+	return s.notifications.CountUnread(ctx, userID)
+}
+
+// MarkRead menandai satu notifikasi milik user sudah dibaca
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID uint) (*models.Notification, error) {
+	notification, err := s.notifications.MarkRead(ctx, notificationID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotificationNotFound
+	}
+	return notification, err
+}
+
+// MarkAllRead menandai semua notifikasi user sudah dibaca
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	return s.notifications.MarkAllRead(ctx, userID)
+}
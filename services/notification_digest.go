@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// digestCheckInterval jeda maksimal antar pengecekan ringkasan yang jatuh tempo
+const digestCheckInterval = 5 * time.Minute
+
+// DigestScheduler mengirim email ringkasan notifikasi secara berkala ke user
+// yang memilih ringkasan harian
+type DigestScheduler struct {
+	notifications *NotificationService
+	interval      time.Duration
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewDigestScheduler membuat scheduler ringkasan setiap interval (0 = tanpa
+// ringkasan)
+func NewDigestScheduler(notifications *NotificationService, interval time.Duration) *DigestScheduler {
+	d := &DigestScheduler{
+		notifications: notifications,
+		interval:      interval,
+		done:          make(chan struct{}),
+	}
+	if interval > 0 {
+		go d.loop()
+	}
+	return d
+}
+
+// Close menghentikan pengecekan berkala
+func (d *DigestScheduler) Close() {
+	d.closeOnce.Do(func() { close(d.done) })
+}
+
+func (d *DigestScheduler) loop() {
+	check := min(d.interval, digestCheckInterval)
+	ticker := time.NewTicker(check)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), check)
+			if err := d.notifications.SendDigests(ctx, d.interval); err != nil {
+				slog.Error("notification digest failed", "error", err)
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/models"
+	"ticketing-fiber/realtime"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+	"ticketing-fiber/worker"
+)
+
+// notificationEnv NotificationService di atas repository in-memory dengan
+// email yang hanya dicatat
+type notificationEnv struct {
+	t       *testing.T
+	repos   *repository.Memory
+	mail    *utils.MemoryMailSender
+	workers *worker.Group
+	service *NotificationService
+
+	owner, staff *models.User
+	ticket       *models.Ticket
+}
+
+func newNotificationEnv(t *testing.T) *notificationEnv {
+	t.Helper()
+	cfg := config.Default()
+	cfg.WebhookAllowPrivate = true
+	env := &notificationEnv{
+		t:       t,
+		repos:   repository.NewMemory(),
+		mail:    &utils.MemoryMailSender{},
+		workers: worker.NewGroup(),
+	}
+	env.service = NewNotificationService(env.repos.Notifications(), env.repos.NotificationPreferences(), env.repos.Users(), env.repos.Tickets(), NotificationDelivery{
+		Email:    utils.NewEmailServiceWithSender(cfg, env.mail),
+		Webhooks: utils.NewWebhookSender(cfg),
+		Workers:  env.workers,
+	})
+
+	ctx := context.Background()
+	env.owner = &models.User{Username: "alice", Email: "alice@example.com", Password: "x", IsActive: true}
+	env.staff = &models.User{Username: "agent", Email: "agent@example.com", Password: "x", IsActive: true, IsStaff: true}
+	for _, user := range []*models.User{env.owner, env.staff} {
+		if err := env.repos.Users().Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env.ticket = &models.Ticket{Title: "Printer rusak", Description: "x", CreatedByID: env.owner.ID}
+	if err := env.repos.Tickets().Create(ctx, env.ticket); err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func (e *notificationEnv) savePreference(preference *models.NotificationPreference) {
+	e.t.Helper()
+	if err := e.repos.NotificationPreferences().Save(context.Background(), preference); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+func (e *notificationEnv) reply(user *models.User, message string) *models.TicketReply {
+	e.t.Helper()
+	reply := &models.TicketReply{TicketID: e.ticket.ID, UserID: user.ID, Message: message}
+	if err := e.repos.Tickets().AddReply(context.Background(), reply); err != nil {
+		e.t.Fatal(err)
+	}
+	reply.User = *user
+	return reply
+}
+
+func (e *notificationEnv) record(event realtime.Event) {
+	e.t.Helper()
+	if err := e.service.Record(context.Background(), event); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// sent menunggu semua job latar belakang lalu mengembalikan email terkirim
+func (e *notificationEnv) sent() []utils.SentMail {
+	e.t.Helper()
+	if err := e.workers.Shutdown(context.Background()); err != nil {
+		e.t.Fatal(err)
+	}
+	return e.mail.Sent()
+}
+
+func TestRecordEmailsReplies(t *testing.T) {
+	tests := []struct {
+		name      string
+		preferred func(p *models.NotificationPreference)
+		author    func(env *notificationEnv) *models.User
+		wantEmail bool
+	}{
+		{"staff reply", nil, func(env *notificationEnv) *models.User { return env.staff }, true},
+		{"email reply disabled", func(p *models.NotificationPreference) { p.EmailReply = false }, func(env *notificationEnv) *models.User { return env.staff }, false},
+		{"daily digest", func(p *models.NotificationPreference) { p.DailyDigest = true }, func(env *notificationEnv) *models.User { return env.staff }, false},
+		{"owner's own reply", nil, func(env *notificationEnv) *models.User { return env.owner }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newNotificationEnv(t)
+			if tt.preferred != nil {
+				preference := models.DefaultNotificationPreference(env.owner.ID)
+				tt.preferred(preference)
+				env.savePreference(preference)
+			}
+			reply := env.reply(tt.author(env), "Printer sudah diganti")
+			env.record(realtime.Event{Type: realtime.EventReply, TicketID: env.ticket.ID, OwnerID: env.owner.ID, Reply: reply})
+
+			sent := env.sent()
+			if !tt.wantEmail {
+				if len(sent) != 0 {
+					t.Fatalf("sent %d emails; want none", len(sent))
+				}
+				return
+			}
+			if len(sent) != 1 {
+				t.Fatalf("sent %d emails; want 1", len(sent))
+			}
+			if sent[0].To[0] != env.owner.Email || !strings.Contains(sent[0].Body, "Printer sudah diganti") {
+				t.Errorf("reply email = %+v", sent[0])
+			}
+		})
+	}
+}
+
+// webhookServer menghitung webhook yang diterima
+func webhookServer(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, &count
+}
+
+func TestDeliverByKindAndChannel(t *testing.T) {
+	webhookOn := func(p *models.NotificationPreference) {
+		for _, kind := range models.NotificationKinds {
+			p.Set(kind, models.ChannelWebhook, true)
+		}
+	}
+	tests := []struct {
+		name        string
+		kind        models.NotificationKind
+		preferred   func(p *models.NotificationPreference, webhookURL string)
+		wantEmail   int
+		wantWebhook int32
+	}{
+		// Email tiket baru dikirim TicketHandler
+		{"created default", models.NotificationCreated, nil, 0, 0},
+		{"reply default", models.NotificationReply, nil, 1, 0},
+		{"status default", models.NotificationStatus, nil, 1, 0},
+		{"assignment default", models.NotificationAssignment, nil, 1, 0},
+		{"status email off", models.NotificationStatus, func(p *models.NotificationPreference, _ string) {
+			p.EmailStatus = false
+		}, 0, 0},
+		{"reply digest", models.NotificationReply, func(p *models.NotificationPreference, _ string) {
+			p.DailyDigest = true
+		}, 0, 0},
+		{"assignment digest", models.NotificationAssignment, func(p *models.NotificationPreference, _ string) {
+			p.DailyDigest = true
+		}, 0, 0},
+		{"created webhook", models.NotificationCreated, func(p *models.NotificationPreference, url string) {
+			webhookOn(p)
+			p.WebhookURL = url
+		}, 0, 1},
+		{"status webhook with digest", models.NotificationStatus, func(p *models.NotificationPreference, url string) {
+			webhookOn(p)
+			p.WebhookURL = url
+			p.DailyDigest = true
+		}, 0, 1},
+		{"reply webhook and email", models.NotificationReply, func(p *models.NotificationPreference, url string) {
+			webhookOn(p)
+			p.WebhookURL = url
+		}, 1, 1},
+		{"webhook without URL", models.NotificationStatus, func(p *models.NotificationPreference, _ string) {
+			webhookOn(p)
+			p.EmailStatus = false
+		}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newNotificationEnv(t)
+			url, webhooks := webhookServer(t)
+			preference := models.DefaultNotificationPreference(env.owner.ID)
+			if tt.preferred != nil {
+				tt.preferred(preference, url)
+			}
+			ticket, err := env.repos.Tickets().FindOwned(context.Background(), env.ticket.ID, env.owner.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var reply *models.TicketReply
+			if tt.kind == models.NotificationReply {
+				reply = env.reply(env.staff, "Sedang dicek")
+			}
+
+			env.service.Deliver(context.Background(), preference, &models.Notification{
+				UserID:   env.owner.ID,
+				TicketID: ticket.ID,
+				Kind:     tt.kind,
+				Detail:   string(models.StatusInProgress),
+			}, ticket, reply)
+
+			if sent := env.sent(); len(sent) != tt.wantEmail {
+				t.Errorf("sent %d emails; want %d", len(sent), tt.wantEmail)
+			}
+			if got := webhooks.Load(); got != tt.wantWebhook {
+				t.Errorf("sent %d webhooks; want %d", got, tt.wantWebhook)
+			}
+		})
+	}
+}
+
+func TestDigestSentOncePerPeriod(t *testing.T) {
+	env := newNotificationEnv(t)
+	ctx := context.Background()
+	preference := models.DefaultNotificationPreference(env.owner.ID)
+	preference.DailyDigest = true
+	if err := env.service.SavePreferences(ctx, preference); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pembaruan setelah ringkasan diaktifkan tidak dikirim langsung
+	env.record(realtime.Event{Type: realtime.EventStatus, TicketID: env.ticket.ID, OwnerID: env.owner.ID, Status: models.StatusInProgress, ChangedAt: time.Now()})
+	reply := env.reply(env.staff, "Sedang dicek")
+	env.record(realtime.Event{Type: realtime.EventReply, TicketID: env.ticket.ID, OwnerID: env.owner.ID, Reply: reply})
+
+	// Dua instance membaca ringkasan jatuh tempo yang sama; hanya satu yang
+	// berhasil mengklaimnya
+	due, err := env.repos.NotificationPreferences().ListDigestDue(ctx, time.Now().UTC())
+	if err != nil || len(due) != 1 {
+		t.Fatalf("ListDigestDue = %v, %v; want the owner", due, err)
+	}
+	stale := *due[0]
+	now := time.Now().UTC()
+	for range 2 {
+		snapshot := stale
+		if err := env.service.sendDigest(ctx, &snapshot, now.Add(-24*time.Hour), now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Periode berikutnya belum jatuh tempo
+	if err := env.service.SendDigests(ctx, 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := env.sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d emails; want one digest", len(sent))
+	}
+	if !strings.Contains(sent[0].Body, "Printer rusak") {
+		t.Errorf("digest body = %q", sent[0].Body)
+	}
+	stored, _ := env.service.Preferences(ctx, env.owner.ID)
+	if stored.DigestSentAt == nil || !stored.DigestSentAt.Equal(now) {
+		t.Errorf("DigestSentAt = %v; want %v", stored.DigestSentAt, now)
+	}
+}
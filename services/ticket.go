@@ -0,0 +1,198 @@
+// Package services berisi aturan bisnis yang dipakai handler, di atas
+// repository sehingga bisa diuji dengan repository in-memory.
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"ticketing-fiber/apperrors"
+	"ticketing-fiber/models"
+	"ticketing-fiber/realtime"
+	"ticketing-fiber/repository"
+)
+
+var (
+	ErrTicketNotFound     = apperrors.NotFoundKey("error.ticket_not_found")
+	ErrDepartmentNotFound = apperrors.ValidationKeys("ticket.validation.department_invalid", map[string]string{"department": "ticket.validation.department_not_found"})
+	ErrEmptyReply         = apperrors.ValidationKeys("ticket.validation.reply_required", map[string]string{"message": "ticket.validation.message_required"})
+)
+
+// TicketStats jumlah tiket milik user per status
+type TicketStats struct {
+	Waiting    int64
+	InProgress int64
+	Closed     int64
+	Total      int64
+}
+
+// Active jumlah tiket yang belum ditutup
+func (s TicketStats) Active() int64 {
+	return s.Total - s.Closed
+}
+
+// CreateTicketInput data form pembuatan tiket
+type CreateTicketInput struct {
+	Title        string
+	Description  string
+	ReplyToEmail string
+	Priority     string
+	DepartmentID *uint
+}
+
+type TicketService struct {
+	tickets     repository.TicketRepository
+	departments repository.DepartmentRepository
+	events      *realtime.Hub
+}
+
+// NewTicketService membuat TicketService; events boleh nil jika tidak ada
+// stream realtime (misalnya di perintah CLI)
+func NewTicketService(tickets repository.TicketRepository, departments repository.DepartmentRepository, events *realtime.Hub) *TicketService {
+	return &TicketService{
+		tickets:     tickets,
+		departments: departments,
+		events:      events,
+	}
+}
+
+// Departments daftar departemen tujuan tiket
+func (s *TicketService) Departments(ctx context.Context) ([]models.Department, error) {
+	return s.departments.List(ctx)
+}
+
+// Stats menghitung tiket milik user per status
+func (s *TicketService) Stats(ctx context.Context, userID uint) (TicketStats, error) {
+	counts, err := s.tickets.CountByStatus(ctx, userID)
+	if err != nil {
+		return TicketStats{}, err
+	}
+
+	stats := TicketStats{
+		Waiting:    counts[models.StatusWaiting],
+		InProgress: counts[models.StatusInProgress],
+		Closed:     counts[models.StatusClosed],
+	}
+	for _, n := range counts {
+		stats.Total += n
+	}
+	return stats, nil
+}
+
+// ListForUser daftar tiket milik user sesuai filter
+func (s *TicketService) ListForUser(ctx context.Context, userID uint, filter repository.TicketFilter) ([]*models.Ticket, error) {
+	filter.CreatedByID = userID
+	return s.tickets.List(ctx, filter)
+}
+
+// Recent tiket terbaru milik user
+func (s *TicketService) Recent(ctx context.Context, userID uint, limit int) ([]*models.Ticket, error) {
+	return s.ListForUser(ctx, userID, repository.TicketFilter{Limit: limit})
+}
+
+// GetForUser mengembalikan tiket hanya jika dibuat oleh user tersebut
+func (s *TicketService) GetForUser(ctx context.Context, ticketID, userID uint) (*models.Ticket, error) {
+	ticket, err := s.tickets.FindOwned(ctx, ticketID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTicketNotFound
+	}
+	return ticket, err
+}
+
+// Create memvalidasi input lalu membuat tiket baru berstatus menunggu
+func (s *TicketService) Create(ctx context.Context, user *models.User, input CreateTicketInput) (*models.Ticket, error) {
+	input.Title = strings.TrimSpace(input.Title)
+	input.Description = strings.TrimSpace(input.Description)
+	input.ReplyToEmail = strings.TrimSpace(input.ReplyToEmail)
+
+	fields := make(map[string]string)
+	if input.Title == "" {
+		fields["title"] = "ticket.validation.title_required"
+	}
+	if input.Description == "" {
+		fields["description"] = "ticket.validation.description_required"
+	}
+	if input.ReplyToEmail == "" {
+		fields["reply_to_email"] = "ticket.validation.reply_to_email_required"
+	}
+
+	priority := models.TicketPriority(strings.ToUpper(input.Priority))
+	switch priority {
+	case models.PriorityLow, models.PriorityMedium, models.PriorityHigh:
+	case "":
+		priority = models.PriorityMedium
+	default:
+		fields["priority"] = "ticket.validation.priority_invalid"
+	}
+
+	if len(fields) > 0 {
+		return nil, apperrors.ValidationKeys("ticket.validation.incomplete", fields)
+	}
+
+	if input.DepartmentID != nil {
+		if _, err := s.departments.FindByID(ctx, *input.DepartmentID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, ErrDepartmentNotFound
+			}
+			return nil, err
+		}
+	}
+
+	ticket := &models.Ticket{
+		Title:        input.Title,
+		Description:  input.Description,
+		ReplyToEmail: input.ReplyToEmail,
+		Priority:     priority,
+		Status:       models.StatusWaiting,
+		CreatedByID:  user.ID,
+		DepartmentID: input.DepartmentID,
+	}
+	if err := s.tickets.Create(ctx, ticket); err != nil {
+		return nil, err
+	}
+
+	// Muat ulang agar relasi Department terisi
+	return s.tickets.FindByID(ctx, ticket.ID)
+}
+
+// AddReply menambahkan balasan user ke tiket miliknya
+func (s *TicketService) AddReply(ctx context.Context, user *models.User, ticketID uint, message string) (*models.Ticket, *models.TicketReply, error) {
+	if strings.TrimSpace(message) == "" {
+		return nil, nil, ErrEmptyReply
+	}
+
+	ticket, err := s.GetForUser(ctx, ticketID, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reply := &models.TicketReply{
+		TicketID: ticket.ID,
+		UserID:   user.ID,
+		Message:  message,
+	}
+	if err := s.tickets.AddReply(ctx, reply); err != nil {
+		return nil, nil, err
+	}
+
+	if s.events != nil {
+		reply.User = *user
+		s.events.Publish(realtime.Event{
+			Type:     realtime.EventReply,
+			TicketID: ticket.ID,
+			OwnerID:  ticket.CreatedByID,
+			Reply:    reply,
+		})
+	}
+	return ticket, reply, nil
+}
+
+// ticketRecipient alamat email untuk kabar tentang tiket: ReplyToEmail jika
+// diisi, selain itu email pemilik tiket
+func ticketRecipient(ticket *models.Ticket) string {
+	if ticket.ReplyToEmail != "" {
+		return ticket.ReplyToEmail
+	}
+	return ticket.CreatedBy.Email
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"ticketing-fiber/models"
+	"ticketing-fiber/realtime"
+	"ticketing-fiber/repository"
+)
+
+// watcherBatch jumlah balasan atau tiket maksimal yang diambil per query
+const watcherBatch = 100
+
+// TicketWatcher mengecek database secara berkala untuk balasan baru serta
+// perubahan status dan departemen, termasuk yang tidak lewat TicketService
+// (misalnya ditulis panel staff atau instance lain). Setiap perubahan
+// dicatat sebagai notifikasi pemilik tiket lalu dipublikasikan ke hub;
+// perubahan status dan departemen juga dicatat di audit log.
+// Balasan dari instance ini bisa terkirim dua kali ke stream; client
+// menyaring berdasarkan ID.
+//
+// Posisi pengecekan dan keadaan terakhir tiap tiket disimpan di database
+// (TicketWatchRepository), sehingga setiap pengecekan hanya membaca balasan
+// dan tiket setelah cursor, dan perubahan selama aplikasi mati tetap
+// dinotifikasi saat start berikutnya.
+type TicketWatcher struct {
+	tickets       repository.TicketRepository
+	departments   repository.DepartmentRepository
+	watch         repository.TicketWatchRepository
+	audit         repository.AuditRepository
+	notifications *NotificationService
+	events        *realtime.Hub
+
+	// cursor posisi instance ini, diambil dari database pada pengecekan
+	// pertama. Setiap instance punya cursor sendiri agar semua instance
+	// mempublikasikan event ke client-nya; cursor di database hanya dipakai
+	// sebagai titik awal.
+	cursor *models.TicketWatchCursor
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewTicketWatcher membuat watcher dan menjalankan pengecekan setiap
+// interval (0 = tanpa pengecekan)
+func NewTicketWatcher(tickets repository.TicketRepository, departments repository.DepartmentRepository, watch repository.TicketWatchRepository, audit repository.AuditRepository, notifications *NotificationService, events *realtime.Hub, interval time.Duration) *TicketWatcher {
+	w := &TicketWatcher{
+		tickets:       tickets,
+		departments:   departments,
+		watch:         watch,
+		audit:         audit,
+		notifications: notifications,
+		events:        events,
+		done:          make(chan struct{}),
+	}
+	if interval > 0 {
+		go w.loop(interval)
+	}
+	return w
+}
+
+// Close menghentikan pengecekan berkala
+func (w *TicketWatcher) Close() {
+	w.closeOnce.Do(func() { close(w.done) })
+}
+
+func (w *TicketWatcher) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := w.Poll(ctx); err != nil {
+				slog.Error("realtime poll failed", "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Poll satu kali pengecekan balasan dan tiket setelah cursor, lalu
+// menyimpan cursor yang baru. Jika database belum punya cursor, pengecekan
+// dimulai dari keadaan sekarang tanpa notifikasi untuk data lama.
+func (w *TicketWatcher) Poll(ctx context.Context) error {
+	if w.cursor == nil {
+		cursor, err := w.watch.Cursor(ctx)
+		if errors.Is(err, repository.ErrNotFound) {
+			cursor, err = w.watch.Seed(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		w.cursor = cursor
+	}
+
+	previous := *w.cursor
+	if err := w.pollReplies(ctx); err != nil {
+		return err
+	}
+	if err := w.pollTickets(ctx); err != nil {
+		return err
+	}
+	if *w.cursor == previous {
+		return nil
+	}
+	return w.watch.Advance(ctx, *w.cursor)
+}
+
+func (w *TicketWatcher) pollReplies(ctx context.Context) error {
+	for {
+		replies, err := w.tickets.RepliesAfter(ctx, w.cursor.ReplyID, watcherBatch)
+		if err != nil {
+			return err
+		}
+		for _, reply := range replies {
+			w.emit(ctx, realtime.Event{
+				Type:      realtime.EventReply,
+				TicketID:  reply.TicketID,
+				OwnerID:   reply.Ticket.CreatedByID,
+				Reply:     reply,
+				ChangedAt: reply.CreatedAt,
+			})
+			w.cursor.ReplyID = reply.ID
+		}
+		if len(replies) < watcherBatch {
+			return nil
+		}
+	}
+}
+
+// pollTickets memeriksa tiket yang berubah setelah cursor terhadap
+// keadaan tersimpannya
+func (w *TicketWatcher) pollTickets(ctx context.Context) error {
+	for {
+		tickets, err := w.tickets.ChangedSince(ctx, w.cursor.TicketUpdatedAt, w.cursor.TicketID, watcherBatch)
+		if err != nil {
+			return err
+		}
+		ids := make([]uint, len(tickets))
+		for i, ticket := range tickets {
+			ids[i] = ticket.ID
+		}
+		states, err := w.watch.States(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, ticket := range tickets {
+			if err := w.checkTicket(ctx, ticket, states[ticket.ID]); err != nil {
+				return err
+			}
+			w.cursor.TicketUpdatedAt, w.cursor.TicketID = ticket.UpdatedAt, ticket.ID
+		}
+		if len(tickets) < watcherBatch {
+			return nil
+		}
+	}
+}
+
+// checkTicket membandingkan versi tiket dengan keadaan tersimpan. Tiket
+// tanpa keadaan adalah tiket baru dan hanya dicatat. Versi yang sudah
+// diproses instance lain dipublikasikan ulang dari tanda perubahannya;
+// notifikasinya tidak tercatat dua kali karena DedupeKey memakai ChangedAt.
+func (w *TicketWatcher) checkTicket(ctx context.Context, ticket *models.Ticket, state *models.TicketWatchState) error {
+	// Percobaan kedua hanya terjadi jika instance lain menyimpan lebih dulu
+	for attempt := 0; attempt < 2; attempt++ {
+		var next models.TicketWatchState
+		switch {
+		case state == nil:
+			next = models.TicketWatchState{TicketID: ticket.ID}
+		case state.SeenAt.Before(ticket.UpdatedAt):
+			next = *state
+			next.StatusChanged = state.Status != ticket.Status
+			next.DepartmentChanged = departmentID(state.DepartmentID) != departmentID(ticket.DepartmentID)
+		case state.SeenAt.Equal(ticket.UpdatedAt):
+			w.emitChanges(ctx, ticket, state)
+			return nil
+		default:
+			// Keadaan tersimpan lebih baru; versi ini sudah terlewati
+			return nil
+		}
+		next.Status, next.DepartmentID, next.SeenAt = ticket.Status, ticket.DepartmentID, ticket.UpdatedAt
+
+		saved, err := w.watch.SaveState(ctx, &next)
+		if err != nil {
+			return err
+		}
+		if saved {
+			// Hanya instance yang menyimpan keadaan baru yang menulis audit
+			w.auditChanges(ctx, ticket, state, &next)
+			w.emitChanges(ctx, ticket, &next)
+			return nil
+		}
+
+		states, err := w.watch.States(ctx, []uint{ticket.ID})
+		if err != nil {
+			return err
+		}
+		state = states[ticket.ID]
+	}
+	return nil
+}
+
+// emitChanges mempublikasikan perubahan yang ditandai state untuk ticket
+func (w *TicketWatcher) emitChanges(ctx context.Context, ticket *models.Ticket, state *models.TicketWatchState) {
+	if state.StatusChanged {
+		w.emit(ctx, realtime.Event{
+			Type:      realtime.EventStatus,
+			TicketID:  ticket.ID,
+			OwnerID:   ticket.CreatedByID,
+			Status:    ticket.Status,
+			ChangedAt: ticket.UpdatedAt,
+		})
+	}
+	if state.DepartmentChanged {
+		w.emit(ctx, realtime.Event{
+			Type:       realtime.EventAssignment,
+			TicketID:   ticket.ID,
+			OwnerID:    ticket.CreatedByID,
+			Department: w.departmentName(ctx, departmentID(ticket.DepartmentID)),
+			ChangedAt:  ticket.UpdatedAt,
+		})
+	}
+}
+
+// auditChanges mencatat perubahan status dan departemen dari previous ke
+// next di audit log dengan waktu perubahan tiket
+func (w *TicketWatcher) auditChanges(ctx context.Context, ticket *models.Ticket, previous, next *models.TicketWatchState) {
+	if next.StatusChanged {
+		w.recordAudit(ctx, ticket, models.AuditTicketStatus, "status", previous.Status, next.Status)
+	}
+	if next.DepartmentChanged {
+		before := w.departmentName(ctx, departmentID(previous.DepartmentID))
+		after := w.departmentName(ctx, departmentID(next.DepartmentID))
+		w.recordAudit(ctx, ticket, models.AuditTicketAssignment, "department", before, after)
+	}
+}
+
+func (w *TicketWatcher) recordAudit(ctx context.Context, ticket *models.Ticket, action, field string, before, after interface{}) {
+	changes, err := json.Marshal(map[string]map[string]interface{}{
+		field: {"before": before, "after": after},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode audit changes", "action", action, "error", err)
+		return
+	}
+	event := &models.AuditEvent{
+		CreatedAt:  ticket.UpdatedAt,
+		Action:     action,
+		TargetType: "ticket",
+		TargetID:   strconv.FormatUint(uint64(ticket.ID), 10),
+		Changes:    string(changes),
+	}
+	if err := w.audit.Create(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "failed to write audit event", "action", action, "ticket_id", ticket.ID, "error", err)
+	}
+}
+
+func departmentID(id *uint) uint {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// emit mencatat notifikasi lebih dulu agar jumlah belum dibaca yang dikirim
+// stream sudah termasuk event ini
+func (w *TicketWatcher) emit(ctx context.Context, event realtime.Event) {
+	if err := w.notifications.Record(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "failed to record notification", "type", event.Type, "ticket_id", event.TicketID, "error", err)
+	}
+	w.events.Publish(event)
+}
+
+// departmentName nama departemen, kosong jika tiket tidak punya departemen
+// atau departemen tidak ditemukan
+func (w *TicketWatcher) departmentName(ctx context.Context, id uint) string {
+	if id == 0 {
+		return ""
+	}
+	department, err := w.departments.FindByID(ctx, id)
+	if err != nil {
+		return ""
+	}
+	return department.Name
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ticketing-fiber/config"
+	"ticketing-fiber/migrations"
+	"ticketing-fiber/models"
+	"ticketing-fiber/realtime"
+	"ticketing-fiber/repository"
+	"ticketing-fiber/utils"
+	"ticketing-fiber/worker"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// watcherEnv database SQLite sementara yang dipakai bersama beberapa
+// TicketWatcher, seperti beberapa instance aplikasi atau restart
+type watcherEnv struct {
+	t             *testing.T
+	db            *gorm.DB
+	tickets       repository.TicketRepository
+	notifications repository.NotificationRepository
+	audit         repository.AuditRepository
+	service       *NotificationService
+	owner, staff  *models.User
+}
+
+func newWatcherEnv(t *testing.T) *watcherEnv {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "watcher.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	users := repository.NewGormUserRepository(db)
+	tickets := repository.NewGormTicketRepository(db)
+	notifications := repository.NewGormNotificationRepository(db)
+	workers := worker.NewGroup()
+	t.Cleanup(func() { workers.Shutdown(context.Background()) })
+	service := NewNotificationService(notifications, repository.NewGormNotificationPreferenceRepository(db), users, tickets, NotificationDelivery{
+		Email:   utils.NewEmailServiceWithSender(config.Default(), &utils.MemoryMailSender{}),
+		Workers: workers,
+	})
+
+	env := &watcherEnv{t: t, db: db, tickets: tickets, notifications: notifications, audit: repository.NewGormAuditRepository(db), service: service}
+	env.owner = &models.User{Username: "alice", Email: "alice@example.com", Password: "x", IsActive: true}
+	env.staff = &models.User{Username: "agent", Email: "agent@example.com", Password: "x", IsActive: true, IsStaff: true}
+	for _, user := range []*models.User{env.owner, env.staff} {
+		if err := users.Create(context.Background(), user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return env
+}
+
+// instance watcher baru dengan hub sendiri yang berlangganan tiket pemilik
+func (e *watcherEnv) instance() (*TicketWatcher, *realtime.Subscription) {
+	e.t.Helper()
+	hub := realtime.NewHub()
+	sub, err := hub.Subscribe(realtime.UserTopic(e.owner.ID))
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	e.t.Cleanup(hub.Close)
+	watcher := NewTicketWatcher(e.tickets, repository.NewGormDepartmentRepository(e.db), repository.NewGormTicketWatchRepository(e.db), e.audit, e.service, hub, 0)
+	return watcher, sub
+}
+
+func (e *watcherEnv) poll(w *TicketWatcher) {
+	e.t.Helper()
+	if err := w.Poll(context.Background()); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+func (e *watcherEnv) createTicket(title string) *models.Ticket {
+	e.t.Helper()
+	ticket := &models.Ticket{Title: title, Description: "x", Status: models.StatusWaiting, CreatedByID: e.owner.ID}
+	if err := e.tickets.Create(context.Background(), ticket); err != nil {
+		e.t.Fatal(err)
+	}
+	return ticket
+}
+
+// update mengubah tiket langsung di database seperti panel staff
+func (e *watcherEnv) update(ticket *models.Ticket, values map[string]interface{}) {
+	e.t.Helper()
+	values["updated_at"] = time.Now().UTC()
+	if err := e.db.Model(&models.Ticket{}).Where("id = ?", ticket.ID).Updates(values).Error; err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+func (e *watcherEnv) reply(ticket *models.Ticket, user *models.User, message string) {
+	e.t.Helper()
+	err := e.tickets.AddReply(context.Background(), &models.TicketReply{TicketID: ticket.ID, UserID: user.ID, Message: message})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+func (e *watcherEnv) notificationKinds() []models.NotificationKind {
+	e.t.Helper()
+	list, err := e.notifications.ListForUser(context.Background(), e.owner.ID, 0)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	kinds := make([]models.NotificationKind, len(list))
+	for i, n := range list {
+		kinds[i] = n.Kind
+	}
+	return kinds
+}
+
+// auditChanges perubahan yang tercatat di audit log untuk action
+func (e *watcherEnv) auditChanges(action string) []string {
+	e.t.Helper()
+	var changes []string
+	err := e.audit.Each(context.Background(), repository.AuditFilter{Action: action}, func(event *models.AuditEvent) error {
+		changes = append(changes, event.Changes)
+		return nil
+	})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return changes
+}
+
+// drain event yang sudah dipublikasikan ke sub
+func drain(sub *realtime.Subscription) []realtime.Event {
+	var events []realtime.Event
+	for {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func eventTypes(events []realtime.Event) []realtime.EventType {
+	types := make([]realtime.EventType, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+func equalTypes[T comparable](got []T, want ...T) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTicketWatcherFirstStartSkipsExistingData(t *testing.T) {
+	env := newWatcherEnv(t)
+	ticket := env.createTicket("Printer rusak")
+	env.reply(ticket, env.staff, "Sedang dicek")
+	env.update(ticket, map[string]interface{}{"status": models.StatusInProgress})
+
+	watcher, sub := env.instance()
+	env.poll(watcher)
+	if events := drain(sub); len(events) != 0 {
+		t.Errorf("first start published %v; want nothing for existing data", eventTypes(events))
+	}
+
+	env.update(ticket, map[string]interface{}{"status": models.StatusClosed})
+	env.poll(watcher)
+	events := drain(sub)
+	if !equalTypes(eventTypes(events), realtime.EventStatus) || events[0].Status != models.StatusClosed {
+		t.Errorf("events after status change = %+v", events)
+	}
+}
+
+func TestTicketWatcherNotifiesChangesWhileDown(t *testing.T) {
+	env := newWatcherEnv(t)
+	ticket := env.createTicket("Printer rusak")
+	department := &models.Department{Name: "Hardware"}
+	if err := env.db.Create(department).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	before, _ := env.instance()
+	env.poll(before)
+
+	// Aplikasi mati: staff membalas, mengubah status lalu memindahkan tiket
+	env.reply(ticket, env.staff, "Sedang dicek")
+	env.update(ticket, map[string]interface{}{"status": models.StatusInProgress, "department_id": department.ID})
+
+	after, sub := env.instance()
+	env.poll(after)
+	events := drain(sub)
+	if !equalTypes(eventTypes(events), realtime.EventReply, realtime.EventStatus, realtime.EventAssignment) {
+		t.Fatalf("events after restart = %v; want reply, status, assignment", eventTypes(events))
+	}
+	if events[2].Department != "Hardware" {
+		t.Errorf("assignment department = %q", events[2].Department)
+	}
+	if kinds := env.notificationKinds(); len(kinds) != 3 {
+		t.Errorf("notifications = %v; want 3", kinds)
+	}
+	if got := env.auditChanges(models.AuditTicketStatus); len(got) != 1 || got[0] != `{"status":{"after":"IN_PROGRESS","before":"WAITING"}}` {
+		t.Errorf("status audit = %v", got)
+	}
+	if got := env.auditChanges(models.AuditTicketAssignment); len(got) != 1 || got[0] != `{"department":{"after":"Hardware","before":""}}` {
+		t.Errorf("assignment audit = %v", got)
+	}
+
+	// Cursor tersimpan: restart berikutnya tidak mengulang perubahan yang sama
+	again, sub := env.instance()
+	env.poll(again)
+	if events := drain(sub); len(events) != 0 {
+		t.Errorf("second restart republished %v", eventTypes(events))
+	}
+}
+
+func TestTicketWatcherIgnoresUpdatesWithoutChanges(t *testing.T) {
+	env := newWatcherEnv(t)
+	ticket := env.createTicket("Printer rusak")
+	watcher, sub := env.instance()
+	env.poll(watcher)
+
+	// Balasan memajukan updated_at tiket tanpa mengubah status
+	env.reply(ticket, env.staff, "Sedang dicek")
+	env.update(ticket, map[string]interface{}{"title": "Printer lantai 2 rusak"})
+	env.poll(watcher)
+	if types := eventTypes(drain(sub)); !equalTypes(types, realtime.EventReply) {
+		t.Errorf("events = %v; want only the reply", types)
+	}
+
+	// Tiket baru hanya dicatat, perubahan berikutnya baru dinotifikasi
+	created := env.createTicket("Email tidak masuk")
+	env.poll(watcher)
+	if events := drain(sub); len(events) != 0 {
+		t.Errorf("new ticket published %v", eventTypes(events))
+	}
+	env.update(created, map[string]interface{}{"status": models.StatusClosed})
+	env.poll(watcher)
+	if types := eventTypes(drain(sub)); !equalTypes(types, realtime.EventStatus) {
+		t.Errorf("events after closing new ticket = %v; want status", types)
+	}
+}
+
+func TestTicketWatcherInstancesShareState(t *testing.T) {
+	env := newWatcherEnv(t)
+	ticket := env.createTicket("Printer rusak")
+	first, firstSub := env.instance()
+	second, secondSub := env.instance()
+	env.poll(first)
+	env.poll(second)
+
+	env.update(ticket, map[string]interface{}{"status": models.StatusInProgress})
+	env.poll(first)
+	env.poll(second)
+
+	// Kedua instance mempublikasikan ke client masing-masing, tetapi
+	// notifikasi hanya tercatat sekali
+	for name, sub := range map[string]*realtime.Subscription{"first": firstSub, "second": secondSub} {
+		if types := eventTypes(drain(sub)); !equalTypes(types, realtime.EventStatus) {
+			t.Errorf("%s instance events = %v; want status", name, types)
+		}
+	}
+	if kinds := env.notificationKinds(); !equalTypes(kinds, models.NotificationStatus) {
+		t.Errorf("notifications = %v; want one status notification", kinds)
+	}
+	if got := env.auditChanges(models.AuditTicketStatus); len(got) != 1 {
+		t.Errorf("status audit = %v; want one event", got)
+	}
+}
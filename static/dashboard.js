@@ -54,17 +54,106 @@ function initSidebarToggle() {
     }
 }
 
-// Notification Button
+// Notification Button: dropdown berisi notifikasi terbaru dari /notifikasi
 function initNotificationButton() {
     const notificationBtn = document.getElementById('notificationBtn');
-    if (notificationBtn) {
-        notificationBtn.addEventListener('click', function() {
-            // TODO: Implement notification panel
-            window.commonUtils.showToast('Fitur notifikasi akan segera hadir!', 'info');
+    const panel = document.getElementById('notificationPanel');
+    if (!notificationBtn || !panel) return;
+
+    notificationBtn.addEventListener('click', function(e) {
+        e.stopPropagation();
+        const open = panel.hidden;
+        panel.hidden = !open;
+        notificationBtn.setAttribute('aria-expanded', open);
+        if (open) loadNotifications();
+    });
+
+    document.addEventListener('click', function(e) {
+        if (!panel.hidden && !panel.contains(e.target)) {
+            panel.hidden = true;
+            notificationBtn.setAttribute('aria-expanded', false);
+        }
+    });
+
+    const readAll = document.getElementById('notificationReadAll');
+    if (readAll) {
+        readAll.addEventListener('click', async function() {
+            const data = await notificationRequest('/notifikasi/read-all', 'POST');
+            if (data) {
+                setUnreadCount(data.unread_count);
+                loadNotifications();
+            }
         });
     }
 }
 
+async function notificationRequest(url, method = 'GET') {
+    try {
+        const response = await fetch(url, {
+            method,
+            headers: { 'Accept': 'application/json' },
+            credentials: 'same-origin'
+        });
+        if (!response.ok) return null;
+        return await response.json();
+    } catch (err) {
+        return null;
+    }
+}
+
+async function loadNotifications() {
+    const list = document.getElementById('notificationList');
+    if (!list) return;
+
+    const data = await notificationRequest('/notifikasi?limit=5');
+    list.replaceChildren();
+    if (!data) {
+        list.appendChild(notificationPlaceholder(list.dataset.error));
+        return;
+    }
+    setUnreadCount(data.unread_count);
+    if (data.notifications.length === 0) {
+        list.appendChild(notificationPlaceholder(list.dataset.empty));
+        return;
+    }
+
+    data.notifications.forEach(notification => {
+        const item = document.createElement('li');
+        if (!notification.read) item.className = 'unread';
+
+        const link = document.createElement('a');
+        link.href = notification.url;
+        link.textContent = notification.message;
+        const time = document.createElement('span');
+        time.className = 'notification-time';
+        time.textContent = notification.time_ago;
+        link.appendChild(time);
+
+        // Tandai dibaca sebelum membuka tiket
+        link.addEventListener('click', async function(e) {
+            e.preventDefault();
+            await notificationRequest(`/notifikasi/${notification.id}/read`, 'POST');
+            window.location.href = notification.url;
+        });
+
+        item.appendChild(link);
+        list.appendChild(item);
+    });
+}
+
+function notificationPlaceholder(text) {
+    const item = document.createElement('li');
+    item.className = 'notification-empty';
+    item.textContent = text;
+    return item;
+}
+
+// Badge notifikasi di header, juga dipakai realtime.js
+function setUnreadCount(count) {
+    const badge = document.getElementById('notificationBadge');
+    if (badge) badge.hidden = !count;
+}
+
 // Ticket Item Click Handlers
 function initTicketClickHandlers() {
     const ticketItems = document.querySelectorAll('.ticket-item');
@@ -0,0 +1,78 @@
+// Realtime updates lewat Server-Sent Events (/events).
+// Halaman detail tiket menambahkan balasan baru dan memperbarui status serta
+// departemen; semua halaman memperbarui badge notifikasi (setUnreadCount di
+// dashboard.js) dan jumlah tiket aktif.
+
+document.addEventListener('DOMContentLoaded', function() {
+    const url = document.body.dataset.realtime;
+    if (!url || !window.EventSource) return;
+
+    const detail = document.querySelector('.ticket-detail-container[data-ticket-id]');
+    const ticketId = detail ? Number(detail.dataset.ticketId) : 0;
+    const source = new EventSource(ticketId ? `${url}?ticket=${ticketId}` : url);
+
+    source.addEventListener('reply', function(e) {
+        const data = JSON.parse(e.data);
+        setUnreadCount(data.unread_count);
+        if (data.ticket_id === ticketId) appendReply(data);
+    });
+
+    source.addEventListener('status', function(e) {
+        const data = JSON.parse(e.data);
+        setUnreadCount(data.unread_count);
+        updateActiveCount(data.active_count);
+        if (data.ticket_id === ticketId) updateStatus(data);
+    });
+
+    source.addEventListener('assignment', function(e) {
+        const data = JSON.parse(e.data);
+        setUnreadCount(data.unread_count);
+        if (data.ticket_id === ticketId) {
+            document.querySelectorAll('[data-ticket-department]').forEach(el => {
+                el.textContent = data.department;
+            });
+        }
+    });
+
+    // Tutup koneksi saat pindah halaman agar server tidak menahan stream
+    window.addEventListener('pagehide', () => source.close());
+});
+
+// Balasan bisa terkirim dua kali (dari request dan dari pengecekan
+// database), jadi disaring berdasarkan ID
+function appendReply(data) {
+    const list = document.getElementById('replyList');
+    if (!list || list.querySelector(`[data-reply-id="${data.reply_id}"]`)) return;
+
+    const template = document.createElement('template');
+    template.innerHTML = data.html.trim();
+    list.appendChild(template.content);
+
+    const count = list.querySelectorAll('[data-reply-id]').length;
+    const title = document.getElementById('repliesTitle');
+    if (title) title.textContent = title.dataset.label.replace('%d', count);
+    const total = document.getElementById('replyTotal');
+    if (total) total.textContent = count;
+    const card = document.getElementById('repliesCard');
+    if (card) card.hidden = false;
+}
+
+function updateStatus(data) {
+    document.querySelectorAll('[data-ticket-status]').forEach(badge => {
+        badge.className = `status-badge ${data.class}`;
+        badge.textContent = data.label;
+    });
+
+    const closed = data.status === 'CLOSED';
+    const form = document.getElementById('replyFormCard');
+    if (form) form.hidden = closed;
+    const notice = document.getElementById('closedNotice');
+    if (notice) notice.hidden = !closed;
+}
+
+function updateActiveCount(count) {
+    const badge = document.getElementById('activeTicketsBadge');
+    if (!badge) return;
+    badge.textContent = count;
+    badge.hidden = !count;
+}
@@ -0,0 +1,112 @@
+// Package tracing menyiapkan OpenTelemetry tracing: tracer provider dengan
+// exporter OTLP (HTTP) atau stdout, middleware Fiber, dan callback GORM yang
+// membuat span untuk setiap query.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gofiber/contrib/otelfiber/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"ticketing-fiber/config"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// instrumentationName nama tracer untuk span buatan aplikasi
+const instrumentationName = "ticketing-fiber"
+
+// Tracer tracer aplikasi dari tracer provider global
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup memasang tracer provider global sesuai cfg.TracingExporter.
+// Fungsi yang dikembalikan mem-flush dan menutup exporter; dengan exporter
+// "none" provider global tetap no-op.
+func Setup(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.TracingExporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		// Tanpa endpoint, exporter membaca OTEL_EXPORTER_OTLP_* sendiri
+		if cfg.TracingOTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.TracingOTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.TracingExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.TracingExporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.TracingServiceName),
+		semconv.DeploymentEnvironmentName(cfg.Environment),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Middleware membuat span server untuk setiap request Fiber, kecuali path
+// yang dilewati (health check dan metrics)
+func Middleware(skipPaths ...string) fiber.Handler {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return otelfiber.Middleware(
+		otelfiber.WithNext(func(c *fiber.Ctx) bool {
+			return skip[c.Path()]
+		}),
+		otelfiber.WithoutMetrics(true),
+		otelfiber.WithSpanNameFormatter(func(c *fiber.Ctx) string {
+			return utils.CopyString(c.Method()) + " " + c.Route().Path
+		}),
+		otelfiber.WithCustomAttributes(func(c *fiber.Ctx) []attribute.KeyValue {
+			if id, ok := c.Locals("request_id").(string); ok {
+				return []attribute.KeyValue{attribute.String("request.id", id)}
+			}
+			return nil
+		}),
+	)
+}
@@ -1,32 +1,96 @@
 package utils
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/smtp"
 	"strings"
 	"time"
 
 	"ticketing-fiber/config"
+	"ticketing-fiber/i18n"
+	"ticketing-fiber/logging"
+	"ticketing-fiber/metrics"
+	"ticketing-fiber/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// MailSender mengirim satu email plain text. EmailService menyusun isi email,
+// MailSender yang mengirimkannya sehingga transport bisa diganti (misalnya
+// MemoryMailSender saat pengujian). ctx membawa request ID dan user ID yang
+// ditulis ke header email (lihat MailHeaders).
+type MailSender interface {
+	SendMail(ctx context.Context, to []string, subject, body string) error
+}
+
+// MailHeaders header tambahan untuk email yang dikirim dalam konteks request,
+// agar email bisa ditelusuri ke log request yang memicunya
+func MailHeaders(ctx context.Context) map[string]string {
+	headers := make(map[string]string)
+	if id := logging.RequestID(ctx); id != "" {
+		headers["X-Request-ID"] = id
+	}
+	if id := logging.UserID(ctx); id != 0 {
+		headers["X-User-ID"] = fmt.Sprint(id)
+	}
+	return headers
+}
+
 type EmailService struct {
-	cfg *config.Config
+	cfg    *config.Config
+	sender MailSender
 }
 
 func NewEmailService(cfg *config.Config) *EmailService {
-	return &EmailService{cfg: cfg}
+	return NewEmailServiceWithSender(cfg, NewSMTPSender(cfg))
+}
+
+// NewEmailServiceWithSender membuat EmailService dengan transport tertentu
+func NewEmailServiceWithSender(cfg *config.Config, sender MailSender) *EmailService {
+	return &EmailService{cfg: cfg, sender: sender}
+}
+
+// SendMail mengirim email lewat MailSender yang dipakai service
+func (e *EmailService) SendMail(ctx context.Context, to []string, subject, body string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "email.send")
+	defer span.End()
+	span.SetAttributes(
+		attribute.Int("email.recipients", len(to)),
+		attribute.String("email.subject", subject),
+	)
+
+	if err := e.sender.SendMail(ctx, to, subject, body); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		metrics.EmailsFailed.Inc()
+		return err
+	}
+	metrics.EmailsSent.Inc()
+	return nil
+}
+
+// SMTPSender mengirim email ke server SMTP dari konfigurasi
+type SMTPSender struct {
+	cfg *config.Config
+}
+
+func NewSMTPSender(cfg *config.Config) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
 }
 
 // SendMail mengirim email menggunakan net/smtp standar (Lebih stabil untuk STARTTLS)
-func (e *EmailService) SendMail(to []string, subject, body string) error {
+func (e *SMTPSender) SendMail(ctx context.Context, to []string, subject, body string) error {
 	// 1. Setup Alamat Server
 	addr := fmt.Sprintf("%s:%d", e.cfg.EmailHost, e.cfg.EmailPort)
 
 	// 2. Setup Header Email (MIME)
 	// Penting agar body email terbaca rapi
-	headers := make(map[string]string)
+	headers := MailHeaders(ctx)
 	headers["From"] = e.cfg.EmailFrom
 	headers["To"] = strings.Join(to, ",")
 	headers["Subject"] = subject
@@ -45,27 +109,44 @@ func (e *EmailService) SendMail(to []string, subject, body string) error {
 	retryDelay := 2 * time.Second
 
 	for i := 0; i < maxRetries; i++ {
-		log.Printf("[Email] Percobaan kirim %d/%d ke %v...", i+1, maxRetries, to)
+		slog.DebugContext(ctx, "sending email", "to", to, "attempt", i+1, "max_attempts", maxRetries)
 
 		err := e.sendSMTPSecure(addr, e.cfg.EmailHost, e.cfg.EmailUsername, e.cfg.EmailPassword, to, []byte(message))
 
 		if err == nil {
-			log.Printf("✅ Email BERHASIL dikirim ke: %v", to)
+			slog.InfoContext(ctx, "email sent", "to", to, "subject", subject)
 			return nil
 		}
 
-		log.Printf("⚠️ Gagal kirim (Coba %d): %v", i+1, err)
+		slog.WarnContext(ctx, "email send attempt failed", "to", to, "attempt", i+1, "error", err)
 
 		if i < maxRetries-1 {
-			time.Sleep(retryDelay)
+			select {
+			case <-time.After(retryDelay):
+			case <-ctx.Done():
+				return fmt.Errorf("email to %v cancelled: %w", to, ctx.Err())
+			}
 		}
 	}
 
 	return fmt.Errorf("gagal mengirim email setelah %d percobaan", maxRetries)
 }
 
+// Ping mengecek server SMTP bisa dihubungi dengan membuka koneksi TCP
+func (e *SMTPSender) Ping(ctx context.Context) error {
+	if e.cfg.EmailHost == "" {
+		return fmt.Errorf("email host is not configured")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", e.cfg.EmailHost, e.cfg.EmailPort))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
 // sendSMTPSecure menangani koneksi SMTP dengan STARTTLS secara manual
-func (e *EmailService) sendSMTPSecure(addr, host, user, password string, to []string, msg []byte) error {
+func (e *SMTPSender) sendSMTPSecure(addr, host, user, password string, to []string, msg []byte) error {
 	// A. Connect ke Server (Koneksi Awal Polos)
 	// Timeout dialer agar tidak hanging selamanya jika server down
 	c, err := smtp.Dial(addr)
@@ -123,56 +204,56 @@ func (e *EmailService) sendSMTPSecure(addr, host, user, password string, to []st
 	return c.Quit()
 }
 
-// --- Helper Methods (Tetap sama untuk menjaga kompatibilitas dengan Handler) ---
-
-func (e *EmailService) SendTicketConfirmation(to, username, title string, ticketID uint, department, priority, status, description string) error {
-	subject := fmt.Sprintf("[Ticket ID: %d] %s", ticketID, title)
-
-	body := fmt.Sprintf(`Halo %s,
-
-Terima kasih telah menghubungi kami. Tiket Anda telah berhasil dibuat dengan rincian berikut:
+// SendTicketConfirmation email konfirmasi tiket baru dalam bahasa lang;
+// waktu ditulis dalam zona waktu loc milik penerima
+func (e *EmailService) SendTicketConfirmation(ctx context.Context, lang string, loc *time.Location, to, username, title string, ticketID uint, createdAt time.Time, department, priority, status, description string) error {
+	subject := i18n.T(lang, "email.ticket_confirmation.subject", ticketID, title)
+	body := i18n.T(lang, "email.ticket_confirmation.body", username, ticketID, title, department, priority, status, emailTime(lang, loc, createdAt), description)
 
-ID Tiket  : %d
-Judul     : %s
-Departemen: %s
-Prioritas : %s
-Status    : %s
-
-Deskripsi:
-%s
-
----
-Tim support kami akan segera meninjau tiket Anda.
-Mohon menunggu balasan dari tim support melalui email ini.
-
-Salam,
-Tim Support`, username, ticketID, title, department, priority, status, description)
-
-	return e.SendMail([]string{to}, subject, body)
+	return e.SendMail(ctx, []string{to}, subject, body)
 }
 
-func (e *EmailService) SendTicketReply(to, username, title string, ticketID uint, status, replyMessage, replierName string) error {
-	subject := fmt.Sprintf("RE: [Ticket ID: %d] %s", ticketID, title)
-
-	body := fmt.Sprintf(`Halo %s,
+// SendTicketReply email notifikasi balasan tiket dalam bahasa lang;
+// waktu ditulis dalam zona waktu loc milik penerima
+func (e *EmailService) SendTicketReply(ctx context.Context, lang string, loc *time.Location, to, username, title string, ticketID uint, status string, repliedAt time.Time, replyMessage, replierName string) error {
+	subject := i18n.T(lang, "email.ticket_reply.subject", ticketID, title)
+	body := i18n.T(lang, "email.ticket_reply.body", username, replierName, replyMessage, ticketID, title, status, emailTime(lang, loc, repliedAt), replierName)
 
-Tim support kami (%s) telah membalas tiket Anda:
+	return e.SendMail(ctx, []string{to}, subject, body)
+}
 
----
-%s
----
+// SendTicketUpdate email notifikasi perubahan tiket (status atau
+// pengalihan) dalam bahasa lang; message isi notifikasi yang sudah diterjemahkan
+func (e *EmailService) SendTicketUpdate(ctx context.Context, lang string, loc *time.Location, to, username, title string, ticketID uint, message string, changedAt time.Time) error {
+	subject := i18n.T(lang, "email.ticket_update.subject", ticketID, title)
+	body := i18n.T(lang, "email.ticket_update.body", username, message, ticketID, title, emailTime(lang, loc, changedAt))
 
-Detail Tiket:
+	return e.SendMail(ctx, []string{to}, subject, body)
+}
 
-ID Tiket    : %d
-Judul       : %s
-Status      : %s
+// DigestItem satu baris email ringkasan
+type DigestItem struct {
+	Message     string
+	TicketTitle string
+	At          time.Time
+}
 
-Silakan balas email ini jika ada pertanyaan tambahan.
+// SendNotificationDigest satu email ringkasan berisi semua pembaruan tiket
+// sejak ringkasan sebelumnya
+func (e *EmailService) SendNotificationDigest(ctx context.Context, lang string, loc *time.Location, to, username string, items []DigestItem) error {
+	var lines strings.Builder
+	for _, item := range items {
+		lines.WriteString(i18n.T(lang, "email.digest.item", emailTime(lang, loc, item.At), item.Message, item.TicketTitle))
+		lines.WriteString("\n")
+	}
+	subject := i18n.T(lang, "email.digest.subject", len(items))
+	body := i18n.T(lang, "email.digest.body", username, len(items), lines.String())
 
-Salam,
-%s
-Tim Support`, username, replierName, replyMessage, ticketID, title, status, replierName)
+	return e.SendMail(ctx, []string{to}, subject, body)
+}
 
-	return e.SendMail([]string{to}, subject, body)
+// emailTime waktu lengkap dengan singkatan zona (misalnya "WIB"), karena
+// email bisa dibaca jauh setelah dikirim dan di luar konteks aplikasi
+func emailTime(lang string, loc *time.Location, t time.Time) string {
+	return i18n.FormatDate(lang, loc, t) + " " + t.In(loc).Format("MST")
 }
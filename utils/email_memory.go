@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// SentMail email yang dicatat oleh MemoryMailSender
+type SentMail struct {
+	To      []string
+	Subject string
+	Body    string
+	// Headers header tambahan dari MailHeaders (X-Request-ID, X-User-ID)
+	Headers map[string]string
+}
+
+// MemoryMailSender MailSender yang hanya menyimpan email di memori, untuk
+// pengujian dan development tanpa server SMTP
+type MemoryMailSender struct {
+	mu   sync.Mutex
+	sent []SentMail
+}
+
+func (m *MemoryMailSender) SendMail(ctx context.Context, to []string, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, SentMail{
+		To:      append([]string(nil), to...),
+		Subject: subject,
+		Body:    body,
+		Headers: MailHeaders(ctx),
+	})
+	return nil
+}
+
+// Sent salinan semua email yang sudah "dikirim"
+func (m *MemoryMailSender) Sent() []SentMail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]SentMail(nil), m.sent...)
+}
+
+// Reset menghapus catatan email
+func (m *MemoryMailSender) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = nil
+}
@@ -1,17 +1,246 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ticketing-fiber/config"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword generates bcrypt hash from plain password
+// Algoritma hash password yang didukung
+const (
+	HashBcrypt   = "bcrypt"
+	HashArgon2id = "argon2id"
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// PasswordHasher membuat dan memverifikasi hash password dalam format
+// yang menyimpan algoritma dan parameternya sendiri:
+//
+//	bcrypt   : $2a$<cost>$<salt+hash>
+//	argon2id : $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>
+//
+// sehingga hash lama tetap bisa diverifikasi setelah parameter diganti.
+type PasswordHasher struct {
+	Algorithm string
+
+	BcryptCost int
+
+	Argon2Memory  uint32 // KiB
+	Argon2Time    uint32
+	Argon2Threads uint8
+	Argon2KeyLen  uint32
+	Argon2SaltLen uint32
+}
+
+// DefaultPasswordHasher dipakai oleh HashPassword dan CheckPasswordHash
+var DefaultPasswordHasher = &PasswordHasher{
+	Algorithm:     HashArgon2id,
+	BcryptCost:    bcrypt.DefaultCost,
+	Argon2Memory:  64 * 1024,
+	Argon2Time:    3,
+	Argon2Threads: 2,
+	Argon2KeyLen:  32,
+	Argon2SaltLen: 16,
+}
+
+// NewPasswordHasher membuat hasher dari konfigurasi aplikasi
+func NewPasswordHasher(cfg *config.Config) *PasswordHasher {
+	h := *DefaultPasswordHasher
+	if cfg.PasswordHashAlgorithm != "" {
+		h.Algorithm = cfg.PasswordHashAlgorithm
+	}
+	if cfg.BcryptCost > 0 {
+		h.BcryptCost = cfg.BcryptCost
+	}
+	if cfg.Argon2Memory > 0 {
+		h.Argon2Memory = cfg.Argon2Memory
+	}
+	if cfg.Argon2Time > 0 {
+		h.Argon2Time = cfg.Argon2Time
+	}
+	if cfg.Argon2Threads > 0 {
+		h.Argon2Threads = cfg.Argon2Threads
+	}
+	return &h
+}
+
+// bcryptMaxBytes panjang password maksimal yang diterima bcrypt
+const bcryptMaxBytes = 72
+
+// Policy kebijakan password base dengan batas panjang dari algoritma hash,
+// agar password yang terlalu panjang ditolak sebagai pelanggaran kebijakan
+// dan bukan gagal saat Hash
+func (h *PasswordHasher) Policy(base PasswordPolicy) PasswordPolicy {
+	if h.Algorithm == HashBcrypt {
+		base.MaxBytes = bcryptMaxBytes
+	}
+	return base
+}
+
+// Hash membuat hash password dengan algoritma dan parameter saat ini
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	switch h.Algorithm {
+	case HashBcrypt:
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.BcryptCost)
+		return string(bytes), err
+	case HashArgon2id:
+		salt := make([]byte, h.Argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, h.Argon2Time, h.Argon2Memory, h.Argon2Threads, h.Argon2KeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, h.Argon2Memory, h.Argon2Time, h.Argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key)), nil
+	default:
+		return "", fmt.Errorf("unsupported password hash algorithm %q", h.Algorithm)
+	}
+}
+
+// Verify membandingkan password dengan hash dalam format apa pun yang didukung
+func (h *PasswordHasher) Verify(password, encoded string) (bool, error) {
+	switch {
+	case !IsUsablePassword(encoded):
+		return false, nil
+	case isBcryptHash(encoded):
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1, nil
+	default:
+		return false, ErrUnknownHashFormat
+	}
+}
+
+// NeedsRehash mengecek apakah hash memakai algoritma atau parameter yang sudah
+// tidak sesuai konfigurasi, sehingga perlu dibuat ulang saat user login
+func (h *PasswordHasher) NeedsRehash(encoded string) bool {
+	switch {
+	case isBcryptHash(encoded):
+		if h.Algorithm != HashBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost < h.BcryptCost
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		if h.Algorithm != HashArgon2id {
+			return true
+		}
+		params, _, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return true
+		}
+		return params.version != argon2.Version ||
+			params.memory < h.Argon2Memory ||
+			params.time < h.Argon2Time ||
+			params.threads != h.Argon2Threads ||
+			uint32(len(key)) < h.Argon2KeyLen
+	default:
+		return true
+	}
+}
+
+// HashPassword generates password hash using DefaultPasswordHasher
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	return DefaultPasswordHasher.Hash(password)
 }
 
 // CheckPasswordHash compares plain password with hash
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	ok, _ := DefaultPasswordHasher.Verify(password, hash)
+	return ok
+}
+
+// UnusablePasswordPrefix menandai akun yang tidak bisa login dengan password
+// (misalnya akun yang dibuat lewat SSO)
+const UnusablePasswordPrefix = "!"
+
+// UnusablePassword membuat nilai password acak yang tidak akan pernah cocok
+func UnusablePassword() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	return UnusablePasswordPrefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+// IsUsablePassword mengecek apakah hash bisa dipakai untuk login dengan password
+func IsUsablePassword(encoded string) bool {
+	return encoded != "" && !strings.HasPrefix(encoded, UnusablePasswordPrefix)
+}
+
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+// Batas parameter argon2id yang diterima dari hash tersimpan. Salt atau key
+// kosong membuat perbandingan selalu cocok, dan parameter yang terlalu besar
+// bisa menghabiskan memori/CPU saat verifikasi.
+const (
+	argon2MinSaltLen = 8
+	argon2MinKeyLen  = 16
+	argon2MaxKeyLen  = 1024
+	argon2MaxMemory  = 4 * 1024 * 1024 // KiB (4 GiB)
+	argon2MaxTime    = 100
+)
+
+type argon2Params struct {
+	version int
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+func decodeArgon2id(encoded string) (argon2Params, []byte, []byte, error) {
+	var p argon2Params
+
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrUnknownHashFormat
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &p.version); err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if p.version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2id version %d", p.version)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	if p.threads < 1 || p.time < 1 || p.time > argon2MaxTime ||
+		p.memory < 8*uint32(p.threads) || p.memory > argon2MaxMemory {
+		return p, nil, nil, fmt.Errorf("argon2id parameters out of range: m=%d,t=%d,p=%d", p.memory, p.time, p.threads)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id hash: %w", err)
+	}
+	if len(salt) < argon2MinSaltLen || len(key) < argon2MinKeyLen || len(key) > argon2MaxKeyLen {
+		return p, nil, nil, fmt.Errorf("invalid argon2id salt or hash length")
+	}
+	return p, salt, key, nil
 }
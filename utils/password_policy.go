@@ -0,0 +1,266 @@
+package utils
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"ticketing-fiber/i18n"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+// Kode pelanggaran kebijakan password
+const (
+	PasswordTooShort       = "too_short"
+	PasswordTooLong        = "too_long"
+	PasswordTooFewClasses  = "too_few_classes"
+	PasswordTooSimilar     = "too_similar"
+	PasswordTooCommon      = "too_common"
+	PasswordEntirelyNumber = "entirely_numeric"
+)
+
+// PasswordPolicy berisi aturan password yang dipakai registrasi,
+// ubah password dan reset password
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+	// MaxBytes batas panjang password dalam byte UTF-8 dari algoritma hash
+	// (bcrypt hanya menerima 72 byte); 0 berarti tanpa batas
+	MaxBytes int
+
+	// MinCharClasses jumlah minimal jenis karakter yang dipakai
+	// (huruf kecil, huruf besar, angka, simbol)
+	MinCharClasses int
+
+	CheckSimilarity bool
+	CheckCommon     bool
+}
+
+// DefaultPasswordPolicy kebijakan password standar aplikasi
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:       8,
+	MaxLength:       128,
+	MinCharClasses:  2,
+	CheckSimilarity: true,
+	CheckCommon:     true,
+}
+
+// PasswordUserInfo data user yang tidak boleh mirip dengan password
+type PasswordUserInfo struct {
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+// PasswordViolation satu aturan yang dilanggar
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError dikembalikan jika password melanggar satu atau lebih aturan
+type PasswordPolicyError struct {
+	Violations []PasswordViolation `json:"violations"`
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return strings.Join(e.Messages(), " ")
+}
+
+// Messages mengembalikan pesan pelanggaran untuk ditampilkan ke user
+func (e *PasswordPolicyError) Messages() []string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return messages
+}
+
+// Has mengecek apakah pelanggaran dengan kode tertentu terjadi
+func (e *PasswordPolicyError) Has(code string) bool {
+	for _, v := range e.Violations {
+		if v.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate memeriksa password terhadap kebijakan, mengembalikan
+// *PasswordPolicyError jika ada aturan yang dilanggar. Pesan pelanggaran
+// memakai bahasa lang.
+func (p PasswordPolicy) Validate(lang, password string, info PasswordUserInfo) error {
+	var violations []PasswordViolation
+	add := func(code string, args ...interface{}) {
+		violations = append(violations, PasswordViolation{
+			Code:    code,
+			Message: i18n.T(lang, "password."+code, args...),
+		})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		add(PasswordTooShort, p.MinLength)
+	}
+	switch {
+	case p.MaxLength > 0 && length > p.MaxLength:
+		add(PasswordTooLong, p.MaxLength)
+	case p.MaxBytes > 0 && len(password) > p.MaxBytes:
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordTooLong,
+			Message: i18n.T(lang, "password.too_long_bytes", p.MaxBytes),
+		})
+	}
+
+	if length > 0 && isNumeric(password) {
+		add(PasswordEntirelyNumber)
+	} else if p.MinCharClasses > 0 && charClasses(password) < p.MinCharClasses {
+		add(PasswordTooFewClasses, p.MinCharClasses)
+	}
+
+	if p.CheckSimilarity {
+		if attr := similarAttribute(password, info); attr != "" {
+			add(PasswordTooSimilar, i18n.T(lang, "password.attr."+attr))
+		}
+	}
+
+	if p.CheckCommon && IsCommonPassword(password) {
+		add(PasswordTooCommon)
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Requirements mengembalikan daftar aturan dalam bentuk teks untuk ditampilkan di form
+func (p PasswordPolicy) Requirements(lang string) []string {
+	var reqs []string
+	if p.MinLength > 0 {
+		reqs = append(reqs, i18n.T(lang, "password.req.min_length", p.MinLength))
+	}
+	if p.MinCharClasses > 1 {
+		reqs = append(reqs, i18n.T(lang, "password.req.char_classes", p.MinCharClasses))
+	}
+	if p.CheckSimilarity {
+		reqs = append(reqs, i18n.T(lang, "password.req.similarity"))
+	}
+	if p.CheckCommon {
+		reqs = append(reqs, i18n.T(lang, "password.req.common"))
+	}
+	return reqs
+}
+
+// IsCommonPassword mengecek password terhadap daftar password umum (case-insensitive)
+func IsCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(loadCommonPasswords)
+	_, found := commonPasswords[strings.ToLower(strings.TrimSpace(password))]
+	return found
+}
+
+func loadCommonPasswords() {
+	commonPasswords = make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(commonPasswordsFile))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commonPasswords[strings.ToLower(line)] = struct{}{}
+	}
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func charClasses(s string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// similarAttribute mengembalikan nama atribut user yang mirip dengan password
+// (suffix key katalog password.attr.*), atau string kosong jika tidak ada
+func similarAttribute(password string, info PasswordUserInfo) string {
+	pw := normalizeForSimilarity(password)
+	if pw == "" {
+		return ""
+	}
+
+	localPart := info.Email
+	if at := strings.Index(localPart, "@"); at >= 0 {
+		localPart = localPart[:at]
+	}
+
+	attrs := []struct {
+		name  string
+		value string
+	}{
+		{"username", info.Username},
+		{"email", localPart},
+		{"first_name", info.FirstName},
+		{"last_name", info.LastName},
+	}
+
+	for _, attr := range attrs {
+		value := normalizeForSimilarity(attr.value)
+		if len(value) < 3 {
+			continue
+		}
+		// Mirip jika salah satu memuat yang lain dan sebagian besar password
+		// berasal dari atribut tersebut
+		if strings.Contains(pw, value) && len(value)*2 >= len(pw) {
+			return attr.name
+		}
+		if strings.Contains(value, pw) {
+			return attr.name
+		}
+	}
+	return ""
+}
+
+func normalizeForSimilarity(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
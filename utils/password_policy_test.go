@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ticketing-fiber/i18n"
+)
+
+// violationCodes kode pelanggaran dari Validate; nil jika password diterima
+func violationCodes(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Validate error = %T %v; want *PasswordPolicyError", err, err)
+	}
+	codes := make([]string, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	alice := PasswordUserInfo{Username: "alice", Email: "alice.w@example.com", FirstName: "Alice", LastName: "Wonderland"}
+	bcryptPolicy := testHasher(HashBcrypt).Policy(DefaultPasswordPolicy)
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		info     PasswordUserInfo
+		want     []string
+	}{
+		{"valid", DefaultPasswordPolicy, "Correct-horse-42", alice, nil},
+		{"too short", DefaultPasswordPolicy, "Ab1-x", alice, []string{PasswordTooShort}},
+		{"min length counts runes", DefaultPasswordPolicy, "ÄÖÜäöü12", PasswordUserInfo{}, nil},
+		{"too long", DefaultPasswordPolicy, "Aa1-" + strings.Repeat("x", 125), alice, []string{PasswordTooLong}},
+		{"max length", DefaultPasswordPolicy, "Aa1-" + strings.Repeat("x", 124), alice, nil},
+		{"entirely numeric", DefaultPasswordPolicy, "9183746502", alice, []string{PasswordEntirelyNumber}},
+		{"one character class", DefaultPasswordPolicy, "zxcvbnmasd", alice, []string{PasswordTooFewClasses}},
+		{"common", DefaultPasswordPolicy, "Password1", alice, []string{PasswordTooCommon}},
+		{"common ignores case", DefaultPasswordPolicy, "QWERTY123", PasswordUserInfo{}, []string{PasswordTooCommon}},
+		{"similar to username", DefaultPasswordPolicy, "Alice2024!", alice, []string{PasswordTooSimilar}},
+		{"similar to email local part", DefaultPasswordPolicy, "alice.w-77", alice, []string{PasswordTooSimilar}},
+		{"similar to last name", DefaultPasswordPolicy, "Wonderland#1", alice, []string{PasswordTooSimilar}},
+		{"contains short name", DefaultPasswordPolicy, "alice-Correct-horse-42", alice, nil},
+		{"multiple violations", DefaultPasswordPolicy, "alice", alice, []string{PasswordTooShort, PasswordTooFewClasses, PasswordTooSimilar}},
+		{"bcrypt accepts 72 bytes", bcryptPolicy, "Aa1-" + strings.Repeat("x", 68), alice, nil},
+		{"bcrypt rejects 73 bytes", bcryptPolicy, "Aa1-" + strings.Repeat("x", 69), alice, []string{PasswordTooLong}},
+		{"bcrypt counts bytes", bcryptPolicy, "Aa1-" + strings.Repeat("é", 35), alice, []string{PasswordTooLong}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes := violationCodes(t, tt.policy.Validate(i18n.English, tt.password, tt.info))
+			if !reflect.DeepEqual(codes, tt.want) {
+				t.Errorf("Validate(%q) = %v; want %v", tt.password, codes, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyMessagesFollowLanguage(t *testing.T) {
+	info := PasswordUserInfo{Username: "alice"}
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{i18n.English, []string{"Password must be at least 8 characters.", "Password is too similar to your username."}},
+		{i18n.Indonesian, []string{"Password minimal 8 karakter.", "Password terlalu mirip dengan username Anda."}},
+	}
+	for _, tt := range tests {
+		err := DefaultPasswordPolicy.Validate(tt.lang, "Alice1", info)
+		var policyErr *PasswordPolicyError
+		if !errors.As(err, &policyErr) {
+			t.Fatalf("%s: Validate error = %v", tt.lang, err)
+		}
+		if got := policyErr.Messages(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: messages = %q; want %q", tt.lang, got, tt.want)
+		}
+	}
+
+	bcryptPolicy := testHasher(HashBcrypt).Policy(DefaultPasswordPolicy)
+	err := bcryptPolicy.Validate(i18n.Indonesian, "Aa1-"+strings.Repeat("x", 80), info)
+	if err == nil || !strings.Contains(err.Error(), "72 byte") {
+		t.Errorf("bcrypt length message = %v", err)
+	}
+}
+
+func TestPasswordHasherPolicyMatchesHash(t *testing.T) {
+	for _, algorithm := range []string{HashArgon2id, HashBcrypt} {
+		h := testHasher(algorithm)
+		policy := h.Policy(DefaultPasswordPolicy)
+		// Password terpanjang yang lolos kebijakan harus bisa di-hash
+		password := "Aa1-" + strings.Repeat("x", 124)
+		if policy.MaxBytes > 0 {
+			password = password[:policy.MaxBytes]
+		}
+		if err := policy.Validate(i18n.English, password, PasswordUserInfo{}); err != nil {
+			t.Fatalf("%s: Validate: %v", algorithm, err)
+		}
+		if _, err := h.Hash(password); err != nil {
+			t.Errorf("%s: Hash of %d-byte password: %v", algorithm, len(password), err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func testHasher(algorithm string) *PasswordHasher {
+	h := *DefaultPasswordHasher
+	h.Algorithm = algorithm
+	h.BcryptCost = 4
+	h.Argon2Memory = 1024
+	h.Argon2Time = 1
+	h.Argon2Threads = 1
+	return &h
+}
+
+func TestPasswordHasherRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{HashArgon2id, HashBcrypt} {
+		h := testHasher(algorithm)
+		encoded, err := h.Hash("correct horse")
+		if err != nil {
+			t.Fatalf("%s: Hash: %v", algorithm, err)
+		}
+		if ok, err := h.Verify("correct horse", encoded); !ok || err != nil {
+			t.Errorf("%s: Verify(correct) = %v, %v; want true, nil", algorithm, ok, err)
+		}
+		if ok, _ := h.Verify("wrong horse", encoded); ok {
+			t.Errorf("%s: Verify(wrong) = true; want false", algorithm)
+		}
+		if h.NeedsRehash(encoded) {
+			t.Errorf("%s: NeedsRehash on fresh hash = true", algorithm)
+		}
+	}
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	old := testHasher(HashBcrypt)
+	encoded, err := old.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testHasher(HashArgon2id).NeedsRehash(encoded) {
+		t.Error("bcrypt hash should need rehash when argon2id is configured")
+	}
+
+	weak := testHasher(HashArgon2id)
+	encoded, err = weak.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strong := testHasher(HashArgon2id)
+	strong.Argon2Time = 2
+	if !strong.NeedsRehash(encoded) {
+		t.Error("argon2id hash with lower time cost should need rehash")
+	}
+}
+
+func TestPasswordHasherRejectsMalformedArgon2id(t *testing.T) {
+	h := testHasher(HashArgon2id)
+	valid, err := h.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, "$")
+
+	tests := map[string]string{
+		"empty salt and key":  "$argon2id$v=19$m=1024,t=1,p=1$$",
+		"empty key":           strings.Join(append(parts[:5:5], ""), "$"),
+		"empty salt":          strings.Join([]string{"", "argon2id", "v=19", "m=1024,t=1,p=1", "", parts[5]}, "$"),
+		"zero threads":        strings.Join([]string{"", "argon2id", "v=19", "m=1024,t=1,p=0", parts[4], parts[5]}, "$"),
+		"zero time":           strings.Join([]string{"", "argon2id", "v=19", "m=1024,t=0,p=1", parts[4], parts[5]}, "$"),
+		"memory too large":    strings.Join([]string{"", "argon2id", "v=19", "m=4294967295,t=1,p=1", parts[4], parts[5]}, "$"),
+		"unsupported version": strings.Join([]string{"", "argon2id", "v=16", "m=1024,t=1,p=1", parts[4], parts[5]}, "$"),
+	}
+	for name, encoded := range tests {
+		for _, password := range []string{"", "secret", "anything"} {
+			ok, err := h.Verify(password, encoded)
+			if ok {
+				t.Errorf("%s: Verify(%q) = true; want false", name, password)
+			}
+			if err == nil {
+				t.Errorf("%s: Verify(%q) returned no error", name, password)
+			}
+		}
+		if !h.NeedsRehash(encoded) {
+			t.Errorf("%s: NeedsRehash = false; want true", name)
+		}
+	}
+}
+
+func TestUnusablePassword(t *testing.T) {
+	h := testHasher(HashArgon2id)
+	if ok, _ := h.Verify("", UnusablePassword()); ok {
+		t.Error("unusable password must never verify")
+	}
+}
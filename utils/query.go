@@ -0,0 +1,26 @@
+package utils
+
+import "strings"
+
+// LikeEscapeChar karakter escape untuk pattern dari ContainsPattern. Dipakai
+// '!' karena backslash diperlakukan berbeda oleh MySQL dan PostgreSQL.
+const LikeEscapeChar = "!"
+
+var likeEscaper = strings.NewReplacer(
+	LikeEscapeChar, LikeEscapeChar+LikeEscapeChar,
+	"%", LikeEscapeChar+"%",
+	"_", LikeEscapeChar+"_",
+)
+
+// ContainsPattern membuat pattern LIKE "mengandung term" yang lowercase,
+// dengan wildcard di dalam term di-escape. Pasangkan dengan ILikeClause agar
+// pencarian case-insensitive dan hasilnya sama di SQLite, PostgreSQL dan MySQL.
+func ContainsPattern(term string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(term)) + "%"
+}
+
+// ILikeClause kondisi WHERE case-insensitive untuk satu kolom, misalnya
+// ILikeClause("title") -> "LOWER(title) LIKE ? ESCAPE '!'"
+func ILikeClause(column string) string {
+	return "LOWER(" + column + ") LIKE ? ESCAPE '" + LikeEscapeChar + "'"
+}
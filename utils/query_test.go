@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// queryTestItem tabel sementara untuk menguji pattern LIKE di setiap dialek
+type queryTestItem struct {
+	ID    uint `gorm:"primarykey"`
+	Title string
+}
+
+// queryTestDatabases SQLite selalu diuji; PostgreSQL dan MySQL hanya jika
+// TEST_POSTGRES_DSN / TEST_MYSQL_DSN (format go-sql-driver) diisi
+func queryTestDatabases(t *testing.T) map[string]gorm.Dialector {
+	dialectors := map[string]gorm.Dialector{
+		"sqlite": sqlite.Open(filepath.Join(t.TempDir(), "query.db")),
+	}
+	if dsn := os.Getenv("TEST_POSTGRES_DSN"); dsn != "" {
+		dialectors["postgres"] = postgres.Open(dsn)
+	}
+	if dsn := os.Getenv("TEST_MYSQL_DSN"); dsn != "" {
+		dialectors["mysql"] = mysql.Open(dsn)
+	}
+	return dialectors
+}
+
+func TestContainsPatternAcrossDialects(t *testing.T) {
+	titles := []string{
+		"Printer rusak",
+		"100% selesai",
+		"1000 tiket",
+		"file_name.txt",
+		"fileXname.txt",
+		"tanda!seru",
+		"tandaseru",
+		"UPPER Case",
+	}
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"printer", []string{"Printer rusak"}},
+		{"upper case", []string{"UPPER Case"}},
+		{"100%", []string{"100% selesai"}},
+		{"%", []string{"100% selesai"}},
+		{"file_name", []string{"file_name.txt"}},
+		{"_", []string{"file_name.txt"}},
+		{"a!s", []string{"tanda!seru"}},
+		{"!", []string{"tanda!seru"}},
+		{"tidak ada", nil},
+	}
+
+	for name, dialector := range queryTestDatabases(t) {
+		t.Run(name, func(t *testing.T) {
+			db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Discard})
+			if err != nil {
+				t.Fatalf("open %s: %v", name, err)
+			}
+			if err := db.Migrator().DropTable(&queryTestItem{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.AutoMigrate(&queryTestItem{}); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { db.Migrator().DropTable(&queryTestItem{}) })
+			for _, title := range titles {
+				if err := db.Create(&queryTestItem{Title: title}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, tt := range tests {
+				var got []string
+				err := db.Model(&queryTestItem{}).
+					Where(ILikeClause("title"), ContainsPattern(tt.term)).
+					Pluck("title", &got).Error
+				if err != nil {
+					t.Errorf("search %q: %v", tt.term, err)
+					continue
+				}
+				sort.Strings(got)
+				if !equalStrings(got, tt.want) {
+					t.Errorf("search %q = %q; want %q", tt.term, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestContainsPattern(t *testing.T) {
+	tests := map[string]string{
+		"Abc":   "%abc%",
+		"50%":   "%50!%%",
+		"a_b":   "%a!_b%",
+		"x!y":   "%x!!y%",
+		"!%_":   "%!!!%!_%",
+		"":      "%%",
+		"MiXeD": "%mixed%",
+	}
+	for term, want := range tests {
+		if got := ContainsPattern(term); got != want {
+			t.Errorf("ContainsPattern(%q) = %q; want %q", term, got, want)
+		}
+	}
+	if got, want := ILikeClause("title"), "LOWER(title) LIKE ? ESCAPE '!'"; got != want {
+		t.Errorf("ILikeClause = %q; want %q", got, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
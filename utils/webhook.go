@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"ticketing-fiber/config"
+)
+
+// webhookTimeout batas waktu satu pengiriman webhook
+const webhookTimeout = 10 * time.Second
+
+// ErrWebhookURL alamat webhook tidak valid
+var ErrWebhookURL = errors.New("webhook URL must be an absolute http or https URL")
+
+// ErrWebhookPrivate tujuan webhook berada di jaringan lokal atau rentang
+// khusus yang tidak bisa dirutekan di internet
+var ErrWebhookPrivate = errors.New("webhook address is not publicly routable")
+
+// privatePrefixes rentang alamat yang tidak boleh menjadi tujuan webhook
+// (IANA special-purpose registry). Rentang IPv6 yang menyisipkan alamat
+// IPv4 (NAT64, 6to4, Teredo) ikut ditolak karena bisa mengarah ke jaringan
+// lokal; alamat IPv4-mapped dicek sebagai IPv4.
+var privatePrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/32"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("fec0::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// lookupNetIP resolver untuk ValidateWebhookURL; diganti saat pengujian
+var lookupNetIP = net.DefaultResolver.LookupNetIP
+
+// WebhookSender mengirim notifikasi sebagai JSON ke URL webhook milik user.
+// Karena URL diisi user, koneksi ke loopback dan jaringan privat ditolak
+// kecuali WebhookAllowPrivate aktif.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender(cfg *config.Config) *WebhookSender {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !cfg.WebhookAllowPrivate {
+		dialer.Control = rejectPrivateAddress
+	}
+	return &WebhookSender{
+		client: &http.Client{
+			Timeout: webhookTimeout,
+			// Tanpa proxy: pengecekan alamat di dialer harus melihat
+			// tujuan webhook, bukan alamat proxy
+			Transport: &http.Transport{
+				Proxy:               nil,
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: webhookTimeout,
+			},
+			// Redirect bisa mengarah ke alamat lain; anggap gagal
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send mengirim payload dengan POST; status selain 2xx dianggap gagal
+func (w *WebhookSender) Send(ctx context.Context, target string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Request ID dan user ID internal sengaja tidak dikirim ke pihak ketiga
+	req.Header.Set("User-Agent", "ticketing-fiber-webhook")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ValidateWebhookURL mengecek URL webhook sebelum disimpan. Kecuali
+// allowPrivate, host harus resolve dan semua alamatnya publik
+// (ErrWebhookPrivate); dialer tetap mengecek ulang saat mengirim karena
+// DNS bisa berubah.
+func ValidateWebhookURL(ctx context.Context, raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return ErrWebhookURL
+	}
+	if allowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if isPrivateAddress(addr) {
+			return fmt.Errorf("%w: %s", ErrWebhookPrivate, host)
+		}
+		return nil
+	}
+	addrs, err := lookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrWebhookURL, err)
+	}
+	for _, addr := range addrs {
+		if isPrivateAddress(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrWebhookPrivate, host, addr)
+		}
+	}
+	return nil
+}
+
+// rejectPrivateAddress dipanggil setelah DNS resolve, sehingga nama host
+// yang mengarah ke alamat lokal juga ditolak
+func rejectPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || isPrivateAddress(addr) {
+		return fmt.Errorf("%w: %s", ErrWebhookPrivate, host)
+	}
+	return nil
+}
+
+// isPrivateAddress true jika addr berada di privatePrefixes
+func isPrivateAddress(addr netip.Addr) bool {
+	addr = addr.WithZone("").Unmap()
+	for _, prefix := range privatePrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+// Package worker menjalankan job latar belakang (misalnya kirim email) yang
+// terdaftar di satu Group, sehingga saat shutdown job yang sedang berjalan
+// bisa ditunggu sampai selesai, bukan hilang begitu saja.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ErrClosed dikembalikan Go setelah Shutdown dipanggil
+var ErrClosed = errors.New("worker group is shutting down")
+
+// Group kumpulan goroutine latar belakang yang dikelola bersama
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	closed  bool
+	wg      sync.WaitGroup
+	running int
+}
+
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Go menjalankan job di goroutine baru. Context job membawa nilai dari ctx
+// (misalnya request ID) tetapi tidak ikut selesai bersama request; context
+// itu baru dibatalkan jika Shutdown melewati batas waktu. Error dan panic
+// dari job hanya dicatat ke log.
+func (g *Group) Go(ctx context.Context, name string, job func(ctx context.Context) error) error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		slog.WarnContext(ctx, "background job rejected", "job", name, "error", ErrClosed)
+		return ErrClosed
+	}
+	g.wg.Add(1)
+	g.running++
+	g.mu.Unlock()
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(g.ctx, cancel)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.ErrorContext(jobCtx, "background job panicked", "job", name, "panic", r)
+			}
+			stop()
+			cancel()
+			g.mu.Lock()
+			g.running--
+			g.mu.Unlock()
+			g.wg.Done()
+		}()
+
+		// Span job menjadi anak span request yang mendaftarkannya
+		spanCtx, span := otel.Tracer("ticketing-fiber/worker").Start(jobCtx, "job "+name)
+		defer span.End()
+
+		if err := job(spanCtx); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			slog.ErrorContext(spanCtx, "background job failed", "job", name, "error", err)
+		}
+	}()
+	return nil
+}
+
+// Running jumlah job yang sedang berjalan
+func (g *Group) Running() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.running
+}
+
+// Shutdown berhenti menerima job baru lalu menunggu semua job selesai. Jika
+// ctx habis lebih dulu, context job dibatalkan dan error dikembalikan.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	g.closed = true
+	pending := g.running
+	g.mu.Unlock()
+
+	if pending > 0 {
+		slog.Info("waiting for background jobs", "pending", pending)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		g.cancel()
+		return nil
+	case <-ctx.Done():
+		g.cancel()
+		return fmt.Errorf("%d background job(s) still running: %w", g.Running(), ctx.Err())
+	}
+}
@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+func TestShutdownDrainsRunningJobs(t *testing.T) {
+	g := NewGroup()
+	release := make(chan struct{})
+	var finished atomic.Int32
+	for range 3 {
+		err := g.Go(context.Background(), "mail", func(ctx context.Context) error {
+			<-release
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			finished.Add(1)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := g.Running(); got != 3 {
+		t.Fatalf("Running = %d; want 3", got)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- g.Shutdown(context.Background()) }()
+
+	// Shutdown menunggu job yang sedang berjalan
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned %v before jobs finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Job baru ditolak selama shutdown
+	if err := g.Go(context.Background(), "late", func(context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Go during shutdown = %v; want ErrClosed", err)
+	}
+
+	close(release)
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown = %v", err)
+	}
+	if got := finished.Load(); got != 3 {
+		t.Errorf("finished jobs = %d; want 3", got)
+	}
+	if got := g.Running(); got != 0 {
+		t.Errorf("Running after Shutdown = %d", got)
+	}
+}
+
+func TestShutdownTimeoutCancelsJobs(t *testing.T) {
+	g := NewGroup()
+	cancelled := make(chan error, 1)
+	g.Go(context.Background(), "stuck", func(ctx context.Context) error {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := g.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v; want deadline exceeded", err)
+	}
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("job context error = %v; want Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("job context was not cancelled after the shutdown deadline")
+	}
+}
+
+func TestJobOutlivesRequestContext(t *testing.T) {
+	g := NewGroup()
+	request, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "req-1"))
+	result := make(chan string, 1)
+	g.Go(request, "mail", func(ctx context.Context) error {
+		<-time.After(20 * time.Millisecond)
+		if ctx.Err() != nil {
+			result <- ctx.Err().Error()
+			return nil
+		}
+		result <- ctx.Value(ctxKey{}).(string)
+		return nil
+	})
+	// Request selesai sebelum job
+	cancel()
+
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-result; got != "req-1" {
+		t.Errorf("job saw %q; want request values without its cancellation", got)
+	}
+}
+
+func TestPanickingJobIsRecovered(t *testing.T) {
+	g := NewGroup()
+	g.Go(context.Background(), "broken", func(context.Context) error { panic("boom") })
+	g.Go(context.Background(), "failing", func(context.Context) error { return errors.New("smtp down") })
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown after failed jobs = %v", err)
+	}
+}